@@ -0,0 +1,20 @@
+package config
+
+// AuditConfig - 터미널 감사 로그 설정 (JSONL, 한 줄에 이벤트 하나, 추가만 함)
+type AuditConfig struct {
+	Enabled  bool
+	File     string // 감사 로그 파일
+	Commands bool   // 입력에서 명령어 줄을 복원해 command_executed 기록 (프롬프트에 입력한 비밀번호도 남을 수 있음)
+}
+
+// LoadAuditConfig - 환경 변수에서 감사 로그 설정 로드
+//
+//	AUDIT_ENABLED=true, AUDIT_LOG_FILE=./audit/terminal.jsonl, AUDIT_COMMANDS=false
+func LoadAuditConfig() *AuditConfig {
+	return &AuditConfig{
+		Enabled: getBool("AUDIT_ENABLED", true),
+		// backend 디렉터리에서 실행하는 기준
+		File:     getEnv("AUDIT_LOG_FILE", "./audit/terminal.jsonl"),
+		Commands: getBool("AUDIT_COMMANDS", false),
+	}
+}
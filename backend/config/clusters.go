@@ -0,0 +1,138 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+// ClusterConfig - 클러스터 하나의 연결 설정 (Teleport + 인벤토리 소스)
+type ClusterConfig struct {
+	Name      string
+	Teleport  TeleportConfig
+	Inventory InventoryConfig
+}
+
+// clustersFile - CLUSTERS_FILE 형식
+//
+//	clusters:
+//	  - name: prod
+//	    auth_server: prod-auth.example.com:3025
+//	    proxy_addr: prod-proxy.example.com:3023
+//	    identity_file: /etc/teleport/prod.identity
+//	    sources: [teleport, docker]
+//	  - name: lab
+//	    sources: [file]
+//	    inventory_file: ../config/lab-inventory.yaml
+//
+// 지정하지 않은 값은 환경 변수 설정(LoadTeleportConfig / LoadInventoryConfig)을 따름
+type clustersFile struct {
+	Clusters []struct {
+		Name          string   `yaml:"name"`
+		AuthServer    string   `yaml:"auth_server"`
+		ProxyAddr     string   `yaml:"proxy_addr"`
+		User          string   `yaml:"user"`
+		IdentityFile  string   `yaml:"identity_file"`
+		DialTimeout   string   `yaml:"dial_timeout"`
+		Sources       []string `yaml:"sources"`
+		DockerHost    string   `yaml:"docker_host"`
+		Kubeconfig    string   `yaml:"kubeconfig"`
+		KubeContext   string   `yaml:"kube_context"`
+		KubeNamespace string   `yaml:"kube_namespace"`
+		InventoryFile string   `yaml:"inventory_file"`
+	} `yaml:"clusters"`
+}
+
+// LoadClusterConfigs - 클러스터 설정 목록 로드
+// CLUSTERS_FILE 이 없으면 환경 변수 설정으로 만든 클러스터 하나(CLUSTER_NAME, 기본 local)만 반환
+// federated 는 CLUSTERS_FILE 을 사용했는지 여부 (컨테이너 ID에 클러스터 이름을 붙일지 결정)
+func LoadClusterConfigs() (clusters []ClusterConfig, federated bool, err error) {
+	path := getEnv("CLUSTERS_FILE", "")
+	if path == "" {
+		return []ClusterConfig{{
+			Name:      getEnv("CLUSTER_NAME", "local"),
+			Teleport:  *LoadTeleportConfig(),
+			Inventory: *LoadInventoryConfig(),
+		}}, false, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false, fmt.Errorf("클러스터 설정 파일 읽기 실패: %v", err)
+	}
+
+	var file clustersFile
+	if err := yaml.UnmarshalStrict(data, &file); err != nil {
+		return nil, false, fmt.Errorf("클러스터 설정 파일 파싱 실패: %v", err)
+	}
+	if len(file.Clusters) == 0 {
+		return nil, false, fmt.Errorf("클러스터 설정 파일에 클러스터가 없음: %s", path)
+	}
+
+	// 상대 경로는 설정 파일 기준
+	resolve := func(p string) string {
+		if p == "" || filepath.IsAbs(p) {
+			return p
+		}
+		return filepath.Join(filepath.Dir(path), p)
+	}
+
+	seen := make(map[string]bool)
+	for i, c := range file.Clusters {
+		if c.Name == "" || strings.ContainsAny(c.Name, ":/ ") {
+			return nil, false, fmt.Errorf("clusters[%d]: 잘못된 클러스터 이름 %q", i, c.Name)
+		}
+		if seen[c.Name] {
+			return nil, false, fmt.Errorf("clusters[%d]: 중복된 클러스터 이름 %q", i, c.Name)
+		}
+		seen[c.Name] = true
+
+		cluster := ClusterConfig{
+			Name:      c.Name,
+			Teleport:  *LoadTeleportConfig(),
+			Inventory: *LoadInventoryConfig(),
+		}
+		if c.AuthServer != "" {
+			cluster.Teleport.AuthServer = c.AuthServer
+		}
+		if c.ProxyAddr != "" {
+			cluster.Teleport.ProxyAddr = c.ProxyAddr
+		}
+		if c.User != "" {
+			cluster.Teleport.User = c.User
+		}
+		if c.IdentityFile != "" {
+			cluster.Teleport.IdentityFile = resolve(c.IdentityFile)
+		}
+		if c.DialTimeout != "" {
+			d, err := time.ParseDuration(c.DialTimeout)
+			if err != nil {
+				return nil, false, fmt.Errorf("clusters[%d]: 잘못된 dial_timeout: %v", i, err)
+			}
+			cluster.Teleport.DialTimeout = d
+		}
+		if len(c.Sources) > 0 {
+			cluster.Inventory.Sources = c.Sources
+		}
+		if c.DockerHost != "" {
+			cluster.Inventory.DockerSocket = strings.TrimPrefix(c.DockerHost, "unix://")
+		}
+		if c.Kubeconfig != "" {
+			cluster.Inventory.Kubeconfig = resolve(c.Kubeconfig)
+		}
+		if c.KubeContext != "" {
+			cluster.Inventory.KubeContext = c.KubeContext
+		}
+		if c.KubeNamespace != "" {
+			cluster.Inventory.KubeNamespace = c.KubeNamespace
+		}
+		// 인벤토리 파일은 클러스터마다 명시한 경우에만 사용 (기본 개발용 파일을 공유하지 않음)
+		cluster.Inventory.File = resolve(c.InventoryFile)
+		clusters = append(clusters, cluster)
+	}
+	return clusters, true, nil
+}
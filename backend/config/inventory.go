@@ -0,0 +1,72 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// InventoryConfig - 컨테이너 인벤토리 소스 설정
+type InventoryConfig struct {
+	Sources       []string // 사용할 소스 목록 (teleport, docker, kubernetes, file)
+	DockerSocket  string   // Docker Engine API unix 소켓 경로
+	Kubeconfig    string   // kubeconfig 파일 경로
+	KubeContext   string   // 사용할 kubeconfig 컨텍스트 (비어 있으면 current-context)
+	KubeNamespace string   // 조회할 네임스페이스 (비어 있으면 전체)
+
+	PollInterval time.Duration // 목록 캐시 백그라운드 갱신 주기 (변경 이벤트도 이 주기로 감지)
+	CacheTTL     time.Duration // 마지막 갱신 후 이 시간이 지나면 목록을 stale 로 표시
+
+	ProbeInterval time.Duration // NodeAddr 접속 검사 주기 (0 이면 비활성화)
+	ProbeTimeout  time.Duration // 접속 검사 제한 시간 (TCP 연결 + SSH 배너)
+
+	File               string        // YAML/JSON 인벤토리 파일 경로 (비어 있으면 사용 안 함)
+	FileReloadInterval time.Duration // 인벤토리 파일 변경 확인 주기
+}
+
+// LoadInventoryConfig - 환경 변수에서 인벤토리 설정 로드
+//
+//	INVENTORY_SOURCES=teleport,docker
+//	DOCKER_HOST=unix:///var/run/docker.sock
+//	KUBECONFIG=~/.kube/config, KUBE_CONTEXT, KUBE_NAMESPACE
+//	INVENTORY_POLL_INTERVAL=5s, INVENTORY_CACHE_TTL=30s
+//	PROBE_INTERVAL=30s, PROBE_TIMEOUT=3s
+//	INVENTORY_FILE=../config/inventory.yaml, INVENTORY_FILE_RELOAD=2s
+func LoadInventoryConfig() *InventoryConfig {
+	return &InventoryConfig{
+		Sources:       splitList(getEnv("INVENTORY_SOURCES", "teleport")),
+		DockerSocket:  strings.TrimPrefix(getEnv("DOCKER_HOST", "unix:///var/run/docker.sock"), "unix://"),
+		Kubeconfig:    getEnv("KUBECONFIG", defaultKubeconfig()),
+		KubeContext:   getEnv("KUBE_CONTEXT", ""),
+		KubeNamespace: getEnv("KUBE_NAMESPACE", ""),
+		PollInterval:  getDuration("INVENTORY_POLL_INTERVAL", 5*time.Second),
+		CacheTTL:      getDuration("INVENTORY_CACHE_TTL", 30*time.Second),
+		ProbeInterval: getDuration("PROBE_INTERVAL", 30*time.Second),
+		ProbeTimeout:  getDuration("PROBE_TIMEOUT", 3*time.Second),
+
+		// backend 디렉터리에서 실행하는 기준
+		File:               getEnv("INVENTORY_FILE", "../config/inventory.yaml"),
+		FileReloadInterval: getDuration("INVENTORY_FILE_RELOAD", 2*time.Second),
+	}
+}
+
+// defaultKubeconfig - kubectl 기본 kubeconfig 경로
+func defaultKubeconfig() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".kube", "config")
+}
+
+// splitList - 쉼표로 구분된 값 목록 파싱
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
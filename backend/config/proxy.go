@@ -0,0 +1,40 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// ProxyConfig - 앞단 인증 프록시 설정
+// 인증 프록시가 넘겨주는 요청 사용자(X-Forwarded-User)와 클라이언트 IP(X-Forwarded-For, X-Real-IP)는
+// TrustedProxies 에서 온 요청일 때만 사용 (아무나 헤더를 넣어 다른 사용자로 접속하지 못하도록)
+type ProxyConfig struct {
+	TrustedProxies []*net.IPNet
+}
+
+// LoadProxyConfig - 환경 변수에서 인증 프록시 설정 로드
+//
+//	TRUSTED_PROXIES=127.0.0.1,10.0.0.0/8 (IP 또는 CIDR, 쉼표로 구분, 비어 있으면 헤더를 믿지 않음)
+func LoadProxyConfig() (*ProxyConfig, error) {
+	cfg := &ProxyConfig{}
+	for _, item := range splitList(getEnv("TRUSTED_PROXIES", "")) {
+		if !strings.Contains(item, "/") {
+			ip := net.ParseIP(item)
+			if ip == nil {
+				return nil, fmt.Errorf("TRUSTED_PROXIES: 잘못된 주소 %q", item)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				bits = 8 * net.IPv4len
+			}
+			item = fmt.Sprintf("%s/%d", item, bits)
+		}
+		_, network, err := net.ParseCIDR(item)
+		if err != nil {
+			return nil, fmt.Errorf("TRUSTED_PROXIES: 잘못된 주소 %q: %v", item, err)
+		}
+		cfg.TrustedProxies = append(cfg.TrustedProxies, network)
+	}
+	return cfg, nil
+}
@@ -0,0 +1,101 @@
+package config
+
+import (
+	"strings"
+	"time"
+)
+
+// 녹화 정책
+const (
+	RecordingOff    = "off"    // 녹화하지 않음
+	RecordingOutput = "output" // 출력과 크기 조정만 녹화
+	RecordingAll    = "all"    // 입력까지 녹화 (비밀번호 등 입력한 내용이 남음)
+)
+
+// 녹화 저장소
+const (
+	RecordingStorageLocal = "local" // RECORDING_DIR 에 그대로 보관
+	RecordingStorageS3    = "s3"    // 세션이 끝나면 S3 호환 저장소에 업로드 (AWS S3, MinIO 등)
+)
+
+// RecordingConfig - 터미널 세션 녹화 설정 (asciicast v2)
+type RecordingConfig struct {
+	Policy  string // off / output / all
+	Dir     string // 녹화 파일 디렉터리 (세션 ID 별 .cast 파일, S3 저장소면 업로드 전 임시 보관)
+	MaxSize int    // 녹화 하나의 최대 크기 (바이트, 넘으면 이후는 녹화하지 않음)
+
+	Storage   string        // local / s3
+	Retention time.Duration // 끝난 지 이 기간이 지난 녹화 삭제 (0 이면 계속 보관)
+	URLTTL    time.Duration // 다운로드 주소(recordingUrl) 유효 시간
+	URLSecret string        // 다운로드 주소 서명 키 (비어 있으면 실행할 때마다 새로 만듦)
+	Keyring   string        // 마스터 키 파일 (설정하면 녹화 암호화, 키 교체는 새 키를 추가하고 active 변경)
+	Admins    []string      // 모든 녹화를 볼 수 있는 사용자 (그 밖의 사용자는 자기 세션의 녹화만)
+
+	S3 S3Config
+}
+
+// S3Config - S3 호환 녹화 저장소 설정
+type S3Config struct {
+	Endpoint  string // 예: https://s3.ap-northeast-2.amazonaws.com, http://localhost:9000 (MinIO)
+	Region    string
+	Bucket    string
+	Prefix    string // 객체 키 접두어
+	AccessKey string
+	SecretKey string
+	PathStyle bool // http://endpoint/bucket/key 형식 (MinIO), false 면 http://bucket.endpoint/key
+	PartSize  int  // 멀티파트 업로드 파트 크기 (최소 5MB)
+}
+
+// LoadRecordingConfig - 환경 변수에서 녹화 설정 로드
+//
+//	RECORDING_POLICY=output (off / output / all)
+//	RECORDING_DIR=./recordings, RECORDING_MAX_SIZE=67108864
+//	RECORDING_STORAGE=local (local / s3), RECORDING_RETENTION=0 (예: 720h)
+//	RECORDING_URL_TTL=15m, RECORDING_URL_SECRET
+//	RECORDING_KEYRING (마스터 키 YAML 파일 경로, 비어 있으면 암호화하지 않음)
+//	RECORDING_ADMINS=alice,bob (인증 프록시가 넘겨준 사용자 이름, 쉼표로 구분)
+//	RECORDING_S3_ENDPOINT=https://s3.amazonaws.com, RECORDING_S3_REGION=us-east-1
+//	RECORDING_S3_BUCKET, RECORDING_S3_PREFIX=recordings/, RECORDING_S3_PATH_STYLE=true
+//	RECORDING_S3_ACCESS_KEY, RECORDING_S3_SECRET_KEY (없으면 AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY)
+//	RECORDING_S3_PART_SIZE=8388608
+func LoadRecordingConfig() *RecordingConfig {
+	partSize := getInt("RECORDING_S3_PART_SIZE", 8*1024*1024)
+	if partSize < 5*1024*1024 {
+		partSize = 5 * 1024 * 1024 // S3 멀티파트 최소 크기 (마지막 파트 제외)
+	}
+
+	return &RecordingConfig{
+		Policy: strings.ToLower(getEnv("RECORDING_POLICY", RecordingOutput)),
+		// backend 디렉터리에서 실행하는 기준
+		Dir:     getEnv("RECORDING_DIR", "./recordings"),
+		MaxSize: getInt("RECORDING_MAX_SIZE", 64*1024*1024),
+
+		Storage:   strings.ToLower(getEnv("RECORDING_STORAGE", RecordingStorageLocal)),
+		Retention: getDuration("RECORDING_RETENTION", 0),
+		URLTTL:    getDuration("RECORDING_URL_TTL", 15*time.Minute),
+		URLSecret: getEnv("RECORDING_URL_SECRET", ""),
+		Keyring:   getEnv("RECORDING_KEYRING", ""),
+		Admins:    splitList(getEnv("RECORDING_ADMINS", "")),
+
+		S3: S3Config{
+			Endpoint:  getEnv("RECORDING_S3_ENDPOINT", "https://s3.amazonaws.com"),
+			Region:    getEnv("RECORDING_S3_REGION", "us-east-1"),
+			Bucket:    getEnv("RECORDING_S3_BUCKET", ""),
+			Prefix:    getEnv("RECORDING_S3_PREFIX", "recordings/"),
+			AccessKey: getEnv("RECORDING_S3_ACCESS_KEY", getEnv("AWS_ACCESS_KEY_ID", "")),
+			SecretKey: getEnv("RECORDING_S3_SECRET_KEY", getEnv("AWS_SECRET_ACCESS_KEY", "")),
+			PathStyle: getBool("RECORDING_S3_PATH_STYLE", true),
+			PartSize:  partSize,
+		},
+	}
+}
+
+// Enabled - 녹화 여부
+func (c *RecordingConfig) Enabled() bool {
+	return c.Policy == RecordingOutput || c.Policy == RecordingAll
+}
+
+// RecordInput - 입력 녹화 여부
+func (c *RecordingConfig) RecordInput() bool {
+	return c.Policy == RecordingAll
+}
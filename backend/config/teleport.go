@@ -0,0 +1,51 @@
+package config
+
+import (
+	"os"
+	"time"
+)
+
+// TeleportConfig - Teleport Auth 서버 연결 설정
+type TeleportConfig struct {
+	AuthServer   string        // Auth 서버 주소 (auth_service.listen_addr)
+	IdentityFile string        // tctl auth sign 으로 발급한 identity 파일 경로
+	DialTimeout  time.Duration // Auth 서버 연결 제한 시간
+
+	ProxyAddr string        // Proxy SSH 주소 (proxy_service.listen_addr), 터미널 세션이 거쳐 가는 곳
+	User      string        // 요청에 사용자 정보(X-Forwarded-User)가 없거나 신뢰하지 않는 주소에서 온 요청에 사용할 Teleport 사용자
+	CertTTL   time.Duration // 터미널 세션용 사용자 인증서 유효 기간
+}
+
+// LoadTeleportConfig - 환경 변수에서 Teleport 설정 로드
+// (값이 없으면 config/teleport.yaml 의 기본 포트 사용)
+func LoadTeleportConfig() *TeleportConfig {
+	return &TeleportConfig{
+		AuthServer:   getEnv("TELEPORT_AUTH_SERVER", "localhost:3025"),
+		IdentityFile: getEnv("TELEPORT_IDENTITY_FILE", ""),
+		DialTimeout:  getDuration("TELEPORT_DIAL_TIMEOUT", 5*time.Second),
+		ProxyAddr:    getEnv("TELEPORT_PROXY_ADDR", "localhost:3023"),
+		User:         getEnv("TELEPORT_USER", ""),
+		CertTTL:      getDuration("TELEPORT_CERT_TTL", 10*time.Minute),
+	}
+}
+
+// getEnv - 환경 변수 조회 (없으면 기본값)
+func getEnv(key, fallback string) string {
+	if value, ok := os.LookupEnv(key); ok && value != "" {
+		return value
+	}
+	return fallback
+}
+
+// getDuration - 기간 형식(예: 5s, 1m) 환경 변수 조회
+func getDuration(key string, fallback time.Duration) time.Duration {
+	value := getEnv(key, "")
+	if value == "" {
+		return fallback
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return fallback
+	}
+	return d
+}
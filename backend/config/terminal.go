@@ -0,0 +1,118 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 컨테이너 안에서 실행할 기본 셸 (bash 가 없으면 sh)
+var defaultExecCommand = []string{"/bin/sh", "-c", "if command -v bash >/dev/null 2>&1; then exec bash; else exec sh; fi"}
+
+// TerminalConfig - 웹 터미널 접속 설정
+type TerminalConfig struct {
+	SSHUser         string        // 인벤토리에 login 이 없을 때 사용할 SSH 사용자
+	SSHKeyFile      string        // SSH 개인 키 파일 (비어 있으면 사용 안 함)
+	SSHKeyFileSet   bool          // SSH_KEY_FILE 로 직접 지정한 키 (읽거나 파싱하지 못하면 에러, 기본 키는 건너뜀)
+	SSHPassword     string        // SSH 비밀번호 (개발용)
+	SSHKnownHosts   string        // 호스트 키 검증용 known_hosts 파일
+	SSHInsecure     bool          // 호스트 키 검증 생략 (개발용)
+	SSHDialTimeout  time.Duration // SSH 연결 제한 시간
+	AllowLocalShell bool          // method=local 대상에 백엔드 호스트 셸 허용 (개발용)
+	Term            string        // PTY 요청 시 TERM 값
+	ExecCommand     []string      // docker / kubernetes exec 로 실행할 명령
+
+	OutputFlushInterval time.Duration // 출력을 모아서 보내는 간격
+	OutputBatchSize     int           // WebSocket 프레임 하나의 최대 출력 바이트
+	OutputQueueSize     int           // 전송 대기 출력 최대 바이트 (넘으면 OutputPolicy 적용)
+	OutputPolicy        string        // block (터미널 읽기 일시 중지) / drop (버리고 알림)
+
+	DetachGrace    time.Duration // 클라이언트 연결이 끊긴 뒤 재연결을 기다리는 시간 (0 이면 바로 종료)
+	ScrollbackSize int           // 재연결 시 다시 보낼 최근 출력 버퍼 크기 (바이트)
+
+	MuxMaxChannels  int // 다중화 WebSocket 하나에 열 수 있는 최대 터미널 수
+	MaxParticipants int // 세션 하나에 동시에 연결할 수 있는 최대 클라이언트 수 (소유자 포함)
+}
+
+// LoadTerminalConfig - 환경 변수에서 터미널 설정 로드
+//
+//	SSH_USER=root, SSH_KEY_FILE=~/.ssh/id_rsa, SSH_PASSWORD
+//	SSH_KNOWN_HOSTS=~/.ssh/known_hosts, SSH_INSECURE_IGNORE_HOST_KEY=false
+//	SSH_DIAL_TIMEOUT=10s, TERMINAL_ALLOW_LOCAL_SHELL=false, TERMINAL_TERM=xterm-256color
+//	TERMINAL_EXEC_COMMAND="/bin/bash -l" (공백으로 구분, 기본은 bash 가 없으면 sh)
+//	TERMINAL_OUTPUT_FLUSH_INTERVAL=10ms, TERMINAL_OUTPUT_BATCH_SIZE=32768
+//	TERMINAL_OUTPUT_QUEUE_SIZE=1048576, TERMINAL_OUTPUT_POLICY=block (block / drop)
+//	TERMINAL_DETACH_GRACE=60s, TERMINAL_SCROLLBACK_SIZE=262144
+//	TERMINAL_MUX_MAX_CHANNELS=16, TERMINAL_MAX_PARTICIPANTS=8
+//
+// SSH_AUTH_SOCK 이 있으면 ssh-agent 의 키도 사용
+func LoadTerminalConfig() *TerminalConfig {
+	return &TerminalConfig{
+		SSHUser:         getEnv("SSH_USER", "root"),
+		SSHKeyFile:      getEnv("SSH_KEY_FILE", defaultSSHPath("id_rsa")),
+		SSHKeyFileSet:   getEnv("SSH_KEY_FILE", "") != "",
+		SSHPassword:     getEnv("SSH_PASSWORD", ""),
+		SSHKnownHosts:   getEnv("SSH_KNOWN_HOSTS", defaultSSHPath("known_hosts")),
+		SSHInsecure:     getBool("SSH_INSECURE_IGNORE_HOST_KEY", false),
+		SSHDialTimeout:  getDuration("SSH_DIAL_TIMEOUT", 10*time.Second),
+		AllowLocalShell: getBool("TERMINAL_ALLOW_LOCAL_SHELL", false),
+		Term:            getEnv("TERMINAL_TERM", "xterm-256color"),
+		ExecCommand:     getCommand("TERMINAL_EXEC_COMMAND", defaultExecCommand),
+
+		OutputFlushInterval: getDuration("TERMINAL_OUTPUT_FLUSH_INTERVAL", 10*time.Millisecond),
+		OutputBatchSize:     getInt("TERMINAL_OUTPUT_BATCH_SIZE", 32*1024),
+		OutputQueueSize:     getInt("TERMINAL_OUTPUT_QUEUE_SIZE", 1024*1024),
+		OutputPolicy:        getEnv("TERMINAL_OUTPUT_POLICY", "block"),
+
+		DetachGrace:    getDuration("TERMINAL_DETACH_GRACE", 60*time.Second),
+		ScrollbackSize: getInt("TERMINAL_SCROLLBACK_SIZE", 256*1024),
+
+		MuxMaxChannels:  getInt("TERMINAL_MUX_MAX_CHANNELS", 16),
+		MaxParticipants: getInt("TERMINAL_MAX_PARTICIPANTS", 8),
+	}
+}
+
+// defaultSSHPath - ~/.ssh 아래 기본 파일 경로
+func defaultSSHPath(name string) string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".ssh", name)
+}
+
+// getCommand - 공백으로 구분된 명령 환경 변수 조회
+func getCommand(key string, fallback []string) []string {
+	if fields := strings.Fields(getEnv(key, "")); len(fields) > 0 {
+		return fields
+	}
+	return fallback
+}
+
+// getInt - 양의 정수 환경 변수 조회
+func getInt(key string, fallback int) int {
+	value := getEnv(key, "")
+	if value == "" {
+		return fallback
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n <= 0 {
+		return fallback
+	}
+	return n
+}
+
+// getBool - true/false 형식 환경 변수 조회
+func getBool(key string, fallback bool) bool {
+	value := getEnv(key, "")
+	if value == "" {
+		return fallback
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return fallback
+	}
+	return b
+}
@@ -0,0 +1,268 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Heo-YJ/teleport-opensource/config"
+)
+
+// 감사 이벤트 종류 (프론트엔드 TerminalEvent.type)
+const (
+	AuditSessionStart    = "session_start"    // 터미널 시작
+	AuditSessionEnd      = "session_end"      // 터미널 종료 (셸 종료, 소유자 종료, 재연결 유예 시간 초과 등)
+	AuditSessionJoin     = "session_join"     // 소유자 재연결 또는 다른 사용자 참가
+	AuditSessionLeave    = "session_leave"    // 참가자 연결 끊김
+	AuditCommandExecuted = "command_executed" // 입력한 명령어 줄 (AUDIT_COMMANDS=true)
+	AuditFileAccessed    = "file_accessed"    // 녹화 다운로드 / 재생
+	AuditError           = "error"            // 접속 거부, 터미널 생성 실패, 녹화 검증 실패 등
+)
+
+// 명령어 줄 최대 길이 (넘는 부분은 버림)
+const auditMaxCommandLength = 4096
+
+// AuditClient - 요청한 클라이언트 (사용자, IP, User-Agent)
+type AuditClient struct {
+	User      string
+	IPAddress string
+	UserAgent string
+}
+
+// AuditEvent - 감사 로그 한 줄 (프론트엔드 AuditLog / TerminalEvent 필드를 모두 포함)
+type AuditEvent struct {
+	ID          string                 `json:"id"`
+	Type        string                 `json:"type"`
+	Action      string                 `json:"action"`   // AuditLog.action (type 과 같음)
+	Resource    string                 `json:"resource"` // container:<ID> / recording:<세션 ID>
+	Timestamp   time.Time              `json:"timestamp"`
+	UserID      string                 `json:"userId"`
+	SessionID   string                 `json:"sessionId,omitempty"`
+	ContainerID string                 `json:"containerId,omitempty"`
+	Cluster     string                 `json:"cluster,omitempty"`
+	IPAddress   string                 `json:"ipAddress"`
+	UserAgent   string                 `json:"userAgent"`
+	Details     map[string]interface{} `json:"details"`
+}
+
+// Auditor - 감사 이벤트를 JSONL 파일 끝에 추가 (nil 이면 기록하지 않음)
+type Auditor struct {
+	mu       sync.Mutex
+	file     *os.File
+	commands bool
+	closed   bool
+}
+
+// sessionAudit - 터미널 세션 하나의 감사 정보 (세션, 컨테이너, 세션을 연 클라이언트)
+type sessionAudit struct {
+	auditor     *Auditor
+	sessionID   string
+	containerID string
+	cluster     string
+	owner       AuditClient
+}
+
+// commandLine - 참가자 입력에서 명령어 줄 복원 (백스페이스, Ctrl-C/U, 이스케이프 시퀀스 처리)
+// 셸의 줄 편집(화살표, 탭 완성, 기록)은 알 수 없으므로 입력한 키 기준의 근사치
+type commandLine struct {
+	buffer []byte
+	escape int // 0: 일반, 1: ESC 다음, 2: CSI (ESC [), 3: SS3 (ESC O)
+}
+
+// NewAuditor - 감사 로그 파일 열기 (추가 전용, 설정에서 끄면 nil)
+func NewAuditor(cfg *config.AuditConfig) (*Auditor, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(cfg.File), 0o700); err != nil {
+		return nil, fmt.Errorf("감사 로그 디렉터리 생성 실패: %v", err)
+	}
+	file, err := os.OpenFile(cfg.File, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("감사 로그 파일 열기 실패: %v", err)
+	}
+	log.Printf("📝 감사 로그: %s (명령어 기록: %v)", cfg.File, cfg.Commands)
+	return &Auditor{file: file, commands: cfg.Commands}, nil
+}
+
+// Emit - 감사 이벤트 기록 (ID, 시각, action 을 채우고 한 줄로 추가)
+func (a *Auditor) Emit(event AuditEvent) {
+	if a == nil {
+		return
+	}
+	event.ID = newAuditEventID()
+	event.Action = event.Type
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+	if event.Details == nil {
+		event.Details = map[string]interface{}{}
+	}
+
+	line, err := json.Marshal(event)
+	if err != nil {
+		log.Printf("감사 이벤트 인코딩 실패: %v", err)
+		return
+	}
+	line = append(line, '\n')
+
+	// 한 번의 write 로 줄 전체를 추가 (O_APPEND)
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.closed {
+		log.Printf("감사 로그가 닫혀 기록하지 않음: %s %s", event.Type, event.SessionID)
+		return
+	}
+	if _, err := a.file.Write(line); err != nil {
+		log.Printf("⚠️ 감사 로그 기록 실패: %v (%s %s)", err, event.Type, event.SessionID)
+	}
+}
+
+// Close - 감사 로그 파일 닫기 (서버 종료 시, 이후 이벤트는 기록하지 않음)
+func (a *Auditor) Close() error {
+	if a == nil {
+		return nil
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.closed {
+		return nil
+	}
+	a.closed = true
+	return a.file.Close()
+}
+
+// recordsCommands - 명령어 줄 기록 여부
+func (a *Auditor) recordsCommands() bool {
+	return a != nil && a.commands
+}
+
+// requestClient - 요청한 클라이언트 정보 (신뢰하는 인증 프록시 뒤라면 X-Forwarded-For 의 첫 주소)
+func (t *TerminalHandler) requestClient(r *http.Request) AuditClient {
+	return AuditClient{
+		User:      t.requestUser(r),
+		IPAddress: t.clientIP(r),
+		UserAgent: r.UserAgent(),
+	}
+}
+
+// clientIP - 클라이언트 IP
+// 신뢰하는 프록시(TRUSTED_PROXIES)에서 온 요청만 X-Forwarded-For, X-Real-IP 를 사용하고, 그 밖에는 연결 주소
+func (t *TerminalHandler) clientIP(r *http.Request) string {
+	if t.proxies.trusts(r) {
+		if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+			first, _, _ := strings.Cut(forwarded, ",")
+			return strings.TrimSpace(first)
+		}
+		if realIP := r.Header.Get("X-Real-IP"); realIP != "" {
+			return realIP
+		}
+	}
+	return remoteHost(r)
+}
+
+// event - 클라이언트 정보를 채운 감사 이벤트 (세션/컨테이너 ID 는 호출하는 쪽에서 채움)
+func (c AuditClient) event(eventType, resource string, details map[string]interface{}) AuditEvent {
+	return AuditEvent{
+		Type:      eventType,
+		Resource:  resource,
+		UserID:    displayUser(c.User),
+		IPAddress: c.IPAddress,
+		UserAgent: c.UserAgent,
+		Details:   details,
+	}
+}
+
+// newSessionAudit - 세션의 감사 정보
+func newSessionAudit(auditor *Auditor, session *Session) *sessionAudit {
+	return &sessionAudit{
+		auditor:     auditor,
+		sessionID:   session.ID,
+		containerID: session.ContainerID,
+		cluster:     session.Cluster,
+		owner:       session.client,
+	}
+}
+
+// emit - 세션 감사 이벤트 기록 (client 는 이벤트를 일으킨 참가자)
+func (s *sessionAudit) emit(eventType string, client AuditClient, details map[string]interface{}) {
+	event := client.event(eventType, "container:"+s.containerID, details)
+	event.SessionID = s.sessionID
+	event.ContainerID = s.containerID
+	event.Cluster = s.cluster
+	s.auditor.Emit(event)
+}
+
+// recordsCommands - 명령어 줄 기록 여부
+func (s *sessionAudit) recordsCommands() bool {
+	return s.auditor.recordsCommands()
+}
+
+// feed - 입력을 반영하고 엔터로 끝난 명령어 줄 반환
+func (c *commandLine) feed(data []byte) []string {
+	var commands []string
+	for _, b := range data {
+		switch c.escape {
+		case 1:
+			switch b {
+			case '[':
+				c.escape = 2
+			case 'O':
+				c.escape = 3
+			default:
+				c.escape = 0
+			}
+			continue
+		case 2:
+			// CSI 는 0x40~0x7e 로 끝남
+			if b >= 0x40 && b <= 0x7e {
+				c.escape = 0
+			}
+			continue
+		case 3:
+			c.escape = 0
+			continue
+		}
+
+		switch {
+		case b == '\r' || b == '\n':
+			if command := strings.TrimSpace(string(c.buffer)); command != "" {
+				commands = append(commands, command)
+			}
+			c.buffer = c.buffer[:0]
+		case b == 0x7f || b == '\b':
+			_, size := utf8.DecodeLastRune(c.buffer)
+			c.buffer = c.buffer[:len(c.buffer)-size]
+		case b == 0x03 || b == 0x15:
+			// Ctrl-C, Ctrl-U: 줄 취소
+			c.buffer = c.buffer[:0]
+		case b == 0x1b:
+			c.escape = 1
+		case b == '\t':
+			c.buffer = append(c.buffer, ' ')
+		case b < 0x20:
+			// 그 밖의 제어 문자는 무시
+		default:
+			if len(c.buffer) < auditMaxCommandLength {
+				c.buffer = append(c.buffer, b)
+			}
+		}
+	}
+	return commands
+}
+
+// newAuditEventID - 감사 이벤트 ID
+func newAuditEventID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
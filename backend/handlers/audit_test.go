@@ -0,0 +1,192 @@
+package handlers
+
+import (
+	"bufio"
+	"encoding/json"
+	"net"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Heo-YJ/teleport-opensource/config"
+)
+
+func TestCommandLineFeed(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		input []string // 읽기 단위로 나눈 입력
+		want  []string
+	}{
+		{"엔터로 끝난 줄", []string{"ls -al\r"}, []string{"ls -al"}},
+		{"여러 번에 나눠 입력", []string{"ec", "ho hi", "\r"}, []string{"echo hi"}},
+		{"한 번에 여러 줄", []string{"cd /tmp\rpwd\n"}, []string{"cd /tmp", "pwd"}},
+		{"백스페이스", []string{"lss\x7f -l\r"}, []string{"ls -l"}},
+		{"백스페이스는 글자 단위", []string{"echo 한글\x7f\r"}, []string{"echo 한"}},
+		{"Ctrl-C 로 취소", []string{"rm -rf /\x03whoami\r"}, []string{"whoami"}},
+		{"Ctrl-U 로 취소", []string{"secret\x15id\r"}, []string{"id"}},
+		{"화살표 키 무시", []string{"ls\x1b[A\x1b[D\x1bOA\r"}, []string{"ls"}},
+		{"나뉜 이스케이프 시퀀스", []string{"ls\x1b", "[1;5", "C -l\r"}, []string{"ls -l"}},
+		{"탭은 공백", []string{"cat\tfile\r"}, []string{"cat file"}},
+		{"빈 줄은 기록하지 않음", []string{"\r  \r"}, nil},
+	} {
+		var line commandLine
+		var got []string
+		for _, data := range tc.input {
+			got = append(got, line.feed([]byte(data))...)
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: feed = %q, want %q", tc.name, got, tc.want)
+		}
+	}
+
+	// 최대 길이를 넘는 부분은 버림
+	var line commandLine
+	got := line.feed([]byte(strings.Repeat("a", auditMaxCommandLength+10) + "\r"))
+	if len(got) != 1 || len(got[0]) != auditMaxCommandLength {
+		t.Errorf("긴 명령어 줄 길이 = %d", len(got[0]))
+	}
+}
+
+// readAuditLog - 감사 로그 파일의 이벤트
+func readAuditLog(t *testing.T, path string) []AuditEvent {
+	t.Helper()
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	var events []AuditEvent
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var event AuditEvent
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			t.Fatalf("감사 로그 줄 파싱 실패: %s (%v)", scanner.Bytes(), err)
+		}
+		events = append(events, event)
+	}
+	return events
+}
+
+func TestAuditorEmit(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit", "terminal.jsonl")
+	auditor, err := NewAuditor(&config.AuditConfig{Enabled: true, File: path, Commands: true})
+	if err != nil {
+		t.Fatalf("NewAuditor: %v", err)
+	}
+	audit := &sessionAudit{auditor: auditor, sessionID: "s1", containerID: "web-1", cluster: "prod"}
+	audit.emit(AuditSessionStart, AuditClient{User: "alice", IPAddress: "10.0.0.1", UserAgent: "test"}, map[string]interface{}{"cols": 80})
+	audit.emit(AuditSessionLeave, AuditClient{}, nil)
+	if !audit.recordsCommands() {
+		t.Error("명령어 기록 설정이 반영되지 않음")
+	}
+	if err := auditor.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	// 닫은 뒤의 이벤트는 기록하지 않고, 다시 닫아도 에러 없음
+	audit.emit(AuditSessionEnd, AuditClient{}, nil)
+	if err := auditor.Close(); err != nil {
+		t.Errorf("두 번째 Close: %v", err)
+	}
+
+	events := readAuditLog(t, path)
+	if len(events) != 2 {
+		t.Fatalf("감사 이벤트 %d개", len(events))
+	}
+
+	start := events[0]
+	if start.ID == "" || start.Type != AuditSessionStart || start.Action != start.Type || start.Timestamp.IsZero() ||
+		start.Resource != "container:web-1" || start.SessionID != "s1" || start.ContainerID != "web-1" || start.Cluster != "prod" ||
+		start.UserID != "alice" || start.IPAddress != "10.0.0.1" || start.UserAgent != "test" || start.Details["cols"] != float64(80) {
+		t.Errorf("session_start = %+v", start)
+	}
+	// 사용자가 없으면 anonymous, details 는 빈 객체
+	if leave := events[1]; leave.UserID != "anonymous" || leave.Details == nil || leave.ID == start.ID {
+		t.Errorf("session_leave = %+v", leave)
+	}
+
+	// 끈 감사 로그는 nil 이고 기록하지 않음
+	disabled, err := NewAuditor(&config.AuditConfig{})
+	if err != nil || disabled != nil {
+		t.Fatalf("꺼진 NewAuditor = %v, %v", disabled, err)
+	}
+	disabled.Emit(AuditEvent{Type: AuditError})
+	if (&sessionAudit{}).recordsCommands() {
+		t.Error("감사 로그 없이 명령어 기록")
+	}
+}
+
+func TestTerminalAuditsCommands(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "terminal.jsonl")
+	auditor, err := NewAuditor(&config.AuditConfig{Enabled: true, File: path, Commands: true})
+	if err != nil {
+		t.Fatalf("NewAuditor: %v", err)
+	}
+	defer auditor.Close()
+	owner := AuditClient{User: "alice", IPAddress: "10.0.0.1"}
+	audit := &sessionAudit{auditor: auditor, sessionID: "session-test", containerID: "web-1", owner: owner}
+
+	backend := newFakeBackend()
+	conn := newFakeConn("")
+	terminal, err := NewTerminal(conn, "session-test", "alice", backend, nil, audit, testTerminalConfig())
+	if err != nil {
+		t.Fatalf("NewTerminal: %v", err)
+	}
+	conn.send("input", "lss\x7f -al\r")
+	conn.send("ping", nil)
+	eventually(t, "pong", func() bool { return conn.count("pong") == 1 })
+	terminal.Close()
+
+	var got []string
+	for _, event := range readAuditLog(t, path) {
+		if event.SessionID != "session-test" || event.UserID != "alice" || event.IPAddress != "10.0.0.1" {
+			t.Errorf("감사 이벤트 = %+v", event)
+		}
+		if event.Type == AuditCommandExecuted {
+			got = append(got, event.Details["command"].(string))
+		}
+		if event.Type == AuditSessionEnd {
+			got = append(got, event.Type)
+		}
+	}
+	if want := []string{"ls -al", AuditSessionEnd}; !reflect.DeepEqual(got, want) {
+		t.Errorf("감사 이벤트 = %q, want %q", got, want)
+	}
+}
+
+func TestRequestClient(t *testing.T) {
+	_, network, _ := net.ParseCIDR("192.0.2.0/24") // httptest 요청의 연결 주소
+	behindProxy := &TerminalHandler{proxies: trustedProxies{network}}
+	for _, tc := range []struct {
+		headers map[string]string
+		want    string
+	}{
+		{nil, "192.0.2.1"},
+		{map[string]string{"X-Real-IP": "10.0.0.2"}, "10.0.0.2"},
+		{map[string]string{"X-Forwarded-For": "10.0.0.3, 10.0.0.4", "X-Real-IP": "10.0.0.2"}, "10.0.0.3"},
+	} {
+		r := httptest.NewRequest("GET", "/api/terminal/web-1", nil)
+		r.Header.Set("User-Agent", "test")
+		r.Header.Set("X-Forwarded-User", "alice")
+		for key, value := range tc.headers {
+			r.Header.Set(key, value)
+		}
+		if got := behindProxy.requestClient(r); got != (AuditClient{User: "alice", IPAddress: tc.want, UserAgent: "test"}) {
+			t.Errorf("%v: requestClient = %+v", tc.headers, got)
+		}
+	}
+
+	// 신뢰하는 프록시에서 온 요청이 아니면 X-Forwarded-User / X-Forwarded-For 무시
+	r := httptest.NewRequest("GET", "/api/terminal/web-1", nil)
+	r.Header.Set("X-Forwarded-User", "alice")
+	r.Header.Set("X-Forwarded-For", "10.0.0.3")
+	if got := (&TerminalHandler{}).requestClient(r); got.User != "" || got.IPAddress != "192.0.2.1" {
+		t.Errorf("프록시 설정 없이 클라이언트 = %+v", got)
+	}
+	r.RemoteAddr = "198.51.100.7:4000"
+	if got := behindProxy.requestClient(r); got.User != "" || got.IPAddress != "198.51.100.7" {
+		t.Errorf("다른 주소에서 온 요청의 클라이언트 = %+v", got)
+	}
+}
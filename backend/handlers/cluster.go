@@ -0,0 +1,189 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/Heo-YJ/teleport-opensource/config"
+)
+
+// Cluster - 클러스터 하나의 인벤토리 소스와 연결 정보
+type Cluster struct {
+	Name     string
+	Config   config.ClusterConfig
+	Teleport *TeleportSource // Teleport 노드 목록 + 연결 상태 (소스에 teleport 가 없으면 nil)
+	Source   ContainerSource // 이 클러스터의 전체 소스 (Teleport/Docker/Kubernetes/file)
+
+	Docker     *DockerSource     // docker exec 터미널용 (설정하지 않으면 nil)
+	Kubernetes *KubernetesSource // kubernetes exec 터미널용 (설정하지 않으면 nil)
+
+	details map[string]ContainerDetailSource // 소스 이름 → 상세 조회 지원 소스
+}
+
+// NewCluster - 설정에 따라 클러스터의 인벤토리 소스 구성
+func NewCluster(cfg config.ClusterConfig) *Cluster {
+	inventoryConfig := cfg.Inventory
+
+	// 인벤토리 파일: INVENTORY_SOURCES 에 file 이 있으면 독립 소스로,
+	// 없으면 Teleport Auth 서버에 연결할 수 없을 때의 개발용 fallback 으로 사용 (teleport 소스가 있을 때만)
+	var fileSource *FileSource
+	if inventoryConfig.File != "" {
+		source, err := NewFileSource(inventoryConfig.File)
+		if err != nil {
+			log.Printf("[%s] 인벤토리 파일 로드 실패 (파일이 바뀌면 다시 읽음): %v", cfg.Name, err)
+		}
+		fileSource = source
+		go fileSource.Watch(context.Background(), inventoryConfig.FileReloadInterval)
+	}
+
+	var fallback ContainerSource
+	if fileSource != nil && !containsString(inventoryConfig.Sources, "file") {
+		fallback = fileSource
+	}
+
+	// 설정된 소스만 사용 (Teleport 를 쓰지 않는 클러스터는 Auth 서버에 연결하지 않음)
+	var (
+		sources        []ContainerSource
+		teleportSource *TeleportSource
+		dockerSource   *DockerSource
+		kubeSource     *KubernetesSource
+	)
+	details := make(map[string]ContainerDetailSource)
+	for _, name := range inventoryConfig.Sources {
+		switch name {
+		case "teleport":
+			teleportSource = NewTeleportSource(&cfg.Teleport, fallback)
+			sources = append(sources, teleportSource)
+		case "file":
+			if fileSource != nil {
+				sources = append(sources, fileSource)
+			}
+		case "docker":
+			dockerSource = NewDockerSource(inventoryConfig.DockerSocket)
+			sources = append(sources, dockerSource)
+			details[dockerSource.Name()] = dockerSource
+		case "kubernetes":
+			kubeSource = NewKubernetesSource(inventoryConfig.Kubeconfig, inventoryConfig.KubeContext, inventoryConfig.KubeNamespace)
+			sources = append(sources, kubeSource)
+			details[kubeSource.Name()] = kubeSource
+		default:
+			log.Printf("[%s] 알 수 없는 인벤토리 소스: %s", cfg.Name, name)
+		}
+	}
+
+	var source ContainerSource
+	switch len(sources) {
+	case 0:
+		log.Printf("[%s] 사용할 인벤토리 소스가 없습니다", cfg.Name)
+		source = NewMultiSource()
+	case 1:
+		source = sources[0]
+	default:
+		source = NewMultiSource(sources...)
+	}
+	log.Printf("[%s] 인벤토리 소스: %s", cfg.Name, source.Name())
+
+	return &Cluster{
+		Name:       cfg.Name,
+		Config:     cfg,
+		Teleport:   teleportSource,
+		Source:     source,
+		Docker:     dockerSource,
+		Kubernetes: kubeSource,
+		details:    details,
+	}
+}
+
+// detailSource - 소스 이름에 해당하는 상세 조회 소스 (지원하지 않으면 nil)
+func (c *Cluster) detailSource(name string) ContainerDetailSource {
+	return c.details[name]
+}
+
+// FederatedSource - 여러 클러스터의 목록을 하나로 합침
+// namespaced 이면 ID 앞에 "클러스터이름:" 을 붙여 클러스터 간 ID 충돌을 막음
+type FederatedSource struct {
+	clusters   []*Cluster
+	namespaced bool
+}
+
+// NewFederatedSource - 클러스터 연합 소스 생성
+func NewFederatedSource(clusters []*Cluster, namespaced bool) *FederatedSource {
+	return &FederatedSource{clusters: clusters, namespaced: namespaced}
+}
+
+// Name - 소스 이름
+func (f *FederatedSource) Name() string {
+	names := make([]string, 0, len(f.clusters))
+	for _, cluster := range f.clusters {
+		names = append(names, cluster.Name)
+	}
+	return "clusters(" + strings.Join(names, ",") + ")"
+}
+
+// ListContainers - 모든 클러스터 목록 조회
+// 실패한 클러스터(또는 클러스터 안의 소스)는 건너뛰고 *PartialListError 로 알림
+func (f *FederatedSource) ListContainers(ctx context.Context) ([]ContainerInfo, error) {
+	type result struct {
+		containers []ContainerInfo
+		err        error
+	}
+
+	// 느린 클러스터가 다른 클러스터를 막지 않도록 병렬 조회
+	results := make([]result, len(f.clusters))
+	done := make(chan int, len(f.clusters))
+	for i, cluster := range f.clusters {
+		go func(i int, cluster *Cluster) {
+			containers, err := cluster.Source.ListContainers(ctx)
+			results[i] = result{containers: containers, err: err}
+			done <- i
+		}(i, cluster)
+	}
+	for range f.clusters {
+		<-done
+	}
+
+	var (
+		containers []ContainerInfo
+		errs       []string
+		partial    PartialListError
+	)
+	for i, cluster := range f.clusters {
+		if err := results[i].err; err != nil {
+			if failed, ok := asPartialList(err); ok {
+				for _, scope := range failed.Failed {
+					partial.Failed = append(partial.Failed, FailedScope{Cluster: cluster.Name, Source: scope.Source})
+				}
+			} else {
+				log.Printf("[%s] 클러스터 목록 조회 실패: %v", cluster.Name, err)
+				errs = append(errs, fmt.Sprintf("%s: %v", cluster.Name, err))
+				partial.Failed = append(partial.Failed, FailedScope{Cluster: cluster.Name})
+				continue
+			}
+		}
+		for _, c := range results[i].containers {
+			c.Cluster = cluster.Name
+			if f.namespaced {
+				c.ID = cluster.Name + ":" + c.ID
+			}
+			containers = append(containers, c)
+		}
+	}
+
+	if len(errs) > 0 && len(errs) == len(f.clusters) {
+		return nil, fmt.Errorf("모든 클러스터 조회 실패: %s", strings.Join(errs, "; "))
+	}
+	if len(partial.Failed) > 0 {
+		return containers, &partial
+	}
+	return containers, nil
+}
+
+// localID - 연합 ID에서 클러스터 내부 ID 추출
+func (f *FederatedSource) localID(c *ContainerInfo) string {
+	if f.namespaced {
+		return strings.TrimPrefix(c.ID, c.Cluster+":")
+	}
+	return c.ID
+}
@@ -0,0 +1,166 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// 상태 변화 기록을 컨테이너마다 보관할 최대 개수
+const maxStateTransitions = 20
+
+// 값이 가려지는 환경 변수 이름 패턴
+var sensitiveEnvPattern = regexp.MustCompile(`(?i)(pass|secret|token|key|credential|auth|private|cert|session|cookie|dsn)`)
+
+// 이름과 상관없이 값이 가려지는 패턴 (URL 사용자 정보 scheme://user:pw@host, DSN 의 password=...)
+var sensitiveEnvValuePattern = regexp.MustCompile(`(?i)([a-z][a-z0-9+.-]*://[^/?#@\s]+@|\b(password|passwd|pwd)=)`)
+
+// 명령어 인자의 URL 사용자 정보 (scheme://user:pw@ 의 user:pw 부분만 가림)
+var commandURLCredentialPattern = regexp.MustCompile(`(?i)([a-z][a-z0-9+.-]*://)[^/?#@\s]+@`)
+
+const redactedValue = "[REDACTED]"
+
+// ContainerDetail - 단건 조회 응답 (목록 정보 + 런타임 메타데이터)
+type ContainerDetail struct {
+	ContainerInfo
+	Command      []string          `json:"command,omitempty"`
+	State        string            `json:"state,omitempty"` // 런타임 원본 상태 (예: exited (137), CrashLoopBackOff)
+	StartedAt    string            `json:"startedAt,omitempty"`
+	RestartCount int               `json:"restartCount"`
+	Resources    *ResourceLimits   `json:"resources,omitempty"`
+	Env          []EnvVar          `json:"env,omitempty"`
+	Mounts       []Mount           `json:"mounts,omitempty"`
+	Transitions  []StateTransition `json:"transitions"`
+	DetailError  string            `json:"detailError,omitempty"` // 런타임 조회 실패 시 원인 (기본 정보만 반환)
+}
+
+// ResourceLimits - CPU/메모리 제한 (사람이 읽는 형식, 없으면 비어 있음)
+type ResourceLimits struct {
+	CPULimit      string `json:"cpuLimit,omitempty"`
+	CPURequest    string `json:"cpuRequest,omitempty"`
+	MemoryLimit   string `json:"memoryLimit,omitempty"`
+	MemoryRequest string `json:"memoryRequest,omitempty"`
+	PidsLimit     int64  `json:"pidsLimit,omitempty"`
+}
+
+// EnvVar - 환경 변수 (민감한 값은 가려짐)
+type EnvVar struct {
+	Name     string `json:"name"`
+	Value    string `json:"value"`
+	Redacted bool   `json:"redacted,omitempty"`
+	From     string `json:"from,omitempty"` // 값의 출처 (예: secret:db/password)
+}
+
+// Mount - 볼륨/바인드 마운트
+type Mount struct {
+	Type        string `json:"type,omitempty"`
+	Source      string `json:"source,omitempty"`
+	Destination string `json:"destination"`
+	ReadOnly    bool   `json:"readOnly"`
+}
+
+// StateTransition - 인벤토리 갱신 중 관찰된 상태 변화
+type StateTransition struct {
+	From string    `json:"from,omitempty"` // 처음 발견된 경우 비어 있음
+	To   string    `json:"to"`
+	Time time.Time `json:"time"`
+}
+
+// ContainerDetailSource - 런타임 상세 정보를 제공할 수 있는 소스 (선택 구현)
+// c.ID 는 클러스터 내부 ID
+type ContainerDetailSource interface {
+	ContainerDetail(ctx context.Context, c *ContainerInfo) (*ContainerDetail, error)
+}
+
+// containerDetail - 컨테이너 상세 정보 구성
+// 상세 조회를 지원하지 않는 소스(teleport, file)이거나 조회에 실패하면 목록 정보만 채워서 반환
+func (h *TeleportHandler) containerDetail(ctx context.Context, target *TerminalTarget) *ContainerDetail {
+	detail := &ContainerDetail{ContainerInfo: target.Container}
+
+	if source := target.Cluster.detailSource(target.Container.Source); source != nil {
+		local := target.Container
+		local.ID = target.LocalID
+
+		runtime, err := source.ContainerDetail(ctx, &local)
+		if err != nil {
+			log.Printf("컨테이너 상세 조회 실패 (%s): %v", target.Container.ID, err)
+			detail.DetailError = err.Error()
+		} else {
+			// 목록 정보(연합 ID, 도달 가능 여부 등)는 그대로 유지
+			runtime.ContainerInfo = target.Container
+			detail = runtime
+		}
+	}
+
+	detail.Transitions = h.watcher.Transitions(target.Container.ID)
+	if detail.Transitions == nil {
+		detail.Transitions = []StateTransition{}
+	}
+	return detail
+}
+
+// redactEnv - 이름이 민감해 보이거나 값에 인증 정보가 들어 있는 환경 변수 값 가리기
+// (DATABASE_URL=postgres://user:pw@db/app 처럼 이름만으로는 알 수 없는 경우)
+func redactEnv(env EnvVar) EnvVar {
+	if env.Value != "" && (sensitiveEnvPattern.MatchString(env.Name) || sensitiveEnvValuePattern.MatchString(env.Value)) {
+		env.Value = redactedValue
+		env.Redacted = true
+	}
+	return env
+}
+
+// redactCommand - 명령어 인자 중 인증 정보 가리기 (이름 기준은 환경 변수와 같음)
+//
+//	--password=secret, DB_TOKEN=secret → 이름은 두고 값만 가림
+//	--token secret                     → 다음 인자를 가림
+//	https://user:pw@host               → 사용자 정보만 가림
+func redactCommand(command []string) []string {
+	redacted := make([]string, 0, len(command))
+	hideNext := false
+	for _, arg := range command {
+		switch {
+		case hideNext && !strings.HasPrefix(arg, "-"):
+			arg = redactedValue
+		case strings.Contains(arg, "="):
+			name, value, _ := strings.Cut(arg, "=")
+			if value != "" && sensitiveEnvPattern.MatchString(strings.TrimLeft(name, "-")) {
+				arg = name + "=" + redactedValue
+			}
+		}
+		hideNext = strings.HasPrefix(arg, "-") && !strings.Contains(arg, "=") && sensitiveEnvPattern.MatchString(arg)
+		redacted = append(redacted, commandURLCredentialPattern.ReplaceAllString(arg, "${1}"+redactedValue+"@"))
+	}
+	return redacted
+}
+
+// parseEnvList - "KEY=VALUE" 목록을 EnvVar 로 변환
+func parseEnvList(list []string) []EnvVar {
+	env := make([]EnvVar, 0, len(list))
+	for _, item := range list {
+		name, value, _ := strings.Cut(item, "=")
+		env = append(env, redactEnv(EnvVar{Name: name, Value: value}))
+	}
+	return env
+}
+
+// formatBytes - 바이트 수를 Ki/Mi/Gi 단위 문자열로 변환 (0이면 빈 문자열)
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n <= 0 {
+		return ""
+	}
+	if n < unit {
+		return fmt.Sprintf("%d", n)
+	}
+	suffixes := []string{"Ki", "Mi", "Gi", "Ti"}
+	value := float64(n) / unit
+	i := 0
+	for value >= unit && i < len(suffixes)-1 {
+		value /= unit
+		i++
+	}
+	return strings.TrimSuffix(strings.TrimSuffix(fmt.Sprintf("%.1f", value), "0"), ".") + suffixes[i]
+}
@@ -0,0 +1,27 @@
+package handlers
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRedactCommand(t *testing.T) {
+	for _, tc := range []struct {
+		command []string
+		want    []string
+	}{
+		{[]string{"nginx", "-g", "daemon off;"}, []string{"nginx", "-g", "daemon off;"}},
+		{[]string{"app", "--password=hunter2", "--port=80"}, []string{"app", "--password=" + redactedValue, "--port=80"}},
+		{[]string{"app", "--api-token", "abc", "--verbose"}, []string{"app", "--api-token", redactedValue, "--verbose"}},
+		{[]string{"app", "--token", "--verbose"}, []string{"app", "--token", "--verbose"}}, // 값 없는 플래그
+		{[]string{"env", "DB_PASSWORD=x", "LANG=C", "run"}, []string{"env", "DB_PASSWORD=" + redactedValue, "LANG=C", "run"}},
+		{[]string{"app", "--secret="}, []string{"app", "--secret="}},
+		{[]string{"psql", "postgres://app:pw@db:5432/app"}, []string{"psql", "postgres://" + redactedValue + "@db:5432/app"}},
+		{[]string{"app", "--upstream=https://u:p@api/v1", "https://example.com/a@b"}, []string{"app", "--upstream=https://" + redactedValue + "@api/v1", "https://example.com/a@b"}},
+		{nil, []string{}},
+	} {
+		if got := redactCommand(tc.command); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("redactCommand(%q) = %q, want %q", tc.command, got, tc.want)
+		}
+	}
+}
@@ -2,19 +2,26 @@ package handlers
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"log"
 	"net/http"
+	"strconv"
+	"sync"
 	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/gorilla/websocket"
-	"github.com/gravitational/teleport/api/client"
+
+	"github.com/Heo-YJ/teleport-opensource/config"
 )
 
 // WebSocket 업그레이더 설정
 var upgrader = websocket.Upgrader{
+	Subprotocols: []string{terminalBinaryProtocol}, // 요청한 클라이언트만 바이너리 프레이밍 사용
 	CheckOrigin: func(r *http.Request) bool {
 		//프론트엔드에서 오는 요청 허용
 		return true
@@ -22,60 +29,142 @@ var upgrader = websocket.Upgrader{
 }
 
 type TeleportHandler struct {
-	client          *client.Client
+	inventory       *InventoryCache     // 컨테이너 목록 (캐시)
+	clusters        map[string]*Cluster // 클러스터 이름 → 클러스터
+	clusterList     []*Cluster          // 설정 순서
+	federation      *FederatedSource
+	watcher         *InventoryWatcher // 변경 이벤트 (SSE)
+	prober          *NodeProber       // NodeAddr 접속 검사 (비활성화 시 nil)
 	terminalHandler *TerminalHandler
 }
 
 // ---- [임시 스텁: TerminalHandler / Session] ----
 // 8/21 수정
 type TerminalHandler struct {
-	sessions  map[string]*Session       // 세션 저장소
-	terminals map[string]*LocalTerminal // 터미널 저장소
+	config        *config.TerminalConfig
+	recording     *config.RecordingConfig
+	recordings    RecordingStorage    // 녹화 저장소 (재생 API, 암호화된 녹화 복호화)
+	recordingURLs *recordingURLSigner // 녹화 다운로드 / 재생 주소 서명
+	recordingKeys *RecordingKeyring   // 녹화 마스터 키 (암호화하지 않으면 nil)
+	audit         *Auditor            // 감사 로그 (끄면 nil)
+	proxies       trustedProxies      // 요청 사용자 헤더를 믿을 인증 프록시 주소
+
+	mu        sync.Mutex           // sessions / terminals 보호 (WebSocket 연결마다 고루틴)
+	sessions  map[string]*Session  // 세션 저장소
+	terminals map[string]*Terminal // 터미널 저장소
+
+	finishedOutput OutputStats // 종료된 터미널의 출력 통계 합계
 }
 
 // 프론트엔드와 일치하게!
 type Session struct {
-	ID          string          `json:"id"`
-	ContainerID string          `json:"containerId"`
-	Status      string          `json:"status"`
-	CreatedAt   time.Time       `json:"createdAt"`
-	Connection  *websocket.Conn `json:"-"` // JSON에서 제외
+	ID          string       `json:"id"`
+	ContainerID string       `json:"containerId"`
+	Status      string       `json:"status"`
+	CreatedAt   time.Time    `json:"createdAt"`
+	Cluster     string       `json:"cluster,omitempty"`
+	User        string       `json:"userId,omitempty"` // 세션을 연 사용자
+	Connection  terminalConn `json:"-"`                // JSON에서 제외 (소유자 연결)
+
+	reattachToken string            // 재연결 시 필요한 토큰 (환영 메시지로 한 번만 전달)
+	joinTokens    map[string]string // 역할(observer / moderator) → 참가 토큰 (소유자가 공유)
+	client        AuditClient       // 세션을 연 클라이언트 (감사 로그)
+}
+
+// TerminalTarget - 터미널 접속 대상 (컨테이너 + 소속 클러스터의 연결 경로)
+type TerminalTarget struct {
+	Container ContainerInfo
+	Cluster   *Cluster
+	LocalID   string // 클러스터 내부 ID (클러스터 접두어 제외)
+	User      string // 접속을 요청한 사용자 (Teleport 인증서 발급 대상)
 }
 
 func NewTerminalHandler() *TerminalHandler {
+	recording := config.LoadRecordingConfig()
+	if !recording.Enabled() && recording.Policy != config.RecordingOff {
+		log.Printf("⚠️ 알 수 없는 녹화 정책 %q, %s 사용", recording.Policy, config.RecordingOutput)
+		recording.Policy = config.RecordingOutput
+	}
+
+	urls := newRecordingURLSigner(recording)
+	var keys *RecordingKeyring
+	if recording.Keyring != "" {
+		var err error
+		if keys, err = LoadRecordingKeyring(recording.Keyring); err != nil {
+			// 암호화를 설정했는데 평문으로 남기지 않도록 녹화 중단
+			log.Printf("⚠️ %v, 녹화하지 않음", err)
+			recording.Policy = config.RecordingOff
+		} else {
+			log.Printf("🔑 녹화 암호화 사용 (마스터 키: %s)", keys.activeID())
+		}
+	}
+	recordings := NewEncryptedRecordingStorage(newRecordingStorage(recording, urls), keys, urls)
+	if keys != nil {
+		go recordings.runKeyRotation()
+	}
+	if recording.Retention > 0 {
+		log.Printf("녹화 보관 기간: %v", recording.Retention)
+		go runRecordingRetention(recordings, recording.Retention)
+	}
+
+	audit, err := NewAuditor(config.LoadAuditConfig())
+	if err != nil {
+		log.Printf("⚠️ %v, 감사 로그를 남기지 않음", err)
+	}
+
+	// 잘못 설정했으면 아무 프록시도 믿지 않음 (모든 요청이 클러스터 기본 Teleport 사용자)
+	var proxies trustedProxies
+	if proxyConfig, err := config.LoadProxyConfig(); err != nil {
+		log.Printf("⚠️ %v, 인증 프록시 헤더를 사용하지 않음", err)
+	} else {
+		proxies = proxyConfig.TrustedProxies
+	}
+
 	return &TerminalHandler{
-		sessions:  make(map[string]*Session), // 세션 맵 초기화
-		terminals: make(map[string]*LocalTerminal),
+		config:        config.LoadTerminalConfig(),
+		recording:     recording,
+		recordings:    recordings,
+		recordingURLs: urls,
+		recordingKeys: keys,
+		audit:         audit,
+		proxies:       proxies,
+		sessions:      make(map[string]*Session), // 세션 맵 초기화
+		terminals:     make(map[string]*Terminal),
 	}
 }
 
-func (t *TerminalHandler) HandleWebSocketConnection(w http.ResponseWriter, r *http.Request) {
+func (t *TerminalHandler) HandleWebSocketConnection(w http.ResponseWriter, r *http.Request, target *TerminalTarget, session *Session) {
 	// HTTP 응답으로 상태 알림 -> HTTP를 WebSocket으로 업그레이드
 	conn, err := upgrader.Upgrade(w, r, nil)
 	if err != nil {
 		log.Printf("WebSocket 업그레이드 실패: %v", err)
+		t.RemoveSession(session.ID)
 		return
 	}
 
 	log.Println("WebSocket 연결 성공")
+	t.runSession(conn, target, session)
+}
 
-	// 세션ID 생성
-	vars := mux.Vars(r)
-	containerID := vars["containerId"]
-	if containerID == "" {
-		containerID = "default"
-	}
+// runSession - 클라이언트 연결(WebSocket 또는 다중화 채널)에 터미널을 열고 종료될 때까지 대기
+func (t *TerminalHandler) runSession(conn terminalConn, target *TerminalTarget, session *Session) {
+	defer t.RemoveSession(session.ID)
 
-	sessionID := fmt.Sprintf("terminal-%s-%d", containerID, time.Now().Unix())
-	log.Printf("세션 ID 생성: %s", sessionID)
+	sessionID := session.ID
+	t.setSessionState(sessionID, "connecting", conn)
 
 	// 연결 성공 메시지 전송
 	welcomMsg := TerminalMessage{
 		Type: "system",
 		Data: map[string]interface{}{
-			"message":   "터미널 연결이 성공했습니다. 메시지를 입력해주세요.",
+			"message":   fmt.Sprintf("%s 에 연결하는 중입니다...", target.Container.Name),
 			"sessionId": sessionID,
-			"time":      time.Now().Format("15:04:05"),
+			// 연결이 끊기면 ?session=<sessionId>&token=<reattachToken> 으로 다시 연결
+			"reattachToken": session.reattachToken,
+			// 다른 사용자는 ?session=<sessionId>&role=<역할>&token=<joinTokens[역할]> 로 참가
+			"joinTokens": session.joinTokens,
+			"recording":  t.recording.Enabled(),
+			"time":       time.Now().Format("15:04:05"),
 		},
 	}
 
@@ -85,50 +174,23 @@ func (t *TerminalHandler) HandleWebSocketConnection(w http.ResponseWriter, r *ht
 		return
 	}
 
-	log.Printf("터미널 생성 시도 중..") //디버깅 확인
-
-	// 메시지 루프
-	for {
-		var message map[string]interface{}
-		err := conn.ReadJSON(&message)
+	// 접속 방식에 맞는 터미널 생성
+	audit := newSessionAudit(t.audit, session)
+	backend, err := t.newBackend(target)
+	var terminal *Terminal
+	if err == nil {
+		recorder := t.startRecording(session, target)
+		terminal, err = NewTerminal(conn, sessionID, displayUser(session.User), backend, recorder, audit, t.config)
 		if err != nil {
-			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
-				log.Printf("WebSocket 연결 오류: %v", err)
-			} else {
-				log.Println("클라이언트가 연결을 종료했습니다.")
-			}
-			break
-		}
-		log.Printf("받은 메시지: %+v", message)
-
-		// 메시지 타입에 따른 처리
-		if msgType, exists := message["type"]; exists {
-			switch msgType {
-			case "command":
-				//명령어 처리
-				if cmd, ok := message["data"].(string); ok {
-					t.handleCommand(conn, cmd)
-				}
-			case "ping":
-				// ping-pong 처리
-				pongMsg := map[string]string{
-					"type":    "pong",
-					"message": "서버 응답 정상",
-					"time":    time.Now().Format("15:04:05"),
-				}
-				conn.WriteJSON(pongMsg)
-			default:
-				// Echo 처리
-				t.handleEcho(conn, message)
-			}
+			recorder.Discard()
 		}
-		log.Println("WebSocket 연결 종료")
 	}
-
-	// 로컬 터미널 생성
-	terminal, err := NewLocalTerminal(conn, sessionID)
 	if err != nil {
 		log.Printf("터미널 생성 실패: %v", err)
+		audit.emit(AuditError, session.client, map[string]interface{}{
+			"message":   fmt.Sprintf("터미널 생성 실패: %v", err),
+			"container": target.Container.Name,
+		})
 
 		//에러 메시지 전송
 		errorMsg := TerminalMessage{
@@ -144,84 +206,253 @@ func (t *TerminalHandler) HandleWebSocketConnection(w http.ResponseWriter, r *ht
 	}
 
 	log.Printf("터미널 생성 성공: %s", sessionID) //디버깅 확인
+	details := map[string]interface{}{
+		"container": target.Container.Name,
+		"user":      displayUser(session.User),
+		"protocol":  terminal.Participants()[0].Protocol,
+		"recording": terminal.recorder != nil,
+	}
+	for key, value := range backend.Info() {
+		details[key] = value
+	}
+	audit.emit(AuditSessionStart, session.client, details)
 
 	// 터미널을 맵에 저장
+	t.mu.Lock()
 	t.terminals[sessionID] = terminal
-	log.Printf("터미널 맵에 저장 완료") //디버깅 확인
+	t.mu.Unlock()
+	t.setSessionState(sessionID, "connected", conn)
 
 	// 터미널 종료 대기
-	select {
-	case <-terminal.done:
-		log.Printf("터미널 세션 완료: %s", sessionID)
-	}
+	<-terminal.Done()
+	log.Printf("터미널 세션 완료: %s", sessionID)
 
+	t.mu.Lock()
 	delete(t.terminals, sessionID)
+	t.finishedOutput.add(terminal.OutputStats())
+	t.mu.Unlock()
 	log.Printf("터미널 세션 정리 완료: %s", sessionID)
 }
 
-// 명령어 처리 함수
-func (t *TerminalHandler) handleCommand(conn *websocket.Conn, command string) {
-	log.Printf("명령어 실행: %s", command)
+// startRecording - 녹화 정책에 따라 세션 녹화 시작 (녹화하지 않거나 실패하면 nil, 세션은 계속 진행)
+func (t *TerminalHandler) startRecording(session *Session, target *TerminalTarget) *Recorder {
+	if !t.recording.Enabled() {
+		return nil
+	}
+	recorder, err := NewRecorder(t.recording, t.recordings, t.recordingKeys, RecordingMeta{
+		SessionID:   session.ID,
+		ContainerID: session.ContainerID,
+		Container:   target.Container.Name,
+		Cluster:     session.Cluster,
+		User:        session.User,
+		StartedAt:   time.Now(),
+	}, defaultTerminalCols, defaultTerminalRows, t.config.Term)
+	if err != nil {
+		log.Printf("세션 녹화 시작 실패: %v (세션: %s)", err, session.ID)
+		return nil
+	}
+	return recorder
+}
 
-	var response map[string]string
+// HandleReattach - 실행 중인 세션에 연결 (소유자 재연결 또는 다른 사용자 참가)
+// 쿼리: session=<세션 ID>, token=<환영 메시지의 reattachToken>, replay=all (놓친 출력 대신 스크롤백 전체)
+// role=observer|moderator 이면 token 은 해당 역할의 참가 토큰 (joinTokens)
+func (t *TerminalHandler) HandleReattach(w http.ResponseWriter, r *http.Request, containerID string) {
+	query := r.URL.Query()
+	role := query.Get("role")
+	terminal, status, err := t.findReattachable(containerID, query.Get("session"), role, query.Get("token"))
+	if err != nil {
+		log.Printf("세션 연결 거부: %v", err)
+		t.auditError(t.requestClient(r), containerID, query.Get("session"), map[string]interface{}{
+			"message": fmt.Sprintf("세션 연결 거부: %v", err),
+			"status":  status,
+			"role":    role,
+		})
+		http.Error(w, http.StatusText(status), status)
+		return
+	}
 
-	switch command {
-	case "help":
-		response = map[string]string{
-			"type":    "output",
-			"message": "사용 가능한 명령어: \n- help: 도움말\n- date: 현재 시간\n- echo [텍스트]: 에코\n- clear: 화면 지우기",
-			"time":    time.Now().Format("15:04:05"),
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Printf("WebSocket 업그레이드 실패: %v", err)
+		return
+	}
+	if role != "" {
+		t.join(conn, terminal, t.requestClient(r), role)
+		return
+	}
+	t.reattach(conn, terminal, query.Get("replay") == "all", t.requestClient(r))
+}
+
+// auditError - 터미널을 열기 전에 거부한 요청의 감사 로그 (접속 대상 확인 실패, 재연결/참가 토큰 불일치 등)
+func (t *TerminalHandler) auditError(client AuditClient, containerID, sessionID string, details map[string]interface{}) {
+	event := client.event(AuditError, "container:"+containerID, details)
+	event.SessionID = sessionID
+	event.ContainerID = containerID
+	t.audit.Emit(event)
+}
+
+// findReattachable - 연결할 터미널 조회 (세션이 해당 컨테이너의 것인지, 토큰이 맞는지 확인)
+// role 이 비어 있으면 소유자 재연결 토큰, 아니면 해당 역할의 참가 토큰과 비교
+// 실패하면 HTTP 상태 코드와 에러 반환
+func (t *TerminalHandler) findReattachable(containerID, sessionID, role, token string) (*Terminal, int, error) {
+	t.mu.Lock()
+	session, exists := t.sessions[sessionID]
+	terminal := t.terminals[sessionID]
+	var expected string
+	var known bool
+	if exists {
+		exists = session.ContainerID == containerID && terminal != nil
+		expected, known = session.reattachToken, true
+		if role != "" {
+			expected, known = session.joinTokens[role]
 		}
-	case "date":
-		response = map[string]string{
-			"type":    "output",
-			"message": fmt.Sprintf("현재 시간: %s", time.Now().Format("2025-08-21 15:04:05")),
+	}
+	t.mu.Unlock()
+
+	if !exists {
+		return nil, http.StatusNotFound, fmt.Errorf("연결할 세션을 찾을 수 없음: %s (컨테이너: %s)", sessionID, containerID)
+	}
+	if !known {
+		return nil, http.StatusBadRequest, fmt.Errorf("알 수 없는 역할: %s", role)
+	}
+	if subtle.ConstantTimeCompare([]byte(token), []byte(expected)) != 1 {
+		return nil, http.StatusForbidden, fmt.Errorf("세션 토큰 불일치: %s (역할: %s)", sessionID, role)
+	}
+	return terminal, http.StatusOK, nil
+}
+
+// reattach - 찾은 터미널에 소유자의 새 연결을 붙임
+func (t *TerminalHandler) reattach(conn terminalConn, terminal *Terminal, replayAll bool, client AuditClient) {
+	if err := terminal.Attach(conn, replayAll, client); err != nil {
+		rejectConnection(conn, fmt.Sprintf("세션 재연결 실패: %v", err))
+		return
+	}
+	t.setSessionState(terminal.sessionID, "connected", conn)
+}
+
+// join - 찾은 터미널에 다른 사용자를 참가자로 붙임
+func (t *TerminalHandler) join(conn terminalConn, terminal *Terminal, client AuditClient, role string) {
+	if err := terminal.Join(conn, displayUser(client.User), role, client); err != nil {
+		rejectConnection(conn, fmt.Sprintf("세션 참가 실패: %v", err))
+	}
+}
+
+// displayUser - 참가/퇴장 알림에 표시할 사용자 이름 (인증 프록시가 없으면 anonymous)
+func displayUser(user string) string {
+	if user == "" {
+		return "anonymous"
+	}
+	return user
+}
+
+// rejectConnection - 에러 메시지를 보내고 연결 닫기
+func rejectConnection(conn terminalConn, message string) {
+	conn.WriteJSON(TerminalMessage{
+		Type: "error",
+		Data: map[string]interface{}{
+			"message": message,
 			"time":    time.Now().Format("15:04:05"),
+		},
+	})
+	conn.Close()
+}
+
+// newBackend - 컨테이너 접속 방식에 따라 터미널 백엔드 선택
+func (t *TerminalHandler) newBackend(target *TerminalTarget) (TerminalBackend, error) {
+	c := &target.Container
+
+	switch method := terminalMethod(c); method {
+	case MethodLocal:
+		// 백엔드 호스트 자체의 셸이므로 명시적으로 허용한 경우에만 사용
+		if !t.config.AllowLocalShell {
+			return nil, fmt.Errorf("로컬 셸 접속이 비활성화되어 있습니다 (TERMINAL_ALLOW_LOCAL_SHELL=true 로 허용)")
 		}
-	case "clear":
-		response = map[string]string{
-			"type":    "clear",
-			"message": "화면을 지웠습니다.",
-			"time":    time.Now().Format("15:04:05"),
+		return NewLocalBackend(), nil
+
+	case MethodTeleport:
+		// Teleport Proxy 경유 (세션이 Teleport 감사 로그/녹화에 남음)
+		if target.Cluster.Teleport == nil {
+			return nil, fmt.Errorf("클러스터 %s 에 Teleport 소스가 설정되어 있지 않습니다", target.Cluster.Name)
 		}
-	default:
-		if len(command) > 5 && command[:4] == "echo" {
-			echoText := command[5:]
-			response = map[string]string{
-				"type":    "output",
-				"message": echoText,
-				"time":    time.Now().Format("15:04:05"),
-			}
-		} else {
-			response = map[string]string{
-				"type":    "error",
-				"message": fmt.Sprintf("알 수 없는 명령어: %s (help를 입력해주세요)", command),
-				"time":    time.Now().Format("15:04:05"),
+		node := teleportNodeAddr(c, target.LocalID)
+		if node == "" {
+			return nil, fmt.Errorf("접속 주소(NodeAddr)가 없는 노드입니다: %s", c.ID)
+		}
+		return target.Cluster.Teleport.NewTeleportBackend(target.User, t.loginFor(c), node, t.config.Term), nil
+
+	case MethodDocker:
+		if target.Cluster.Docker == nil {
+			return nil, fmt.Errorf("클러스터 %s 에 Docker 소스가 설정되어 있지 않습니다", target.Cluster.Name)
+		}
+		return target.Cluster.Docker.NewDockerExecBackend(target.LocalID, t.config.ExecCommand, c.Login, t.config.Term), nil
+
+	case MethodKubernetes:
+		if target.Cluster.Kubernetes == nil {
+			return nil, fmt.Errorf("클러스터 %s 에 Kubernetes 소스가 설정되어 있지 않습니다", target.Cluster.Name)
+		}
+		// 인벤토리 파일 항목은 k8s:<namespace>:<pod>:<container> 형식의 ID 로 지정
+		kube := c.Kubernetes
+		if kube == nil {
+			parsed, ok := parseKubeContainerID(target.LocalID)
+			if !ok {
+				return nil, fmt.Errorf("Kubernetes 대상 정보가 없습니다: %s", c.ID)
 			}
+			kube = parsed
 		}
-	}
-	if err := conn.WriteJSON(response); err != nil {
-		log.Printf("명령어 응답 전송 실패: %v", err)
+		return target.Cluster.Kubernetes.NewKubernetesExecBackend(KubernetesExecTarget{
+			Namespace: kube.Namespace,
+			Pod:       kube.Pod,
+			Container: kube.Container,
+			Command:   t.config.ExecCommand,
+		}), nil
+
+	case MethodSSH:
+		if c.NodeAddr == "" {
+			return nil, fmt.Errorf("접속 주소(NodeAddr)가 없는 노드입니다: %s", c.ID)
+		}
+		clientConfig, agent, err := newSSHClientConfig(t.config, t.loginFor(c))
+		if err != nil {
+			return nil, err
+		}
+		return NewSSHBackend(c.NodeAddr, clientConfig, agent, t.config.Term), nil
+
+	default:
+		return nil, fmt.Errorf("지원하지 않는 접속 방식: %s", method)
 	}
 }
 
-// Echo 처리 함수
-func (t *TerminalHandler) handleEcho(conn *websocket.Conn, message map[string]interface{}) {
-	response := map[string]interface{}{
-		"type":     "echo",
-		"original": message,
-		"message":  fmt.Sprintf("Echo: %+v", message),
-		"time":     time.Now().Format("15:04:05"),
+// loginFor - 노드 로그인 사용자 (인벤토리에 없으면 SSH_USER)
+func (t *TerminalHandler) loginFor(c *ContainerInfo) string {
+	if c.Login != "" {
+		return c.Login
 	}
-	if err := conn.WriteJSON(response); err != nil {
-		log.Printf("Echo 응답 전송 실패: %v", err)
+	return t.config.SSHUser
+}
+
+// terminalMethod - 컨테이너 접속 방식 (인벤토리에 없으면 소스로 결정)
+func terminalMethod(c *ContainerInfo) string {
+	if c.Method != "" {
+		return c.Method
+	}
+	switch c.Source {
+	case "teleport":
+		return MethodTeleport
+	case "docker":
+		return MethodDocker
+	case "kubernetes":
+		return MethodKubernetes
+	default:
+		return MethodSSH
 	}
 }
 
 // 활성 터미널 관리
-func (t *TerminalHandler) GetActiveTerminals() map[string]*LocalTerminal {
-	activeTerminals := make(map[string]*LocalTerminal)
+func (t *TerminalHandler) GetActiveTerminals() map[string]*Terminal {
+	t.mu.Lock()
+	defer t.mu.Unlock()
 
+	activeTerminals := make(map[string]*Terminal)
 	for sessionID, terminal := range t.terminals {
 		if terminal.IsAlive() {
 			activeTerminals[sessionID] = terminal
@@ -232,11 +463,27 @@ func (t *TerminalHandler) GetActiveTerminals() map[string]*LocalTerminal {
 	return activeTerminals
 }
 
+// OutputStats - 전체 터미널 출력 통계 (종료된 세션 포함)
+func (t *TerminalHandler) OutputStats() OutputStats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	total := t.finishedOutput
+	for _, terminal := range t.terminals {
+		total.add(terminal.OutputStats())
+	}
+	return total
+}
+
 // 특정 터미널 종료
 func (t *TerminalHandler) CloseTerminal(sessionID string) bool {
-	if terminal, exists := t.terminals[sessionID]; exists {
+	t.mu.Lock()
+	terminal, exists := t.terminals[sessionID]
+	delete(t.terminals, sessionID)
+	t.mu.Unlock()
+
+	if exists {
 		terminal.Close()
-		delete(t.terminals, sessionID)
 		log.Printf("터미널 강제 종료: %s", sessionID)
 		return true
 	}
@@ -247,19 +494,39 @@ func (t *TerminalHandler) CloseTerminal(sessionID string) bool {
 func (t *TerminalHandler) CloseAllTerminals() {
 	log.Println("모든 터미널 세션 종료 중..")
 
-	for sessionID, terminal := range t.terminals {
-		terminal.Close()
-		delete(t.terminals, sessionID)
+	t.mu.Lock()
+	terminals := t.terminals
+	t.terminals = make(map[string]*Terminal)
+	t.mu.Unlock()
+
+	for _, terminal := range terminals {
+		terminal.closeWith("shutdown", nil)
 	}
 	log.Println("모든 터미널 세션 종료 완료")
 }
 
+// Shutdown - 서버 종료: 모든 터미널을 닫고 (session_end 기록) 감사 로그 파일 닫기
+func (h *TeleportHandler) Shutdown() {
+	h.terminalHandler.CloseAllTerminals()
+	// docker exec 셸 종료는 백그라운드에서 진행되므로 끝날 때까지 (최대 dockerExecKillTimeout) 대기
+	dockerExecKills.Wait()
+	if err := h.terminalHandler.audit.Close(); err != nil {
+		log.Printf("감사 로그 파일 닫기 실패: %v", err)
+	}
+}
+
 // 세션 관리
 func (t *TerminalHandler) GetActiveSessions() []Session {
+	t.mu.Lock()
 	sessions := make([]Session, 0, len(t.sessions))
-	for _, session := range t.sessions {
-		sessions = append(sessions, *session)
+	for sessionID, session := range t.sessions {
+		s := *session
+		if terminal, ok := t.terminals[sessionID]; ok && !terminal.IsAttached() {
+			s.Status = "detached" // 재연결 대기 중
+		}
+		sessions = append(sessions, s)
 	}
+	t.mu.Unlock()
 
 	// 개발용 Mock 세션 데이터
 	if len(sessions) == 0 {
@@ -275,23 +542,56 @@ func (t *TerminalHandler) GetActiveSessions() []Session {
 	return sessions
 }
 
-// 세션 추가 메서드
-func (t *TerminalHandler) AddSession(containerID string) *Session {
-	sessionID := "session-" + containerID + "-" + time.Now().Format("20060102150405")
+// 세션 추가 메서드 (user: 세션을 연 사용자, client: 감사 로그에 남길 요청 정보)
+func (t *TerminalHandler) AddSession(containerID, cluster, user string, client AuditClient) *Session {
+	now := time.Now()
+	// 같은 컨테이너에 동시에 여러 세션이 열릴 수 있으므로 나노초까지 사용
+	sessionID := "session-" + containerID + "-" + now.Format("20060102150405") + "-" + strconv.Itoa(now.Nanosecond())
 	session := &Session{
-		ID:          sessionID,
-		ContainerID: containerID,
-		Status:      "connecting",
-		CreatedAt:   time.Now(),
+		ID:            sessionID,
+		ContainerID:   containerID,
+		Status:        "connecting",
+		CreatedAt:     now,
+		Cluster:       cluster,
+		User:          user,
+		client:        client,
+		reattachToken: newReattachToken(),
+		joinTokens: map[string]string{
+			RoleObserver:  newReattachToken(),
+			RoleModerator: newReattachToken(),
+		},
 	}
 
+	t.mu.Lock()
 	t.sessions[sessionID] = session
-	log.Printf("새 세션 생성: %s (컨테이너: %s)", sessionID, containerID)
+	t.mu.Unlock()
+	log.Printf("새 세션 생성: %s (컨테이너: %s, 클러스터: %s, 사용자: %s)", sessionID, containerID, cluster, user)
 	return session
 }
 
+// newReattachToken - 추측할 수 없는 재연결/참가 토큰
+func newReattachToken() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// setSessionState - 세션 상태 / 연결 갱신
+func (t *TerminalHandler) setSessionState(sessionID, status string, conn terminalConn) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if session, exists := t.sessions[sessionID]; exists {
+		session.Status = status
+		session.Connection = conn
+	}
+}
+
 // 세션 제거 메서드
 func (t *TerminalHandler) RemoveSession(sessionID string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
 	if _, exists := t.sessions[sessionID]; exists {
 		delete(t.sessions, sessionID)
 		log.Printf("세션 제거: %s", sessionID)
@@ -306,110 +606,211 @@ type ContainerInfo struct {
 	Status   string            `json:"status"`
 	Labels   map[string]string `json:"labels"`
 	NodeAddr string            `json:"node_addr"`
+	Source   string            `json:"source"` // 데이터 출처 (teleport / docker / kubernetes / file)
+	Image    string            `json:"image,omitempty"`
+	Created  string            `json:"created,omitempty"` // RFC3339
+	Ports    []string          `json:"ports,omitempty"`   // "공개포트:내부포트/프로토콜"
+	Login    string            `json:"login,omitempty"`   // SSH 로그인 사용자 (인벤토리 파일)
+	Method   string            `json:"method,omitempty"`  // 접속 방식 (ssh / teleport / docker / kubernetes / local)
+	Cluster  string            `json:"cluster,omitempty"` // 소속 클러스터 이름
+
+	Kubernetes *KubernetesTarget `json:"kubernetes,omitempty"` // Kubernetes 파드 컨테이너인 경우
+
+	// NodeAddr 접속 검사 결과 (NodeProber)
+	Reachable *bool   `json:"reachable,omitempty"`
+	LastSeen  string  `json:"lastSeen,omitempty"` // RFC3339
+	Uptime    string  `json:"uptime,omitempty"`   // 연속 접속 가능 시간 (예: 2d 5h 30m)
+	LatencyMs float64 `json:"latencyMs,omitempty"`
 }
 
 type ContainerListResponse struct {
 	Containers []ContainerInfo `json:"containers"`
-	Total      int             `json:"total"`
-}
-
-// Mock 컨테이너 데이터 (3단계에서 실제 Teleport API로 교체 예정)
-func (h *TeleportHandler) getMockContainers() []ContainerInfo {
-	return []ContainerInfo{
-		{
-			ID:     "web-001",
-			Name:   "nginx-server",
-			Status: "online",
-			Labels: map[string]string{
-				"environment": "production",
-				"service":     "web",
-				"team":        "frontend",
-			},
-			NodeAddr: "0.0.0.0:3022",
-		},
-		{
-			ID:     "db-001",
-			Name:   "postgres-db",
-			Status: "online",
-			Labels: map[string]string{
-				"environment": "production",
-				"service":     "database",
-				"team":        "backend",
-			},
-			NodeAddr: "0.0.0.0:3022",
-		},
-		{
-			ID:     "api-001",
-			Name:   "backend-api",
-			Status: "offline",
-			Labels: map[string]string{
-				"environment": "development",
-				"service":     "api",
-				"team":        "backend",
-			},
-			NodeAddr: "0.0.0.0:3022",
-		},
-	}
+	Total      int             `json:"total"` // 필터 적용 후 전체 개수
+	Page       int             `json:"page,omitempty"`
+	PageSize   int             `json:"pageSize,omitempty"`
+	NextCursor string          `json:"nextCursor,omitempty"`
+
+	// 캐시 상태: stale 이면 목록이 오래되었을 수 있음
+	Stale        bool       `json:"stale"`
+	LastSyncedAt *time.Time `json:"lastSyncedAt,omitempty"`
 }
 
 // 생성자 함수
 func NewTeleportHandler() *TeleportHandler {
-	// Teleport 클라이언트 설정
-	/* 나중에 실제 Teleport 클라이언트 추가 예정
-	config := config.LoadTeleportConfig()
+	inventoryConfig := config.LoadInventoryConfig()
 
-	clientConfig := client.Config{
-		Addrs: []string{config.AuthServer},
+	// 클러스터별 소스 구성 (CLUSTERS_FILE 이 없으면 환경 변수 기반 클러스터 하나)
+	clusterConfigs, federated, err := config.LoadClusterConfigs()
+	if err != nil {
+		log.Printf("클러스터 설정 로드 실패, 환경 변수 설정으로 동작합니다: %v", err)
+		clusterConfigs = []config.ClusterConfig{{
+			Name:      "local",
+			Teleport:  *config.LoadTeleportConfig(),
+			Inventory: *inventoryConfig,
+		}}
+		federated = false
 	}
 
-	teleportClient, err := client.New(context.Background(), clientConfig)
-	if err != nil {
-		log.Printf("Teleport 클라이언트 생성 실패: %v", err)
-		teleportClient = nil //개발 중에는 nil로 두고 Mock데이터 사용
+	clusters := make(map[string]*Cluster, len(clusterConfigs))
+	clusterList := make([]*Cluster, 0, len(clusterConfigs))
+	for _, cfg := range clusterConfigs {
+		cluster := NewCluster(cfg)
+		clusters[cluster.Name] = cluster
+		clusterList = append(clusterList, cluster)
+	}
+	federation := NewFederatedSource(clusterList, federated)
+	source := ContainerSource(federation)
+
+	// 캐시 갱신 결과로 변경 이벤트 생성
+	inventory := NewInventoryCache(source, inventoryConfig.PollInterval, inventoryConfig.CacheTTL)
+	watcher := NewInventoryWatcher()
+	inventory.OnRefresh(watcher.apply)
+
+	// NodeAddr 접속 검사 (PROBE_INTERVAL=0 이면 비활성화)
+	var prober *NodeProber
+	if inventoryConfig.ProbeInterval > 0 {
+		prober = NewNodeProber(inventoryConfig.ProbeInterval, inventoryConfig.ProbeTimeout)
+		inventory.OnRefresh(prober.SetTargets)
 	}
-	*/
+
+	go inventory.Run(context.Background())
+	if prober != nil {
+		go prober.Run(context.Background())
+	}
+
 	return &TeleportHandler{
-		// client:          teleportClient,
+		inventory:       inventory,
+		clusters:        clusters,
+		clusterList:     clusterList,
+		federation:      federation,
+		watcher:         watcher,
+		prober:          prober,
 		terminalHandler: NewTerminalHandler(),
 	}
 }
 
-// Teleport API를 통한 실제 컨테이너 목록 조회 (구현 예정)
-func (h *TeleportHandler) GetTeleportContainers(ctx context.Context) ([]ContainerInfo, error) {
-	//현재는 Mock 데이터 반환
-	/* 나중에 다시 구현 예정. 일단 Mock 데이터로!
-	if h.client == nil {
-		containers := []ContainerInfo{
-			{
-				ID:     "teleport-node-1",
-				Name:   "production-web",
-				Status: "online",
-				Labels: map[string]string{
-					"environment": "production",
-					"service":     "web",
-				},
-				NodeAddr: "0.0.0.0:3022",
-			},
+// isOnline - 터미널 접속 가능한 상태인지 확인
+// (Teleport 노드는 online, Docker/Kubernetes 컨테이너는 running)
+func isOnline(status string) bool {
+	return status == "online" || status == "running"
+}
+
+// annotate - 응답 직전에 접속 검사 결과 반영
+// (캐시/변경 이벤트에는 넣지 않음: lastSeen 이 바뀔 때마다 updated 이벤트가 생기므로)
+func (h *TeleportHandler) annotate(containers []ContainerInfo) {
+	if h.prober == nil {
+		return
+	}
+	for i := range containers {
+		h.prober.Annotate(&containers[i])
+	}
+}
+
+// checkConnectable - 터미널 접속 가능 여부 확인 (불가능하면 이유 반환)
+// 소스 상태가 online/running 이어야 하고, NodeAddr 검사 결과가 있으면 접속 가능해야 함
+func (h *TeleportHandler) checkConnectable(c *ContainerInfo) (bool, string) {
+	if !isOnline(c.Status) {
+		return false, "Container is not online"
+	}
+	// 접속 검사 결과는 직접 접속하는 노드에만 있음 (Teleport 노드는 Proxy 경유라 검사하지 않음)
+	if c.Reachable != nil && !*c.Reachable {
+		return false, "Container is not reachable"
+	}
+	return true, ""
+}
+
+// resolveTarget - 연합 ID로 컨테이너와 소속 클러스터 조회
+// (컨테이너가 없으면 nil, nil)
+func (h *TeleportHandler) resolveTarget(ctx context.Context, containerID string) (*TerminalTarget, error) {
+	container, err := h.inventory.Get(ctx, containerID)
+	if err != nil || container == nil {
+		return nil, err
+	}
+
+	cluster, exists := h.clusters[container.Cluster]
+	if !exists {
+		return nil, fmt.Errorf("알 수 없는 클러스터: %q", container.Cluster)
+	}
+
+	return &TerminalTarget{
+		Container: *container,
+		Cluster:   cluster,
+		LocalID:   h.federation.localID(container),
+	}, nil
+}
+
+// HTTP 핸들러: 헬스 체크 (클러스터별 Teleport 연결 상태 포함)
+func (h *TeleportHandler) HandleHealthCheck(w http.ResponseWriter, r *http.Request) {
+	// 모든 클러스터가 연결되어 있으면 connected, 하나라도 fallback 이면 fallback
+	// (Teleport 소스를 쓰지 않는 클러스터는 disabled, 모두 쓰지 않으면 전체도 disabled)
+	teleportStatus := "disabled"
+	clusterStatus := make(map[string]map[string]string, len(h.clusterList))
+	for _, cluster := range h.clusterList {
+		if cluster.Teleport == nil {
+			clusterStatus[cluster.Name] = map[string]string{"teleport": "disabled"}
+			continue
+		}
+		if teleportStatus == "disabled" {
+			teleportStatus = "connected"
+		}
+		status, err := cluster.Teleport.Status()
+		entry := map[string]string{"teleport": status}
+		if err != nil {
+			entry["teleportError"] = err.Error()
+			teleportStatus = status
 		}
+		clusterStatus[cluster.Name] = entry
+	}
 
-		return h.getMockContainers(), nil
+	response := map[string]interface{}{
+		"status":    "ok",
+		"message":   "Container SSH System is running",
+		"teleport":  teleportStatus,
+		"clusters":  clusterStatus,
+		"inventory": h.inventory.Status(),
+		"terminals": map[string]interface{}{
+			"active": len(h.terminalHandler.GetActiveTerminals()),
+			"output": h.terminalHandler.OutputStats(),
+		},
 	}
-	*/
-	return h.getMockContainers(), nil
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
 }
 
 // HTTP 핸들러: 컨테이너 목록 조회
+// 라벨 셀렉터/검색/상태 필터/정렬/페이지네이션 지원 (ContainerQuery 참고)
 func (h *TeleportHandler) HandleGetContainers(w http.ResponseWriter, r *http.Request) {
-	containers, err := h.GetTeleportContainers(r.Context())
+	query, err := ParseContainerQuery(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	all, err := h.inventory.ListContainers(r.Context())
 	if err != nil {
 		log.Printf("컨테이너 목록 조회 실패: %v", err)
 		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 		return
 	}
 
+	containers, total, nextCursor, err := query.Apply(all)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	h.annotate(containers)
+
+	status := h.inventory.Status()
 	response := ContainerListResponse{
-		Containers: containers,
-		Total:      len(containers),
+		Containers:   containers,
+		Total:        total,
+		Page:         query.Page,
+		PageSize:     query.PageSize,
+		NextCursor:   nextCursor,
+		Stale:        status.Stale,
+		LastSyncedAt: status.LastSyncedAt,
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -422,6 +823,48 @@ func (h *TeleportHandler) HandleGetContainers(w http.ResponseWriter, r *http.Req
 	log.Printf("컨테이너 목록 반환: %d개", len(containers))
 }
 
+// terminalTarget - 터미널 접속 대상 확인 (존재 여부, 클러스터, 접속 가능 여부, 요청 사용자)
+// 접속할 수 없으면 nil 과 HTTP 상태 코드, 메시지 반환
+func (h *TeleportHandler) terminalTarget(r *http.Request, containerID string) (*TerminalTarget, int, string) {
+	// 컨테이너 존재 여부 확인 (캐시 조회) 및 클러스터 결정
+	target, err := h.resolveTarget(r.Context(), containerID)
+	if err != nil {
+		log.Printf("컨테이너 조회 실패: %v", err)
+		return nil, http.StatusInternalServerError, "Internal Server Error"
+	}
+
+	if target == nil {
+		log.Printf("컨테이너를 찾을 수 없음: %s", containerID)
+		return nil, http.StatusNotFound, "Container not found"
+	}
+
+	if h.prober != nil {
+		h.prober.Annotate(&target.Container)
+	}
+	if ok, reason := h.checkConnectable(&target.Container); !ok {
+		log.Printf("컨테이너에 접속할 수 없음: %s (상태: %s, %s)", containerID, target.Container.Status, reason)
+		return nil, http.StatusBadRequest, reason
+	}
+
+	// 인증 프록시가 넘겨준 사용자 (없거나 신뢰하지 않는 주소의 요청이면 클러스터 기본 Teleport 사용자)
+	target.User = h.terminalHandler.requestUser(r)
+	if target.User == "" {
+		target.User = target.Cluster.Config.Teleport.User
+	}
+	return target, http.StatusOK, ""
+}
+
+// requestUser - 인증 프록시가 넘겨준 요청 사용자
+// TRUSTED_PROXIES 에서 직접 온 요청이 아니면 헤더를 무시하고 빈 문자열
+func (t *TerminalHandler) requestUser(r *http.Request) string {
+	user := r.Header.Get("X-Forwarded-User")
+	if user != "" && !t.proxies.trusts(r) {
+		log.Printf("⚠️ 신뢰하지 않는 주소의 X-Forwarded-User 무시: %s (%s)", user, r.RemoteAddr)
+		return ""
+	}
+	return user
+}
+
 // WebSocket을 통한 터미널 연결
 func (h *TeleportHandler) HandleTerminalWebSocket(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
@@ -433,44 +876,32 @@ func (h *TeleportHandler) HandleTerminalWebSocket(w http.ResponseWriter, r *http
 		return
 	}
 
-	// 컨테이너 존재 여부 확인
-	containers, err := h.GetTeleportContainers(r.Context())
-	if err != nil {
-		log.Printf("컨테이너 조회 실패: %v", err)
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
-		return
-	}
-
-	var targetContainer *ContainerInfo
-	for i := range containers {
-		if containers[i].ID == containerID {
-			targetContainer = &containers[i]
-			break
-		}
-	}
-
-	if targetContainer == nil {
-		log.Printf("컨테이너를 찾을 수 없음: %s", containerID)
-		http.Error(w, "Container not found", http.StatusNotFound)
+	// 끊긴 세션에 다시 연결하거나 다른 사용자의 세션에 참가 (셸은 이미 실행 중)
+	if r.URL.Query().Get("session") != "" {
+		h.terminalHandler.HandleReattach(w, r, containerID)
 		return
 	}
 
-	if targetContainer.Status != "online" {
-		log.Printf("컨테이너가 온라인이 아님: %s (상태: %s)", containerID, targetContainer.Status)
-		http.Error(w, "Container is not online", http.StatusBadRequest)
+	target, status, message := h.terminalTarget(r, containerID)
+	if target == nil {
+		h.terminalHandler.auditError(h.terminalHandler.requestClient(r), containerID, "", map[string]interface{}{
+			"message": message,
+			"status":  status,
+		})
+		http.Error(w, message, status)
 		return
 	}
 
-	log.Printf("터미널 WebSocket 연결 요청: 컨테이너 %s (%s)", targetContainer.Name, containerID)
+	log.Printf("터미널 WebSocket 연결 요청: 컨테이너 %s (%s, 클러스터: %s)", target.Container.Name, containerID, target.Cluster.Name)
 
 	// 세션 생성 후 터미널 핸들러에게 위임
-	session := h.terminalHandler.AddSession(containerID)
+	session := h.terminalHandler.AddSession(containerID, target.Cluster.Name, target.User, h.terminalHandler.requestClient(r))
 	log.Printf("세션 생성됨: %s", session.ID)
 
-	h.terminalHandler.HandleWebSocketConnection(w, r)
+	h.terminalHandler.HandleWebSocketConnection(w, r, target, session)
 }
 
-// 특정 컨테이너 상세 정보 조회 (단건)
+// 특정 컨테이너 상세 정보 조회 (단건, Docker/Kubernetes 는 런타임 메타데이터 포함)
 func (h *TeleportHandler) HandleGetContainer(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	containerID := vars["containerId"]
@@ -480,36 +911,59 @@ func (h *TeleportHandler) HandleGetContainer(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	containers, err := h.GetTeleportContainers(r.Context())
+	target, err := h.resolveTarget(r.Context(), containerID)
 	if err != nil {
 		log.Printf("컨테이너 조회 실패: %v", err)
 		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 		return
 	}
 
-	for i := range containers {
-		if containers[i].ID == containerID {
-			w.Header().Set("Content-Type", "application/json")
-			_ = json.NewEncoder(w).Encode(containers[i])
-			return
+	if target != nil {
+		if h.prober != nil {
+			h.prober.Annotate(&target.Container)
 		}
+		detail := h.containerDetail(r.Context(), target)
+		setInventoryStatusHeaders(w, h.inventory.Status())
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(detail)
+		return
 	}
 
 	http.Error(w, "Container not found", http.StatusNotFound)
 }
 
+// setInventoryStatusHeaders - 단건 응답에 캐시 상태 헤더 추가
+func setInventoryStatusHeaders(w http.ResponseWriter, status InventoryStatus) {
+	w.Header().Set("X-Inventory-Stale", strconv.FormatBool(status.Stale))
+	if status.LastSyncedAt != nil {
+		w.Header().Set("X-Inventory-Last-Synced-At", status.LastSyncedAt.Format(time.RFC3339))
+	}
+}
+
 // 활성 터미널 세션 목록 조회
 func (h *TeleportHandler) HandleGetTerminalSessions(w http.ResponseWriter, r *http.Request) {
 	sessions := h.terminalHandler.GetActiveSessions()
+	terminals := h.terminalHandler.GetActiveTerminals()
 
 	sessionList := make([]map[string]interface{}, 0, len(sessions))
 	for _, session := range sessions {
 		sessionInfo := map[string]interface{}{
 			"id":          session.ID,
 			"containerId": session.ContainerID,
-			"status":      "connected",
+			"status":      session.Status,
 			"createdAt":   "2025-01-01T00:00:00Z", //하드코딩으로 임시 대체
 		}
+		if session.User != "" {
+			sessionInfo["userId"] = session.User
+		}
+		if terminal, ok := terminals[session.ID]; ok {
+			sessionInfo["output"] = terminal.OutputStats()
+			sessionInfo["participants"] = terminal.Participants()
+			// 서명된 녹화 주소는 녹화 목록과 같이 볼 수 있는 사용자에게만
+			if terminal.recorder != nil && h.terminalHandler.canViewRecording(r, &RecordingMeta{User: session.User}) {
+				sessionInfo["recordingUrl"] = h.terminalHandler.recordings.DownloadURL(session.ID)
+			}
+		}
 		sessionList = append(sessionList, sessionInfo)
 	}
 
@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"time"
+)
+
+// dockerClient - Docker Engine API 클라이언트 (unix 소켓)
+type dockerClient struct {
+	socketPath string
+	httpClient *http.Client
+}
+
+// newDockerClient - unix 소켓으로 연결하는 Docker 클라이언트 생성
+func newDockerClient(socketPath string) *dockerClient {
+	transport := &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var dialer net.Dialer
+			return dialer.DialContext(ctx, "unix", socketPath)
+		},
+	}
+
+	return &dockerClient{
+		socketPath: socketPath,
+		httpClient: &http.Client{
+			Transport: transport,
+			Timeout:   10 * time.Second,
+		},
+	}
+}
+
+// get - GET 요청 후 JSON 응답 디코딩
+func (c *dockerClient) get(ctx context.Context, path string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://docker"+path, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("Docker API 요청 실패 (%s): %v", path, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return dockerError(resp)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// post - JSON 본문으로 POST 요청 (out 이 nil 이면 응답 본문 무시)
+func (c *dockerClient) post(ctx context.Context, path string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "http://docker"+path, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("Docker API 요청 실패 (%s): %v", path, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return dockerError(resp)
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// hijack - 연결을 업그레이드해 양방향 원시 스트림으로 사용 (exec start / attach)
+// 반환된 Reader 는 응답 헤더 뒤에 이미 읽힌 데이터를 포함하므로 conn 대신 읽기에 사용
+func (c *dockerClient) hijack(ctx context.Context, path string, body interface{}) (net.Conn, *bufio.Reader, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "unix", c.socketPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Docker 소켓 연결 실패: %v", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, "http://docker"+path, bytes.NewReader(data))
+	if err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "tcp")
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	if err := req.Write(conn); err != nil {
+		conn.Close()
+		return nil, nil, fmt.Errorf("Docker API 요청 실패 (%s): %v", path, err)
+	}
+
+	reader := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(reader, req)
+	if err != nil {
+		conn.Close()
+		return nil, nil, fmt.Errorf("Docker API 응답 읽기 실패 (%s): %v", path, err)
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols && resp.StatusCode != http.StatusOK {
+		defer conn.Close()
+		return nil, nil, dockerError(resp)
+	}
+	conn.SetDeadline(time.Time{})
+	return conn, reader, nil
+}
+
+// dockerError - Docker API 에러 응답 변환
+func dockerError(resp *http.Response) error {
+	var body struct {
+		Message string `json:"message"`
+	}
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if err := json.Unmarshal(data, &body); err != nil || body.Message == "" {
+		body.Message = string(data)
+	}
+	return fmt.Errorf("Docker API 오류 (HTTP %d): %s", resp.StatusCode, body.Message)
+}
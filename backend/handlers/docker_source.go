@@ -0,0 +1,248 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DockerSource - Docker Engine 컨테이너 목록 제공자
+type DockerSource struct {
+	client *dockerClient
+}
+
+// NewDockerSource - Docker 소스 생성 (socketPath: Engine API unix 소켓)
+func NewDockerSource(socketPath string) *DockerSource {
+	return &DockerSource{client: newDockerClient(socketPath)}
+}
+
+// dockerContainer - GET /containers/json 응답 항목
+type dockerContainer struct {
+	ID      string            `json:"Id"`
+	Names   []string          `json:"Names"`
+	Image   string            `json:"Image"`
+	Created int64             `json:"Created"`
+	State   string            `json:"State"`
+	Labels  map[string]string `json:"Labels"`
+	Ports   []dockerPort      `json:"Ports"`
+}
+
+type dockerPort struct {
+	IP          string `json:"IP"`
+	PrivatePort int    `json:"PrivatePort"`
+	PublicPort  int    `json:"PublicPort"`
+	Type        string `json:"Type"`
+}
+
+// Name - 소스 이름
+func (d *DockerSource) Name() string {
+	return "docker"
+}
+
+// ListContainers - 중지된 컨테이너를 포함한 전체 목록 조회
+func (d *DockerSource) ListContainers(ctx context.Context) ([]ContainerInfo, error) {
+	var list []dockerContainer
+	if err := d.client.get(ctx, "/containers/json?all=1", &list); err != nil {
+		return nil, err
+	}
+
+	containers := make([]ContainerInfo, 0, len(list))
+	for _, c := range list {
+		containers = append(containers, dockerToContainerInfo(c))
+	}
+	return containers, nil
+}
+
+// dockerToContainerInfo - Docker 컨테이너를 ContainerInfo로 변환
+func dockerToContainerInfo(c dockerContainer) ContainerInfo {
+	name := shortDockerID(c.ID)
+	if len(c.Names) > 0 {
+		name = strings.TrimPrefix(c.Names[0], "/")
+	}
+
+	labels := c.Labels
+	if labels == nil {
+		labels = map[string]string{}
+	}
+
+	return ContainerInfo{
+		ID:      shortDockerID(c.ID),
+		Name:    name,
+		Status:  dockerStatus(c.State),
+		Labels:  labels,
+		Source:  "docker",
+		Image:   c.Image,
+		Created: time.Unix(c.Created, 0).UTC().Format(time.RFC3339),
+		Ports:   formatDockerPorts(c.Ports),
+	}
+}
+
+// shortDockerID - docker ps 와 같은 12자리 ID
+func shortDockerID(id string) string {
+	if len(id) > 12 {
+		return id[:12]
+	}
+	return id
+}
+
+// dockerStatus - Docker State를 프론트엔드 ContainerStatus로 변환
+func dockerStatus(state string) string {
+	switch state {
+	case "running":
+		return "running"
+	case "created", "restarting":
+		return "pending"
+	case "exited", "paused":
+		return "stopped"
+	case "dead", "removing":
+		return "error"
+	default:
+		return "unknown"
+	}
+}
+
+// formatDockerPorts - 포트 매핑을 "공개포트:내부포트/프로토콜" 형식으로 변환
+// (IPv4/IPv6 중복 매핑은 하나로 합침)
+func formatDockerPorts(ports []dockerPort) []string {
+	seen := make(map[string]bool)
+	var result []string
+	for _, p := range ports {
+		port := fmt.Sprintf("%d/%s", p.PrivatePort, p.Type)
+		if p.PublicPort != 0 {
+			port = fmt.Sprintf("%d:%s", p.PublicPort, port)
+		}
+		if !seen[port] {
+			seen[port] = true
+			result = append(result, port)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// dockerInspect - GET /containers/{id}/json 응답 중 필요한 부분만
+type dockerInspect struct {
+	Path         string   `json:"Path"`
+	Args         []string `json:"Args"`
+	RestartCount int      `json:"RestartCount"`
+	State        struct {
+		Status    string `json:"Status"`
+		ExitCode  int    `json:"ExitCode"`
+		OOMKilled bool   `json:"OOMKilled"`
+		StartedAt string `json:"StartedAt"`
+		Health    *struct {
+			Status string `json:"Status"`
+		} `json:"Health"`
+	} `json:"State"`
+	Config struct {
+		Env []string `json:"Env"`
+	} `json:"Config"`
+	HostConfig struct {
+		Memory            int64  `json:"Memory"`
+		MemoryReservation int64  `json:"MemoryReservation"`
+		NanoCpus          int64  `json:"NanoCpus"`
+		CpuQuota          int64  `json:"CpuQuota"`
+		CpuPeriod         int64  `json:"CpuPeriod"`
+		CpuShares         int64  `json:"CpuShares"`
+		PidsLimit         *int64 `json:"PidsLimit"`
+	} `json:"HostConfig"`
+	Mounts []struct {
+		Type        string `json:"Type"`
+		Name        string `json:"Name"`
+		Source      string `json:"Source"`
+		Destination string `json:"Destination"`
+		RW          bool   `json:"RW"`
+	} `json:"Mounts"`
+}
+
+// ContainerDetail - docker inspect 로 런타임 상세 정보 조회
+func (d *DockerSource) ContainerDetail(ctx context.Context, c *ContainerInfo) (*ContainerDetail, error) {
+	var inspect dockerInspect
+	if err := d.client.get(ctx, "/containers/"+url.PathEscape(c.ID)+"/json", &inspect); err != nil {
+		return nil, err
+	}
+
+	detail := &ContainerDetail{
+		ContainerInfo: *c,
+		Command:       redactCommand(append([]string{inspect.Path}, inspect.Args...)),
+		State:         dockerStateDescription(&inspect),
+		RestartCount:  inspect.RestartCount,
+		Resources:     dockerResources(&inspect),
+		Env:           parseEnvList(inspect.Config.Env),
+	}
+	if !strings.HasPrefix(inspect.State.StartedAt, "0001-") {
+		detail.StartedAt = inspect.State.StartedAt
+	}
+
+	for _, m := range inspect.Mounts {
+		source := m.Source
+		if m.Type == "volume" && m.Name != "" {
+			source = m.Name
+		}
+		detail.Mounts = append(detail.Mounts, Mount{
+			Type:        m.Type,
+			Source:      source,
+			Destination: m.Destination,
+			ReadOnly:    !m.RW,
+		})
+	}
+	return detail, nil
+}
+
+// dockerStateDescription - 상태 설명 (예: running (healthy), exited (137, OOMKilled))
+func dockerStateDescription(inspect *dockerInspect) string {
+	state := inspect.State
+	switch {
+	case state.Status == "exited" && state.OOMKilled:
+		return fmt.Sprintf("exited (%d, OOMKilled)", state.ExitCode)
+	case state.Status == "exited":
+		return fmt.Sprintf("exited (%d)", state.ExitCode)
+	case state.Health != nil && state.Health.Status != "":
+		return fmt.Sprintf("%s (%s)", state.Status, state.Health.Status)
+	default:
+		return state.Status
+	}
+}
+
+// dockerResources - HostConfig 의 CPU/메모리 제한 변환 (제한이 없으면 nil)
+func dockerResources(inspect *dockerInspect) *ResourceLimits {
+	host := inspect.HostConfig
+	limits := &ResourceLimits{
+		MemoryLimit:   formatBytes(host.Memory),
+		MemoryRequest: formatBytes(host.MemoryReservation),
+	}
+
+	switch {
+	case host.NanoCpus > 0:
+		limits.CPULimit = formatCPU(float64(host.NanoCpus) / 1e9)
+	case host.CpuQuota > 0:
+		period := host.CpuPeriod
+		if period == 0 {
+			period = 100000 // Docker 기본값 (100ms)
+		}
+		limits.CPULimit = formatCPU(float64(host.CpuQuota) / float64(period))
+	}
+	if host.CpuShares > 0 {
+		// 기본 1024 shares 를 1 CPU 상대 가중치로 표시
+		limits.CPURequest = formatCPU(float64(host.CpuShares) / 1024)
+	}
+	if host.PidsLimit != nil && *host.PidsLimit > 0 {
+		limits.PidsLimit = *host.PidsLimit
+	}
+
+	if *limits == (ResourceLimits{}) {
+		return nil
+	}
+	return limits
+}
+
+// formatCPU - CPU 코어 수를 Kubernetes 와 같은 형식으로 표시 (예: 2, 500m)
+func formatCPU(cores float64) string {
+	if cores >= 1 && cores == float64(int64(cores)) {
+		return fmt.Sprintf("%d", int64(cores))
+	}
+	return fmt.Sprintf("%dm", int64(cores*1000+0.5))
+}
@@ -0,0 +1,232 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// Docker API 호출 제한 시간 (exec 생성/크기 조정/종료 코드 조회)
+const dockerExecAPITimeout = 10 * time.Second
+
+// exec 종료에 걸리는 최대 시간 (종료 스크립트의 SIGKILL 까지 1초 + 여유)
+const dockerExecKillTimeout = 5 * time.Second
+
+// dockerExecKills - 백그라운드에서 진행 중인 exec 종료 (서버 종료 시 기다림)
+var dockerExecKills sync.WaitGroup
+
+// exec 프로세스를 찾기 위한 환경 변수 (세션마다 임의 값, 셸의 자식 프로세스도 물려받음)
+const dockerExecMarkerEnv = "TERMINAL_EXEC_MARKER"
+
+// dockerExecKillScript - 표시 환경 변수를 가진 프로세스에 SIGHUP, 1초 뒤 남아 있으면 SIGKILL (%s: 표시 값)
+const dockerExecKillScript = `pids=""
+for p in /proc/[0-9]*; do
+  grep -qa "` + dockerExecMarkerEnv + `=%s" "$p/environ" 2>/dev/null && pids="$pids ${p#/proc/}"
+done
+[ -z "$pids" ] && exit 0
+kill -HUP $pids 2>/dev/null
+sleep 1
+kill -KILL $pids 2>/dev/null
+exit 0`
+
+// DockerExecBackend - docker exec TTY 세션 (컨테이너에 sshd 가 없어도 셸 접속)
+type DockerExecBackend struct {
+	client      *dockerClient
+	containerID string
+	command     []string // 실행할 명령 (예: /bin/sh -c ...)
+	user        string   // 비어 있으면 이미지 기본 사용자
+	term        string   // TERM 환경 변수
+	marker      string   // dockerExecMarkerEnv 값
+
+	execID    string
+	stream    net.Conn      // exec start 로 업그레이드된 연결 (쓰기)
+	reader    *bufio.Reader // 같은 연결의 읽기 쪽
+	closeOnce sync.Once
+}
+
+// NewDockerExecBackend - docker exec 터미널 백엔드 생성 (Start 에서 exec 실행)
+func (d *DockerSource) NewDockerExecBackend(containerID string, command []string, user, term string) *DockerExecBackend {
+	return &DockerExecBackend{
+		client:      d.client,
+		containerID: containerID,
+		command:     command,
+		user:        user,
+		term:        term,
+		marker:      newReattachToken(),
+	}
+}
+
+// Start - exec 인스턴스를 만들고 TTY 스트림에 연결
+func (b *DockerExecBackend) Start(ctx context.Context, cols, rows int) error {
+	var created struct {
+		ID string `json:"Id"`
+	}
+	err := b.client.post(ctx, "/containers/"+url.PathEscape(b.containerID)+"/exec", map[string]interface{}{
+		"AttachStdin":  true,
+		"AttachStdout": true,
+		"AttachStderr": true,
+		"Tty":          true,
+		"Cmd":          b.command,
+		"User":         b.user,
+		"Env":          []string{"TERM=" + b.term, dockerExecMarkerEnv + "=" + b.marker},
+		"ConsoleSize":  []int{rows, cols},
+	}, &created)
+	if err != nil {
+		return fmt.Errorf("docker exec 생성 실패: %v", err)
+	}
+	b.execID = created.ID
+
+	// TTY 모드에서는 stdout/stderr 가 구분 없이 원시 스트림으로 전달됨
+	b.stream, b.reader, err = b.client.hijack(ctx, "/exec/"+created.ID+"/start", map[string]interface{}{
+		"Detach": false,
+		"Tty":    true,
+	})
+	if err != nil {
+		return fmt.Errorf("docker exec 시작 실패: %v", err)
+	}
+	log.Printf("docker exec 시작: %s %v (exec: %s)", b.containerID, b.command, shortDockerID(created.ID))
+	return nil
+}
+
+// Read - exec 출력 읽기
+func (b *DockerExecBackend) Read(p []byte) (int, error) {
+	return b.reader.Read(p)
+}
+
+// Write - exec 입력
+func (b *DockerExecBackend) Write(p []byte) (int, error) {
+	return b.stream.Write(p)
+}
+
+// Resize - exec TTY 크기 조정
+func (b *DockerExecBackend) Resize(cols, rows int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), dockerExecAPITimeout)
+	defer cancel()
+
+	return b.client.post(ctx, fmt.Sprintf("/exec/%s/resize?h=%d&w=%d", b.execID, rows, cols), nil, nil)
+}
+
+// Signal - exec 프로세스에 시그널을 보내는 API 가 없으므로 TTY 제어 문자로 전달
+func (b *DockerExecBackend) Signal(sig string) error {
+	return ttySignal(b.stream, sig)
+}
+
+// Wait - exec 종료 코드 조회 (알 수 없으면 -1)
+func (b *DockerExecBackend) Wait() (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dockerExecAPITimeout)
+	defer cancel()
+
+	// 스트림이 닫힌 직후에는 아직 Running 일 수 있어 잠시 재시도
+	for attempt := 0; attempt < 10; attempt++ {
+		inspect, err := b.inspect(ctx, b.execID)
+		if err != nil {
+			return -1, fmt.Errorf("docker exec 종료 코드 조회 실패: %v", err)
+		}
+		if !inspect.Running && inspect.ExitCode != nil {
+			return *inspect.ExitCode, nil
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	return -1, fmt.Errorf("docker exec 가 아직 실행 중입니다")
+}
+
+// dockerExecInspect - exec 상태
+type dockerExecInspect struct {
+	Running  bool `json:"Running"`
+	ExitCode *int `json:"ExitCode"`
+}
+
+// inspect - exec 상태 조회
+func (b *DockerExecBackend) inspect(ctx context.Context, execID string) (*dockerExecInspect, error) {
+	var inspect dockerExecInspect
+	if err := b.client.get(ctx, "/exec/"+execID+"/json", &inspect); err != nil {
+		return nil, err
+	}
+	return &inspect, nil
+}
+
+// Close - exec 스트림을 닫고, 셸이 아직 실행 중이면 종료 (여러 번 호출 가능)
+// Docker 는 TTY exec 의 스트림이 닫혀도 프로세스를 종료하지 않으므로 (셸은 SIGHUP 을 받지 않음)
+// 재연결 유예 시간 초과나 강제 종료 뒤에 셸이 남지 않도록 직접 종료
+// 종료 확인에 1초 넘게 걸리므로 닫는 쪽(서버 종료 시 CloseAllTerminals 등)을 막지 않도록 백그라운드에서 진행
+func (b *DockerExecBackend) Close() error {
+	var err error
+	b.closeOnce.Do(func() {
+		if b.stream != nil {
+			err = b.stream.Close()
+		}
+		if b.execID != "" {
+			dockerExecKills.Add(1)
+			go func() {
+				defer dockerExecKills.Done()
+				b.killExec()
+			}()
+		}
+	})
+	return err
+}
+
+// killExec - 실행 중인 exec 프로세스(와 자식 프로세스) 종료 (최대 dockerExecKillTimeout)
+// exec API 에는 종료 기능이 없고 inspect 의 Pid 는 호스트 기준이므로,
+// 표시 환경 변수를 가진 프로세스를 다른 exec (root) 로 찾아서 종료
+// 컨테이너에 /bin/sh, grep, kill 과 /proc 가 있고 root 로 exec 할 수 있어야 함
+// (distroless 이미지처럼 종료 스크립트를 실행할 수 없거나 종료하지 못하면 셸이 남으므로 로그로 남김)
+func (b *DockerExecBackend) killExec() {
+	ctx, cancel := context.WithTimeout(context.Background(), dockerExecKillTimeout)
+	defer cancel()
+
+	if inspect, err := b.inspect(ctx, b.execID); err != nil || !inspect.Running {
+		return
+	}
+
+	var created struct {
+		ID string `json:"Id"`
+	}
+	err := b.client.post(ctx, "/containers/"+url.PathEscape(b.containerID)+"/exec", map[string]interface{}{
+		"Cmd":  []string{"/bin/sh", "-c", fmt.Sprintf(dockerExecKillScript, b.marker)},
+		"User": "0",
+	}, &created)
+	if err == nil {
+		err = b.client.post(ctx, "/exec/"+created.ID+"/start", map[string]interface{}{"Detach": true}, nil)
+	}
+	if err != nil {
+		log.Printf("⚠️ docker exec 종료 스크립트를 실행할 수 없음, 셸이 남아 있을 수 있음: %s (exec: %s): %v", b.containerID, shortDockerID(b.execID), err)
+		return
+	}
+
+	// 셸이 종료될 때까지 대기 (SIGKILL 은 스크립트 시작 1초 뒤)
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			log.Printf("⚠️ docker exec 가 종료되지 않음, 셸이 남아 있음: %s (exec: %s)", b.containerID, shortDockerID(b.execID))
+			return
+		case <-ticker.C:
+		}
+
+		if inspect, err := b.inspect(ctx, b.execID); err == nil && !inspect.Running {
+			log.Printf("docker exec 종료: %s (exec: %s)", b.containerID, shortDockerID(b.execID))
+			return
+		}
+		// /bin/sh 가 없으면 126/127 로 끝남
+		if script, err := b.inspect(ctx, created.ID); err == nil && !script.Running && script.ExitCode != nil && *script.ExitCode != 0 {
+			log.Printf("⚠️ docker exec 종료 스크립트 실패 (종료 코드 %d), 셸이 남아 있음: %s (exec: %s)", *script.ExitCode, b.containerID, shortDockerID(b.execID))
+			return
+		}
+	}
+}
+
+// Info - 터미널 정보
+func (b *DockerExecBackend) Info() map[string]interface{} {
+	return map[string]interface{}{
+		"backend":   MethodDocker,
+		"container": b.containerID,
+		"execId":    shortDockerID(b.execID),
+	}
+}
@@ -0,0 +1,539 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeDocker - unix 소켓에 띄운 Docker Engine API
+// 컨테이너 목록/inspect, exec 생성/시작/크기 조정/조회를 처리
+// exec start 는 연결을 업그레이드해서 입력을 그대로 돌려주고, "exit <코드>" 입력을 받으면 종료
+// 분리 실행(Detach)한 exec 는 종료 스크립트로 보고, 스크립트의 표시 값을 가진 exec 를 종료
+type fakeDocker struct {
+	socket     string
+	containers []dockerContainer
+	inspects   map[string]interface{} // 컨테이너 ID → inspect 응답
+	noShell    bool                   // 종료 스크립트가 127 로 끝남 (/bin/sh 가 없는 이미지)
+
+	mu     sync.Mutex
+	execs  map[string]*fakeDockerExec
+	order  []string // 생성 순서
+	nextID int
+}
+
+// fakeDockerExec - exec 인스턴스 (생성 요청 본문과 실행 상태)
+type fakeDockerExec struct {
+	ContainerID string
+	Cmd         []string
+	User        string
+	Env         []string
+	Tty         bool
+	ConsoleSize []int
+
+	detached bool
+	running  bool
+	exitCode *int
+	resizes  []string
+	conn     net.Conn
+}
+
+func newFakeDocker(t *testing.T) *fakeDocker {
+	// unix 소켓 경로 길이 제한 때문에 t.TempDir 대신 짧은 경로 사용
+	dir, err := os.MkdirTemp("", "docker")
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := &fakeDocker{
+		socket:   filepath.Join(dir, "docker.sock"),
+		inspects: make(map[string]interface{}),
+		execs:    make(map[string]*fakeDockerExec),
+	}
+	listener, err := net.Listen("unix", d.socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /containers/json", d.listContainers)
+	mux.HandleFunc("GET /containers/{id}/json", d.inspectContainer)
+	mux.HandleFunc("POST /containers/{id}/exec", d.createExec)
+	mux.HandleFunc("POST /exec/{id}/start", d.startExec)
+	mux.HandleFunc("POST /exec/{id}/resize", d.resizeExec)
+	mux.HandleFunc("GET /exec/{id}/json", d.inspectExec)
+
+	server := httptest.NewUnstartedServer(mux)
+	server.Listener.Close()
+	server.Listener = listener
+	server.Start()
+	t.Cleanup(func() {
+		d.mu.Lock()
+		for _, exec := range d.execs {
+			if exec.conn != nil {
+				exec.conn.Close()
+			}
+		}
+		d.mu.Unlock()
+		server.Close()
+		os.RemoveAll(dir)
+	})
+	return d
+}
+
+// exec - 생성 순서로 exec 조회 (0 부터)
+func (d *fakeDocker) exec(i int) (fakeDockerExec, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if i >= len(d.order) {
+		return fakeDockerExec{}, false
+	}
+	return *d.execs[d.order[i]], true
+}
+
+func (d *fakeDocker) execCount() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return len(d.order)
+}
+
+func (d *fakeDocker) listContainers(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Query().Get("all") != "1" {
+		writeDockerError(w, http.StatusBadRequest, "all=1 이 필요합니다")
+		return
+	}
+	json.NewEncoder(w).Encode(d.containers)
+}
+
+func (d *fakeDocker) inspectContainer(w http.ResponseWriter, r *http.Request) {
+	inspect, ok := d.inspects[r.PathValue("id")]
+	if !ok {
+		writeDockerError(w, http.StatusNotFound, "No such container: "+r.PathValue("id"))
+		return
+	}
+	json.NewEncoder(w).Encode(inspect)
+}
+
+func (d *fakeDocker) createExec(w http.ResponseWriter, r *http.Request) {
+	exec := &fakeDockerExec{ContainerID: r.PathValue("id")}
+	if err := json.NewDecoder(r.Body).Decode(exec); err != nil {
+		writeDockerError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	d.mu.Lock()
+	d.nextID++
+	id := fmt.Sprintf("e%063d", d.nextID)
+	d.execs[id] = exec
+	d.order = append(d.order, id)
+	d.mu.Unlock()
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]string{"Id": id})
+}
+
+func (d *fakeDocker) startExec(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Detach bool `json:"Detach"`
+		Tty    bool `json:"Tty"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeDockerError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	d.mu.Lock()
+	exec, ok := d.execs[r.PathValue("id")]
+	if !ok {
+		d.mu.Unlock()
+		writeDockerError(w, http.StatusNotFound, "No such exec instance: "+r.PathValue("id"))
+		return
+	}
+	if body.Detach {
+		exec.detached = true
+		d.runKillScript(exec)
+		d.mu.Unlock()
+		return
+	}
+	exec.running = true
+	d.mu.Unlock()
+
+	conn, rw, err := http.NewResponseController(w).Hijack()
+	if err != nil {
+		writeDockerError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	rw.WriteString("HTTP/1.1 101 UPGRADED\r\nContent-Type: application/vnd.docker.raw-stream\r\nConnection: Upgrade\r\nUpgrade: tcp\r\n\r\n")
+	rw.Flush()
+
+	d.mu.Lock()
+	exec.conn = conn
+	d.mu.Unlock()
+	go d.echo(exec, conn)
+}
+
+// echo - 입력을 그대로 출력 ("exit <코드>" 를 받으면 종료 코드를 남기고 연결 종료)
+func (d *fakeDocker) echo(exec *fakeDockerExec, conn net.Conn) {
+	defer conn.Close()
+	buf := make([]byte, 1024)
+	for {
+		n, err := conn.Read(buf)
+		if err != nil {
+			return
+		}
+		if code, ok := strings.CutPrefix(strings.TrimSpace(string(buf[:n])), "exit "); ok {
+			exitCode, _ := strconv.Atoi(code)
+			d.mu.Lock()
+			exec.running = false
+			exec.exitCode = &exitCode
+			d.mu.Unlock()
+			return
+		}
+		if _, err := conn.Write(buf[:n]); err != nil {
+			return
+		}
+	}
+}
+
+// runKillScript - 종료 스크립트에 들어 있는 표시 값을 환경 변수로 가진 exec 종료 (d.mu 를 잡은 상태)
+func (d *fakeDocker) runKillScript(script *fakeDockerExec) {
+	exitCode := 0
+	defer func() { script.exitCode = &exitCode }()
+	if d.noShell {
+		exitCode = 127
+		return
+	}
+	if len(script.Cmd) != 3 || script.User != "0" {
+		return
+	}
+	for _, exec := range d.execs {
+		for _, env := range exec.Env {
+			if exec.running && strings.HasPrefix(env, dockerExecMarkerEnv+"=") && strings.Contains(script.Cmd[2], env) {
+				exitCode := 129 // SIGHUP
+				exec.running = false
+				exec.exitCode = &exitCode
+				exec.conn.Close()
+			}
+		}
+	}
+}
+
+func (d *fakeDocker) resizeExec(w http.ResponseWriter, r *http.Request) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	exec, ok := d.execs[r.PathValue("id")]
+	if !ok {
+		writeDockerError(w, http.StatusNotFound, "No such exec instance: "+r.PathValue("id"))
+		return
+	}
+	exec.resizes = append(exec.resizes, r.URL.RawQuery)
+}
+
+func (d *fakeDocker) inspectExec(w http.ResponseWriter, r *http.Request) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	exec, ok := d.execs[r.PathValue("id")]
+	if !ok {
+		writeDockerError(w, http.StatusNotFound, "No such exec instance: "+r.PathValue("id"))
+		return
+	}
+	json.NewEncoder(w).Encode(dockerExecInspect{Running: exec.running, ExitCode: exec.exitCode})
+}
+
+func writeDockerError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"message": message})
+}
+
+// readUntil - 백엔드 출력에서 want 가 나올 때까지 읽기 (2초 안에 나오지 않으면 실패)
+func readUntil(t *testing.T, r io.Reader, want string) {
+	t.Helper()
+	done := make(chan error, 1)
+	go func() {
+		var output []byte
+		buf := make([]byte, 256)
+		for !strings.Contains(string(output), want) {
+			n, err := r.Read(buf)
+			if err != nil {
+				done <- fmt.Errorf("%v (받은 출력 %q)", err, output)
+				return
+			}
+			output = append(output, buf[:n]...)
+		}
+		done <- nil
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("출력 %q 를 기다리다 실패: %v", want, err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("출력 %q 를 기다리다 시간 초과", want)
+	}
+}
+
+func TestDockerSourceListContainers(t *testing.T) {
+	d := newFakeDocker(t)
+	d.containers = []dockerContainer{
+		{
+			ID: "4f1c2a3b5d6e7f8091a2b3c4", Names: []string{"/web-1"}, Image: "nginx:1.27", Created: 1700000000, State: "running",
+			Labels: map[string]string{"app": "web"},
+			Ports: []dockerPort{
+				{IP: "0.0.0.0", PrivatePort: 80, PublicPort: 8080, Type: "tcp"},
+				{IP: "::", PrivatePort: 80, PublicPort: 8080, Type: "tcp"},
+				{PrivatePort: 443, Type: "tcp"},
+			},
+		},
+		{ID: "abcdef0123456789abcdef", State: "exited"},
+	}
+
+	containers, err := NewDockerSource(d.socket).ListContainers(context.Background())
+	if err != nil {
+		t.Fatalf("ListContainers: %v", err)
+	}
+	want := []ContainerInfo{
+		{
+			ID: "4f1c2a3b5d6e", Name: "web-1", Status: "running", Labels: map[string]string{"app": "web"}, Source: "docker",
+			Image: "nginx:1.27", Created: "2023-11-14T22:13:20Z", Ports: []string{"443/tcp", "8080:80/tcp"},
+		},
+		{ID: "abcdef012345", Name: "abcdef012345", Status: "stopped", Labels: map[string]string{}, Source: "docker", Created: "1970-01-01T00:00:00Z"},
+	}
+	if !reflect.DeepEqual(containers, want) {
+		t.Errorf("ListContainers = %+v, want %+v", containers, want)
+	}
+}
+
+func TestDockerSourceContainerDetail(t *testing.T) {
+	d := newFakeDocker(t)
+	d.inspects["web-1"] = map[string]interface{}{
+		"Path":         "nginx",
+		"Args":         []string{"-g", "daemon off;", "--upstream=https://svc:pw@api", "--api-token", "abc"},
+		"RestartCount": 2,
+		"State": map[string]interface{}{
+			"Status":    "running",
+			"StartedAt": "2024-05-01T09:00:00.123456789Z",
+			"Health":    map[string]string{"Status": "healthy"},
+		},
+		"Config": map[string]interface{}{"Env": []string{"PATH=/usr/bin", "DB_PASSWORD=hunter2", "UPSTREAM=https://svc:pw@api"}},
+		"HostConfig": map[string]interface{}{
+			"Memory": 512 * 1024 * 1024, "NanoCpus": 1500000000, "CpuShares": 512, "PidsLimit": 100,
+		},
+		"Mounts": []map[string]interface{}{
+			{"Type": "volume", "Name": "data", "Source": "/var/lib/docker/volumes/data/_data", "Destination": "/data", "RW": true},
+			{"Type": "bind", "Source": "/etc/nginx", "Destination": "/etc/nginx", "RW": false},
+		},
+	}
+	source := NewDockerSource(d.socket)
+
+	detail, err := source.ContainerDetail(context.Background(), &ContainerInfo{ID: "web-1", Name: "web-1"})
+	if err != nil {
+		t.Fatalf("ContainerDetail: %v", err)
+	}
+	if want := []string{"nginx", "-g", "daemon off;", "--upstream=https://" + redactedValue + "@api", "--api-token", redactedValue}; !reflect.DeepEqual(detail.Command, want) {
+		t.Errorf("Command = %q", detail.Command)
+	}
+	if detail.State != "running (healthy)" || detail.RestartCount != 2 || detail.StartedAt != "2024-05-01T09:00:00.123456789Z" {
+		t.Errorf("State = %q, RestartCount = %d, StartedAt = %q", detail.State, detail.RestartCount, detail.StartedAt)
+	}
+	if want := (ResourceLimits{CPULimit: "1500m", CPURequest: "500m", MemoryLimit: "512Mi", PidsLimit: 100}); detail.Resources == nil || *detail.Resources != want {
+		t.Errorf("Resources = %+v", detail.Resources)
+	}
+	wantEnv := []EnvVar{
+		{Name: "PATH", Value: "/usr/bin"},
+		{Name: "DB_PASSWORD", Value: redactedValue, Redacted: true},
+		{Name: "UPSTREAM", Value: redactedValue, Redacted: true},
+	}
+	if !reflect.DeepEqual(detail.Env, wantEnv) {
+		t.Errorf("Env = %+v", detail.Env)
+	}
+	wantMounts := []Mount{
+		{Type: "volume", Source: "data", Destination: "/data"},
+		{Type: "bind", Source: "/etc/nginx", Destination: "/etc/nginx", ReadOnly: true},
+	}
+	if !reflect.DeepEqual(detail.Mounts, wantMounts) {
+		t.Errorf("Mounts = %+v", detail.Mounts)
+	}
+
+	_, err = source.ContainerDetail(context.Background(), &ContainerInfo{ID: "missing"})
+	if err == nil || !strings.Contains(err.Error(), "HTTP 404") || !strings.Contains(err.Error(), "No such container: missing") {
+		t.Errorf("없는 컨테이너 에러 = %v", err)
+	}
+}
+
+func TestDockerExecBackendSession(t *testing.T) {
+	d := newFakeDocker(t)
+	b := NewDockerSource(d.socket).NewDockerExecBackend("web-1", []string{"/bin/sh"}, "app", "xterm-256color")
+	if err := b.Start(context.Background(), 120, 40); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer b.Close()
+
+	exec, _ := d.exec(0)
+	if exec.ContainerID != "web-1" || !exec.Tty || exec.User != "app" || !reflect.DeepEqual(exec.Cmd, []string{"/bin/sh"}) {
+		t.Errorf("exec 생성 요청 = %+v", exec)
+	}
+	if want := []string{"TERM=xterm-256color", dockerExecMarkerEnv + "=" + b.marker}; !reflect.DeepEqual(exec.Env, want) {
+		t.Errorf("Env = %q, want %q", exec.Env, want)
+	}
+	if want := []int{40, 120}; !reflect.DeepEqual(exec.ConsoleSize, want) {
+		t.Errorf("ConsoleSize = %v, want %v", exec.ConsoleSize, want)
+	}
+
+	if _, err := b.Write([]byte("ls -al\n")); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	readUntil(t, b, "ls -al\n")
+
+	if err := b.Resize(100, 30); err != nil {
+		t.Fatalf("Resize: %v", err)
+	}
+	if exec, _ := d.exec(0); !reflect.DeepEqual(exec.resizes, []string{"h=30&w=100"}) {
+		t.Errorf("크기 조정 요청 = %q", exec.resizes)
+	}
+
+	// 시그널은 TTY 제어 문자로 전달
+	if err := b.Signal("INT"); err != nil {
+		t.Fatalf("Signal: %v", err)
+	}
+	readUntil(t, b, "\x03")
+	if err := b.Signal("TERM"); !errors.Is(err, errSignalUnsupported) {
+		t.Errorf("Signal(TERM) = %v", err)
+	}
+
+	if _, err := b.Write([]byte("exit 3\n")); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if _, err := io.Copy(io.Discard, b); err != nil {
+		t.Fatalf("종료 후 읽기: %v", err)
+	}
+	if code, err := b.Wait(); err != nil || code != 3 {
+		t.Errorf("Wait = %d, %v", code, err)
+	}
+
+	// 이미 끝난 exec 는 종료 스크립트를 실행하지 않음
+	b.Close()
+	if n := d.execCount(); n != 1 {
+		t.Errorf("exec 수 = %d, want 1", n)
+	}
+}
+
+func TestDockerExecBackendCloseKillsShell(t *testing.T) {
+	d := newFakeDocker(t)
+	b := NewDockerSource(d.socket).NewDockerExecBackend("web-1", []string{"/bin/sh"}, "", "xterm")
+	if err := b.Start(context.Background(), 80, 24); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+
+	// 셸이 실행 중인 상태로 닫으면 표시 값으로 셸을 찾아 종료하는 exec 를 분리 실행 (백그라운드)
+	if err := b.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	dockerExecKills.Wait()
+	shell, _ := d.exec(0)
+	if shell.running || shell.exitCode == nil {
+		t.Error("셸이 종료되지 않음")
+	}
+	script, ok := d.exec(1)
+	if !ok {
+		t.Fatal("종료 exec 가 생성되지 않음")
+	}
+	if !script.detached || script.User != "0" || script.ContainerID != "web-1" || !strings.Contains(script.Cmd[2], dockerExecMarkerEnv+"="+b.marker) {
+		t.Errorf("종료 exec = %+v", script)
+	}
+
+	b.Close()
+	dockerExecKills.Wait()
+	if n := d.execCount(); n != 2 {
+		t.Errorf("두 번 닫은 뒤 exec 수 = %d, want 2", n)
+	}
+}
+
+func TestDockerExecBackendKillScriptFails(t *testing.T) {
+	d := newFakeDocker(t)
+	d.noShell = true
+	b := NewDockerSource(d.socket).NewDockerExecBackend("web-1", []string{"/bin/sh"}, "", "xterm")
+	if err := b.Start(context.Background(), 80, 24); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+
+	// 종료 스크립트가 실패하면 제한 시간까지 기다리지 않고 끝남 (셸은 남음)
+	start := time.Now()
+	b.Close()
+	dockerExecKills.Wait()
+	if elapsed := time.Since(start); elapsed >= dockerExecKillTimeout {
+		t.Errorf("종료 스크립트 실패 확인까지 %v", elapsed)
+	}
+	if shell, _ := d.exec(0); !shell.running {
+		t.Error("종료 스크립트 없이 셸이 종료됨")
+	}
+}
+
+func TestDockerSourceUnavailable(t *testing.T) {
+	dir := t.TempDir()
+	_, err := NewDockerSource(filepath.Join(dir, "missing.sock")).ListContainers(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "/containers/json") {
+		t.Errorf("소켓이 없을 때 에러 = %v", err)
+	}
+}
+
+// staticSource - 고정된 목록(또는 에러)을 반환하는 소스
+type staticSource struct {
+	name       string
+	containers []ContainerInfo
+	err        error
+}
+
+func (s *staticSource) Name() string { return s.name }
+
+func (s *staticSource) ListContainers(ctx context.Context) ([]ContainerInfo, error) {
+	return s.containers, s.err
+}
+
+func TestMultiSource(t *testing.T) {
+	docker := &staticSource{name: "docker", containers: []ContainerInfo{{ID: "a", Source: "docker"}}}
+	teleport := &staticSource{name: "teleport", containers: []ContainerInfo{{ID: "b", Source: "teleport"}}}
+	broken := &staticSource{name: "kubernetes", err: errors.New("연결 거부")}
+
+	source := NewMultiSource(teleport, docker)
+	if name := source.Name(); name != "teleport+docker" {
+		t.Errorf("Name = %q", name)
+	}
+	containers, err := source.ListContainers(context.Background())
+	if err != nil || len(containers) != 2 || containers[0].ID != "b" || containers[1].ID != "a" {
+		t.Errorf("ListContainers = %+v, %v", containers, err)
+	}
+
+	// 일부 소스만 실패하면 나머지 목록과 실패한 소스를 알림
+	containers, err = NewMultiSource(docker, broken).ListContainers(context.Background())
+	partial, ok := asPartialList(err)
+	if !ok || len(containers) != 1 || containers[0].ID != "a" || !reflect.DeepEqual(partial.Failed, []FailedScope{{Source: "kubernetes"}}) {
+		t.Errorf("일부 실패 = %+v, %v", containers, err)
+	}
+
+	// 소스 안에서 일부만 실패하면 받은 목록은 합치고 실패 범위는 그대로 전달
+	degraded := &staticSource{name: "teleport", containers: []ContainerInfo{{ID: "f", Source: "file"}}, err: &PartialListError{Failed: []FailedScope{{Source: "teleport"}}}}
+	containers, err = NewMultiSource(degraded, docker, broken).ListContainers(context.Background())
+	partial, ok = asPartialList(err)
+	if !ok || containerIDs(containers) != "f,a" || !reflect.DeepEqual(partial.Failed, []FailedScope{{Source: "teleport"}, {Source: "kubernetes"}}) {
+		t.Errorf("소스 안의 일부 실패 = %+v, %v", containers, err)
+	}
+
+	// 모든 소스가 실패하면 에러
+	if _, err := NewMultiSource(broken).ListContainers(context.Background()); err == nil || !strings.Contains(err.Error(), "연결 거부") {
+		t.Errorf("모두 실패 에러 = %v", err)
+	}
+}
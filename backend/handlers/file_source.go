@@ -0,0 +1,250 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+// 접속 방식 (ContainerInfo.Method)
+const (
+	MethodSSH        = "ssh"
+	MethodTeleport   = "teleport"
+	MethodDocker     = "docker"
+	MethodKubernetes = "kubernetes"
+	MethodLocal      = "local"
+)
+
+// FileSource - YAML/JSON 인벤토리 파일 기반 컨테이너 목록 제공자
+// 파일이 바뀌면 재시작 없이 다시 읽음 (잘못된 파일이면 이전 목록 유지)
+type FileSource struct {
+	path string
+
+	mu         sync.RWMutex
+	containers []ContainerInfo
+	loaded     bool
+	modTime    time.Time
+	size       int64
+	lastErr    error
+}
+
+// inventoryFile - 인벤토리 파일 형식
+//
+//	containers:
+//	  - id: web-001
+//	    name: nginx-server
+//	    address: 10.0.1.10:22
+//	    login: ubuntu
+//	    method: ssh
+//	    labels:
+//	      environment: production
+type inventoryFile struct {
+	Containers []inventoryEntry `yaml:"containers" json:"containers"`
+}
+
+type inventoryEntry struct {
+	ID      string            `yaml:"id" json:"id"`
+	Name    string            `yaml:"name" json:"name"`
+	Address string            `yaml:"address" json:"address"`
+	Login   string            `yaml:"login" json:"login"`
+	Method  string            `yaml:"method" json:"method"`
+	Status  string            `yaml:"status" json:"status"`
+	Labels  map[string]string `yaml:"labels" json:"labels"`
+}
+
+// NewFileSource - 인벤토리 파일 소스 생성 (처음 한 번 읽기)
+// 읽기에 실패해도 소스를 반환하므로 Watch 로 파일이 고쳐지면 다시 읽음 (그 전까지는 조회 실패)
+func NewFileSource(path string) (*FileSource, error) {
+	s := &FileSource{path: path}
+	return s, s.reload()
+}
+
+// Name - 소스 이름
+func (s *FileSource) Name() string {
+	return "file"
+}
+
+// ListContainers - 마지막으로 읽은 목록 반환
+func (s *FileSource) ListContainers(ctx context.Context) ([]ContainerInfo, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if !s.loaded {
+		return nil, s.lastErr
+	}
+	containers := make([]ContainerInfo, len(s.containers))
+	copy(containers, s.containers)
+	return containers, nil
+}
+
+// Watch - ctx 가 끝날 때까지 파일 변경(수정 시각/크기)을 확인해 다시 읽음
+func (s *FileSource) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		info, err := os.Stat(s.path)
+		if err != nil {
+			continue
+		}
+
+		s.mu.RLock()
+		changed := !info.ModTime().Equal(s.modTime) || info.Size() != s.size
+		s.mu.RUnlock()
+		if !changed {
+			continue
+		}
+
+		if err := s.reload(); err != nil {
+			log.Printf("인벤토리 파일 다시 읽기 실패 (이전 목록 유지): %v", err)
+			continue
+		}
+		log.Printf("인벤토리 파일 다시 읽음: %s", s.path)
+	}
+}
+
+// reload - 파일을 읽고 검증한 뒤 목록 교체
+func (s *FileSource) reload() error {
+	info, err := os.Stat(s.path)
+	if err != nil {
+		return s.fail(fmt.Errorf("인벤토리 파일 확인 실패: %v", err), info)
+	}
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return s.fail(fmt.Errorf("인벤토리 파일 읽기 실패: %v", err), info)
+	}
+
+	containers, err := parseInventoryFile(s.path, data)
+	if err != nil {
+		return s.fail(fmt.Errorf("%s: %v", s.path, err), info)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.containers = containers
+	s.loaded = true
+	s.modTime = info.ModTime()
+	s.size = info.Size()
+	s.lastErr = nil
+	return nil
+}
+
+// fail - 에러 기록 (같은 파일을 반복해서 다시 읽지 않도록 수정 시각은 갱신)
+func (s *FileSource) fail(err error, info os.FileInfo) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lastErr = err
+	if info != nil {
+		s.modTime = info.ModTime()
+		s.size = info.Size()
+	}
+	return err
+}
+
+// parseInventoryFile - 확장자에 따라 YAML/JSON 파싱 후 검증
+func parseInventoryFile(path string, data []byte) ([]ContainerInfo, error) {
+	var file inventoryFile
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		// YAML 과 같이 모르는 필드(오타)는 에러
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&file); err != nil {
+			return nil, fmt.Errorf("JSON 파싱 실패: %v", err)
+		}
+		if _, err := decoder.Token(); err != io.EOF {
+			return nil, fmt.Errorf("JSON 파싱 실패: 문서 뒤에 데이터가 있음")
+		}
+	default:
+		if err := yaml.UnmarshalStrict(data, &file); err != nil {
+			return nil, fmt.Errorf("YAML 파싱 실패: %v", err)
+		}
+	}
+
+	seen := make(map[string]bool)
+	containers := make([]ContainerInfo, 0, len(file.Containers))
+	for i, entry := range file.Containers {
+		if err := validateInventoryEntry(&entry); err != nil {
+			return nil, fmt.Errorf("containers[%d]: %v", i, err)
+		}
+		if seen[entry.ID] {
+			return nil, fmt.Errorf("containers[%d]: 중복된 id %q", i, entry.ID)
+		}
+		seen[entry.ID] = true
+
+		labels := entry.Labels
+		if labels == nil {
+			labels = map[string]string{}
+		}
+		containers = append(containers, ContainerInfo{
+			ID:       entry.ID,
+			Name:     entry.Name,
+			Status:   entry.Status,
+			Labels:   labels,
+			NodeAddr: entry.Address,
+			Source:   "file",
+			Login:    entry.Login,
+			Method:   entry.Method,
+		})
+	}
+	return containers, nil
+}
+
+// validateInventoryEntry - 항목 검증 및 기본값 채우기
+func validateInventoryEntry(entry *inventoryEntry) error {
+	if entry.ID == "" {
+		return fmt.Errorf("id 가 비어 있음")
+	}
+	if strings.ContainsAny(entry.ID, "/ ") {
+		return fmt.Errorf("id %q 에 '/' 나 공백을 사용할 수 없음", entry.ID)
+	}
+	if entry.Name == "" {
+		entry.Name = entry.ID
+	}
+	if entry.Method == "" {
+		entry.Method = MethodSSH
+	}
+	if entry.Status == "" {
+		entry.Status = "online"
+	}
+
+	switch entry.Method {
+	case MethodSSH, MethodTeleport:
+		if entry.Address == "" {
+			return fmt.Errorf("%s: %s 방식은 address 가 필요함", entry.ID, entry.Method)
+		}
+	case MethodDocker, MethodKubernetes, MethodLocal:
+	default:
+		return fmt.Errorf("%s: 알 수 없는 method %q", entry.ID, entry.Method)
+	}
+
+	if entry.Address != "" {
+		if _, _, err := net.SplitHostPort(entry.Address); err != nil {
+			return fmt.Errorf("%s: 잘못된 address %q (host:port 형식): %v", entry.ID, entry.Address, err)
+		}
+	}
+
+	switch entry.Status {
+	case "online", "offline", "running", "stopped", "pending", "error", "unknown":
+	default:
+		return fmt.Errorf("%s: 알 수 없는 status %q", entry.ID, entry.Status)
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseInventoryFile(t *testing.T) {
+	containers, err := parseInventoryFile("inventory.json", []byte(`{"containers": [
+		{"id": "web-1", "address": "10.0.1.10:22", "labels": {"env": "prod"}},
+		{"id": "db-1", "method": "docker"}
+	]}`))
+	if err != nil {
+		t.Fatalf("parseInventoryFile: %v", err)
+	}
+	want := []ContainerInfo{
+		{ID: "web-1", Name: "web-1", Status: "online", Labels: map[string]string{"env": "prod"}, NodeAddr: "10.0.1.10:22", Source: "file", Method: MethodSSH},
+		{ID: "db-1", Name: "db-1", Status: "online", Labels: map[string]string{}, Source: "file", Method: MethodDocker},
+	}
+	if !reflect.DeepEqual(containers, want) {
+		t.Errorf("parseInventoryFile = %+v, want %+v", containers, want)
+	}
+
+	for name, tc := range map[string]struct {
+		path, data, message string
+	}{
+		"JSON 모르는 필드":   {"inventory.json", `{"containers": [{"id": "web-1", "adress": "10.0.1.10:22"}]}`, "adress"},
+		"JSON 최상위 오타":   {"inventory.json", `{"container": []}`, "container"},
+		"JSON 뒤에 데이터":   {"inventory.json", `{"containers": []} {}`, "뒤에 데이터"},
+		"YAML 모르는 필드":   {"inventory.yaml", "containers:\n  - id: web-1\n    adress: 10.0.1.10:22\n", "adress"},
+		"address 없음":    {"inventory.json", `{"containers": [{"id": "web-1"}]}`, "address 가 필요함"},
+		"중복된 id":        {"inventory.yaml", "containers:\n  - {id: a, method: local}\n  - {id: a, method: local}\n", "중복된 id"},
+		"알 수 없는 method": {"inventory.yaml", "containers:\n  - {id: a, method: rdp}\n", "알 수 없는 method"},
+	} {
+		if _, err := parseInventoryFile(tc.path, []byte(tc.data)); err == nil || !strings.Contains(err.Error(), tc.message) {
+			t.Errorf("%s: 에러 = %v", name, err)
+		}
+	}
+}
+
+func TestFileSourceKeepsLastGoodList(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "inventory.json")
+	if err := os.WriteFile(path, []byte(`{"containers": [{"id": "web-1", "method": "local"}]}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	source, err := NewFileSource(path)
+	if err != nil {
+		t.Fatalf("NewFileSource: %v", err)
+	}
+
+	// 잘못된 파일로 바뀌면 이전 목록 유지
+	if err := os.WriteFile(path, []byte(`{"containers": [{"id": "web-2", "methd": "local"}]}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := source.reload(); err == nil {
+		t.Error("모르는 필드가 있는 파일을 읽음")
+	}
+	if containers, err := source.ListContainers(context.Background()); err != nil || len(containers) != 1 || containers[0].ID != "web-1" {
+		t.Errorf("ListContainers = %+v, %v", containers, err)
+	}
+}
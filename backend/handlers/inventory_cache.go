@@ -0,0 +1,215 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// 캐시에 없는 ID 조회 시 즉시 갱신을 허용하는 최소 간격
+const inventoryMissRefreshGap = 2 * time.Second
+
+// InventoryCache - 컨테이너 목록 캐시
+// 백그라운드에서 주기적으로 소스를 갱신하고, 요청은 캐시된 목록으로 바로 응답
+type InventoryCache struct {
+	source          ContainerSource
+	refreshInterval time.Duration // 백그라운드 갱신 주기
+	ttl             time.Duration // 마지막 성공 갱신 후 이 시간이 지나면 stale
+
+	refreshMu sync.Mutex // 동시에 한 번만 갱신
+
+	mu            sync.RWMutex
+	containers    []ContainerInfo
+	byID          map[string]int // ID → containers 인덱스
+	synced        bool
+	lastSyncedAt  time.Time // 마지막 성공 갱신 시각
+	lastAttemptAt time.Time // 마지막 갱신 시도 시각
+	lastErr       error     // 마지막 갱신 에러 (일부 소스만 실패한 경우 포함)
+	listeners     []func([]ContainerInfo, *PartialListError)
+}
+
+// InventoryStatus - 캐시 상태 (API 응답용)
+type InventoryStatus struct {
+	Stale        bool       `json:"stale"`
+	LastSyncedAt *time.Time `json:"lastSyncedAt,omitempty"`
+	Error        string     `json:"error,omitempty"`
+}
+
+// NewInventoryCache - 인벤토리 캐시 생성
+func NewInventoryCache(source ContainerSource, refreshInterval, ttl time.Duration) *InventoryCache {
+	return &InventoryCache{
+		source:          source,
+		refreshInterval: refreshInterval,
+		ttl:             ttl,
+		byID:            make(map[string]int),
+	}
+}
+
+// OnRefresh - 갱신 성공 시마다 호출될 함수 등록 (예: InventoryWatcher.apply)
+// 일부 소스만 실패했으면 partial 에 실패한 범위 전달 (아니면 nil)
+func (c *InventoryCache) OnRefresh(listener func(containers []ContainerInfo, partial *PartialListError)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.listeners = append(c.listeners, listener)
+}
+
+// Run - ctx 가 끝날 때까지 주기적으로 갱신
+func (c *InventoryCache) Run(ctx context.Context) {
+	ticker := time.NewTicker(c.refreshInterval)
+	defer ticker.Stop()
+
+	for {
+		refreshCtx, cancel := context.WithTimeout(ctx, c.refreshInterval)
+		if err := c.Refresh(refreshCtx); err != nil {
+			log.Printf("인벤토리 캐시 갱신 실패: %v", err)
+		}
+		cancel()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Refresh - 소스에서 목록을 다시 가져와 캐시 교체
+// 실패하면 이전 목록을 유지하고 에러만 기록
+// 일부 소스만 실패하면 나머지 목록으로 교체하되, 실패한 범위의 항목은 이전 목록에서 유지
+func (c *InventoryCache) Refresh(ctx context.Context) error {
+	c.refreshMu.Lock()
+	defer c.refreshMu.Unlock()
+
+	start := time.Now()
+	containers, err := c.source.ListContainers(ctx)
+	partial, _ := asPartialList(err)
+
+	c.mu.Lock()
+	c.lastAttemptAt = start
+	c.lastErr = err
+	if err != nil && partial == nil {
+		c.mu.Unlock()
+		return err
+	}
+
+	if partial != nil {
+		containers = c.carryOver(containers, partial)
+	}
+	c.containers = containers
+	c.byID = make(map[string]int, len(containers))
+	for i := range containers {
+		c.byID[containers[i].ID] = i
+	}
+	c.synced = true
+	c.lastSyncedAt = time.Now()
+	listeners := c.listeners
+	c.mu.Unlock()
+
+	if elapsed := time.Since(start); elapsed > time.Second {
+		log.Printf("인벤토리 갱신 지연: %v (%d개)", elapsed, len(containers))
+	}
+
+	for _, listener := range listeners {
+		listener(containers, partial)
+	}
+	return nil
+}
+
+// carryOver - 이번 목록에 없는 이전 항목 중 조회에 실패한 범위의 항목을 덧붙임 (c.mu 잠금 상태에서 호출)
+func (c *InventoryCache) carryOver(containers []ContainerInfo, partial *PartialListError) []ContainerInfo {
+	current := make(map[string]bool, len(containers))
+	for _, container := range containers {
+		current[container.ID] = true
+	}
+	for _, prev := range c.containers {
+		if !current[prev.ID] && partial.Covers(prev) {
+			containers = append(containers, prev)
+		}
+	}
+	return containers
+}
+
+// Name - 원본 소스 이름
+func (c *InventoryCache) Name() string {
+	return c.source.Name()
+}
+
+// ListContainers - 캐시된 목록 반환 (한 번도 갱신되지 않았으면 즉시 갱신)
+func (c *InventoryCache) ListContainers(ctx context.Context) ([]ContainerInfo, error) {
+	if err := c.ensureSynced(ctx); err != nil {
+		return nil, err
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	containers := make([]ContainerInfo, len(c.containers))
+	copy(containers, c.containers)
+	return containers, nil
+}
+
+// Get - ID로 컨테이너 조회
+// 캐시에 없으면 (최소 간격을 지켜) 한 번 갱신 후 다시 확인
+func (c *InventoryCache) Get(ctx context.Context, containerID string) (*ContainerInfo, error) {
+	if err := c.ensureSynced(ctx); err != nil {
+		return nil, err
+	}
+
+	if container := c.lookup(containerID); container != nil {
+		return container, nil
+	}
+
+	c.mu.RLock()
+	canRefresh := time.Since(c.lastAttemptAt) > inventoryMissRefreshGap
+	c.mu.RUnlock()
+	if canRefresh {
+		if err := c.Refresh(ctx); err != nil {
+			log.Printf("인벤토리 즉시 갱신 실패: %v", err)
+		}
+	}
+	return c.lookup(containerID), nil
+}
+
+// lookup - 캐시에서 ID 검색
+func (c *InventoryCache) lookup(containerID string) *ContainerInfo {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	i, exists := c.byID[containerID]
+	if !exists {
+		return nil
+	}
+	container := c.containers[i]
+	return &container
+}
+
+// ensureSynced - 첫 갱신 전이면 동기적으로 갱신
+func (c *InventoryCache) ensureSynced(ctx context.Context) error {
+	c.mu.RLock()
+	synced := c.synced
+	c.mu.RUnlock()
+
+	if synced {
+		return nil
+	}
+	return c.Refresh(ctx)
+}
+
+// Status - 캐시 상태 조회
+func (c *InventoryCache) Status() InventoryStatus {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	status := InventoryStatus{
+		Stale: !c.synced || time.Since(c.lastSyncedAt) > c.ttl,
+	}
+	if c.synced {
+		lastSyncedAt := c.lastSyncedAt
+		status.LastSyncedAt = &lastSyncedAt
+	}
+	if c.lastErr != nil {
+		status.Error = c.lastErr.Error()
+	}
+	return status
+}
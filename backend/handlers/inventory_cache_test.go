@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// drainInventoryEvents - 채널에 쌓인 이벤트 (종류:ID)
+func drainInventoryEvents(ch chan InventoryEvent) []string {
+	var events []string
+	for {
+		select {
+		case event := <-ch:
+			events = append(events, event.Type+":"+event.Container.ID)
+		default:
+			return events
+		}
+	}
+}
+
+func TestInventoryCachePartialRefresh(t *testing.T) {
+	source := &staticSource{name: "docker+kubernetes", containers: []ContainerInfo{
+		{ID: "a", Source: "docker", Status: "online"},
+		{ID: "b", Source: "kubernetes", Status: "online"},
+	}}
+	cache := NewInventoryCache(source, time.Minute, time.Minute)
+	watcher := NewInventoryWatcher()
+	cache.OnRefresh(watcher.apply)
+	_, _, _, events, _ := watcher.Subscribe(0, false)
+	ctx := context.Background()
+
+	ids := func() []string {
+		containers, err := cache.ListContainers(ctx)
+		if err != nil {
+			t.Fatalf("ListContainers: %v", err)
+		}
+		var ids []string
+		for _, c := range containers {
+			ids = append(ids, c.ID+"/"+c.Status)
+		}
+		return ids
+	}
+
+	if err := cache.Refresh(ctx); err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+	if got := drainInventoryEvents(events); !reflect.DeepEqual(got, []string{"added:a", "added:b"}) {
+		t.Errorf("첫 갱신 이벤트 = %q", got)
+	}
+
+	// kubernetes 만 실패: docker 항목은 새 목록으로, kubernetes 항목은 이전 목록에서 유지
+	source.containers = []ContainerInfo{{ID: "a", Source: "docker", Status: "offline"}, {ID: "c", Source: "docker", Status: "online"}}
+	source.err = &PartialListError{Failed: []FailedScope{{Source: "kubernetes"}}}
+	if err := cache.Refresh(ctx); err != nil {
+		t.Fatalf("일부 실패 Refresh: %v", err)
+	}
+	if got, want := ids(), []string{"a/offline", "c/online", "b/online"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("일부 실패 후 목록 = %q, want %q", got, want)
+	}
+	if container, _ := cache.Get(ctx, "b"); container == nil {
+		t.Error("실패한 소스의 항목을 찾을 수 없음")
+	}
+	if status := cache.Status(); status.Error == "" || status.Stale {
+		t.Errorf("일부 실패 후 상태 = %+v", status)
+	}
+	if got := drainInventoryEvents(events); !reflect.DeepEqual(got, []string{"updated:a", "added:c"}) {
+		t.Errorf("일부 실패 이벤트 = %q", got)
+	}
+
+	// 연속으로 실패해도 유지
+	if err := cache.Refresh(ctx); err != nil {
+		t.Fatalf("다시 일부 실패 Refresh: %v", err)
+	}
+	if got, want := ids(), []string{"a/offline", "c/online", "b/online"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("다시 일부 실패 후 목록 = %q, want %q", got, want)
+	}
+
+	// 모두 실패하면 이전 목록을 그대로 유지하고 에러
+	source.containers, source.err = nil, errors.New("연결 거부")
+	if err := cache.Refresh(ctx); err == nil {
+		t.Error("모두 실패 Refresh 에러 없음")
+	}
+	if got := ids(); len(got) != 3 {
+		t.Errorf("모두 실패 후 목록 = %q", got)
+	}
+
+	// kubernetes 가 복구되어 b 가 없으면 삭제
+	source.containers, source.err = []ContainerInfo{{ID: "a", Source: "docker", Status: "offline"}}, nil
+	if err := cache.Refresh(ctx); err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+	if got := ids(); !reflect.DeepEqual(got, []string{"a/offline"}) {
+		t.Errorf("복구 후 목록 = %q", got)
+	}
+	if got := drainInventoryEvents(events); !reflect.DeepEqual(got, []string{"removed:b", "removed:c"}) {
+		t.Errorf("복구 후 이벤트 = %q", got)
+	}
+	if status := cache.Status(); status.Error != "" {
+		t.Errorf("복구 후 상태 = %+v", status)
+	}
+}
+
+func TestInventoryCacheFirstSync(t *testing.T) {
+	source := &staticSource{name: "docker", err: errors.New("연결 거부")}
+	cache := NewInventoryCache(source, time.Minute, time.Minute)
+	ctx := context.Background()
+
+	// 한 번도 갱신하지 못했으면 stale 이고 조회는 에러
+	if _, err := cache.ListContainers(ctx); err == nil {
+		t.Error("첫 갱신 실패 ListContainers 에러 없음")
+	}
+	if status := cache.Status(); !status.Stale || status.LastSyncedAt != nil {
+		t.Errorf("첫 갱신 실패 상태 = %+v", status)
+	}
+
+	source.containers, source.err = []ContainerInfo{{ID: "a"}}, nil
+	if container, err := cache.Get(ctx, "a"); err != nil || container == nil {
+		t.Errorf("Get = %+v, %v", container, err)
+	}
+	// 캐시에 없는 ID 는 최소 간격 안에서는 다시 갱신하지 않음
+	source.containers = []ContainerInfo{{ID: "a"}, {ID: "b"}}
+	if container, err := cache.Get(ctx, "b"); err != nil || container != nil {
+		t.Errorf("간격 안의 Get(b) = %+v, %v", container, err)
+	}
+}
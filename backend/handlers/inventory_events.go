@@ -0,0 +1,285 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// 인벤토리 이벤트 타입
+const (
+	InventoryEventAdded   = "added"
+	InventoryEventUpdated = "updated"
+	InventoryEventRemoved = "removed"
+)
+
+const (
+	inventoryEventHistory   = 1000             // 재연결용으로 보관할 최근 이벤트 수
+	inventorySubscriberBuf  = 256              // 구독자별 이벤트 버퍼
+	inventoryEventHeartbeat = 15 * time.Second // SSE keep-alive 주기
+)
+
+// InventoryEvent - 컨테이너 추가/변경/삭제 이벤트
+type InventoryEvent struct {
+	ID        uint64        `json:"id"` // 재연결 커서 (단조 증가)
+	Type      string        `json:"type"`
+	Container ContainerInfo `json:"container"`
+	Time      time.Time     `json:"time"`
+}
+
+// InventoryWatcher - 캐시가 갱신될 때마다 이전 목록과의 차이를 이벤트로 발행 (InventoryCache.OnRefresh)
+type InventoryWatcher struct {
+	mu          sync.Mutex
+	snapshot    map[string]ContainerInfo // 마지막으로 조회한 목록
+	events      []InventoryEvent         // 최근 이벤트 (오래된 순)
+	lastID      uint64                   // 마지막 이벤트 ID
+	subscribers map[chan InventoryEvent]struct{}
+	transitions map[string][]StateTransition // ID → 최근 상태 변화 (오래된 순)
+}
+
+// NewInventoryWatcher - 인벤토리 감시자 생성
+func NewInventoryWatcher() *InventoryWatcher {
+	return &InventoryWatcher{
+		snapshot:    make(map[string]ContainerInfo),
+		subscribers: make(map[chan InventoryEvent]struct{}),
+		transitions: make(map[string][]StateTransition),
+	}
+}
+
+// apply - 새 목록과 이전 목록을 비교해 이벤트 생성
+// 조회에 실패한 소스/클러스터(partial)의 컨테이너는 목록에 없어도 삭제로 보지 않고 이전 상태 유지
+func (w *InventoryWatcher) apply(containers []ContainerInfo, partial *PartialListError) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	now := time.Now()
+	current := make(map[string]ContainerInfo, len(containers))
+	for _, c := range containers {
+		current[c.ID] = c
+
+		prev, exists := w.snapshot[c.ID]
+		switch {
+		case !exists:
+			w.publish(InventoryEventAdded, c, now)
+			w.recordTransition(c.ID, "", c.Status, now)
+		case !reflect.DeepEqual(prev, c):
+			w.publish(InventoryEventUpdated, c, now)
+			if prev.Status != c.Status {
+				w.recordTransition(c.ID, prev.Status, c.Status, now)
+			}
+		}
+	}
+
+	// 삭제된 항목 (ID 순으로 발행)
+	var removed []string
+	for id, prev := range w.snapshot {
+		if _, exists := current[id]; exists {
+			continue
+		}
+		if partial != nil && partial.Covers(prev) {
+			current[id] = prev
+			continue
+		}
+		removed = append(removed, id)
+	}
+	sort.Strings(removed)
+	for _, id := range removed {
+		w.publish(InventoryEventRemoved, w.snapshot[id], now)
+		delete(w.transitions, id)
+	}
+
+	w.snapshot = current
+}
+
+// recordTransition - 상태 변화 기록 (w.mu 잠금 상태에서 호출)
+func (w *InventoryWatcher) recordTransition(id, from, to string, now time.Time) {
+	history := append(w.transitions[id], StateTransition{From: from, To: to, Time: now})
+	if len(history) > maxStateTransitions {
+		history = history[len(history)-maxStateTransitions:]
+	}
+	w.transitions[id] = history
+}
+
+// Transitions - 컨테이너의 최근 상태 변화 (오래된 순)
+func (w *InventoryWatcher) Transitions(id string) []StateTransition {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	history := w.transitions[id]
+	if len(history) == 0 {
+		return nil
+	}
+	result := make([]StateTransition, len(history))
+	copy(result, history)
+	return result
+}
+
+// publish - 이벤트 기록 및 구독자에게 전달 (w.mu 잠금 상태에서 호출)
+func (w *InventoryWatcher) publish(eventType string, container ContainerInfo, now time.Time) {
+	w.lastID++
+	event := InventoryEvent{
+		ID:        w.lastID,
+		Type:      eventType,
+		Container: container,
+		Time:      now,
+	}
+
+	w.events = append(w.events, event)
+	if len(w.events) > inventoryEventHistory {
+		w.events = w.events[len(w.events)-inventoryEventHistory:]
+	}
+
+	for ch := range w.subscribers {
+		select {
+		case ch <- event:
+		default:
+			// 너무 느린 구독자는 끊음 (커서로 재연결하면 놓친 이벤트를 받음)
+			delete(w.subscribers, ch)
+			close(ch)
+		}
+	}
+}
+
+// Subscribe - 이벤트 구독
+// cursor 이후 이벤트를 backlog 로 돌려주며, 이미 버려진 커서이거나 커서가 없으면
+// resync=true 와 함께 현재 전체 목록(snapshot)을 돌려줌
+func (w *InventoryWatcher) Subscribe(cursor uint64, hasCursor bool) (backlog []InventoryEvent, snapshot []ContainerInfo, resync bool, ch chan InventoryEvent, lastID uint64) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	oldest := w.lastID + 1
+	if len(w.events) > 0 {
+		oldest = w.events[0].ID
+	}
+
+	if hasCursor && cursor <= w.lastID && cursor+1 >= oldest {
+		for _, event := range w.events {
+			if event.ID > cursor {
+				backlog = append(backlog, event)
+			}
+		}
+	} else {
+		resync = true
+		snapshot = make([]ContainerInfo, 0, len(w.snapshot))
+		for _, c := range w.snapshot {
+			snapshot = append(snapshot, c)
+		}
+		sort.Slice(snapshot, func(i, j int) bool { return snapshot[i].ID < snapshot[j].ID })
+	}
+
+	ch = make(chan InventoryEvent, inventorySubscriberBuf)
+	w.subscribers[ch] = struct{}{}
+	return backlog, snapshot, resync, ch, w.lastID
+}
+
+// Unsubscribe - 구독 해제
+func (w *InventoryWatcher) Unsubscribe(ch chan InventoryEvent) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if _, exists := w.subscribers[ch]; exists {
+		delete(w.subscribers, ch)
+		close(ch)
+	}
+}
+
+// HTTP 핸들러: 컨테이너 변경 이벤트 스트림 (Server-Sent Events)
+//
+// 재연결 시 브라우저 EventSource 가 보내는 Last-Event-ID 헤더 또는
+// ?cursor= 쿼리로 마지막으로 받은 이벤트 ID를 전달하면 그 이후 이벤트부터 전송
+func (h *TeleportHandler) HandleContainerEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	cursorValue := r.Header.Get("Last-Event-ID")
+	if cursorValue == "" {
+		cursorValue = r.URL.Query().Get("cursor")
+	}
+	var cursor uint64
+	hasCursor := false
+	if cursorValue != "" {
+		parsed, err := strconv.ParseUint(cursorValue, 10, 64)
+		if err != nil {
+			http.Error(w, "Invalid cursor", http.StatusBadRequest)
+			return
+		}
+		cursor, hasCursor = parsed, true
+	}
+
+	backlog, snapshot, resync, events, lastID := h.watcher.Subscribe(cursor, hasCursor)
+	defer h.watcher.Unsubscribe(events)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no") // nginx 버퍼링 방지
+	w.WriteHeader(http.StatusOK)
+
+	fmt.Fprint(w, "retry: 3000\n\n")
+
+	// 전체 목록 재동기화 (커서가 없거나 너무 오래된 경우)
+	if resync {
+		if err := writeSSE(w, lastID, "snapshot", map[string]interface{}{
+			"containers": snapshot,
+			"total":      len(snapshot),
+			"cursor":     lastID,
+		}); err != nil {
+			return
+		}
+	}
+	for _, event := range backlog {
+		if err := writeSSE(w, event.ID, event.Type, event); err != nil {
+			return
+		}
+	}
+	flusher.Flush()
+
+	log.Printf("인벤토리 이벤트 구독 시작 (커서: %d, 재동기화: %v)", lastID, resync)
+
+	heartbeat := time.NewTicker(inventoryEventHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			log.Println("인벤토리 이벤트 구독 종료")
+			return
+		case event, ok := <-events:
+			if !ok {
+				// 버퍼가 가득 차서 끊김 → 클라이언트가 커서로 재연결
+				return
+			}
+			if event.ID <= lastID {
+				continue // backlog 로 이미 보낸 이벤트
+			}
+			if err := writeSSE(w, event.ID, event.Type, event); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// writeSSE - SSE 이벤트 하나 기록
+func writeSSE(w http.ResponseWriter, id uint64, event string, data interface{}) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", id, event, payload)
+	return err
+}
@@ -0,0 +1,272 @@
+package handlers
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"gopkg.in/yaml.v2"
+)
+
+// kubeClient - Kubernetes API 서버 클라이언트 (kubeconfig 기반)
+type kubeClient struct {
+	server     string // API 서버 주소 (https://host:6443)
+	namespace  string // 컨텍스트 기본 네임스페이스
+	token      string // Bearer 토큰 (없으면 클라이언트 인증서 사용)
+	username   string // Basic 인증 (token 이 없을 때)
+	password   string
+	tlsConfig  *tls.Config
+	httpClient *http.Client
+}
+
+// kubeconfig - kubeconfig 파일 중 필요한 부분만
+type kubeconfig struct {
+	CurrentContext string `yaml:"current-context"`
+	Clusters       []struct {
+		Name    string `yaml:"name"`
+		Cluster struct {
+			Server                   string `yaml:"server"`
+			CertificateAuthority     string `yaml:"certificate-authority"`
+			CertificateAuthorityData string `yaml:"certificate-authority-data"`
+			InsecureSkipTLSVerify    bool   `yaml:"insecure-skip-tls-verify"`
+		} `yaml:"cluster"`
+	} `yaml:"clusters"`
+	Contexts []struct {
+		Name    string `yaml:"name"`
+		Context struct {
+			Cluster   string `yaml:"cluster"`
+			User      string `yaml:"user"`
+			Namespace string `yaml:"namespace"`
+		} `yaml:"context"`
+	} `yaml:"contexts"`
+	Users []struct {
+		Name string `yaml:"name"`
+		User struct {
+			Token                 string `yaml:"token"`
+			TokenFile             string `yaml:"tokenFile"`
+			ClientCertificate     string `yaml:"client-certificate"`
+			ClientCertificateData string `yaml:"client-certificate-data"`
+			ClientKey             string `yaml:"client-key"`
+			ClientKeyData         string `yaml:"client-key-data"`
+			Username              string `yaml:"username"`
+			Password              string `yaml:"password"`
+			// 플러그인 인증은 지원하지 않음 (설정되어 있으면 에러)
+			Exec *struct {
+				Command string `yaml:"command"`
+			} `yaml:"exec"`
+			AuthProvider *struct {
+				Name string `yaml:"name"`
+			} `yaml:"auth-provider"`
+		} `yaml:"user"`
+	} `yaml:"users"`
+}
+
+// newKubeClientFromKubeconfig - kubeconfig 파일로 클라이언트 생성
+// contextName 이 비어 있으면 current-context 사용
+func newKubeClientFromKubeconfig(path, contextName string) (*kubeClient, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("kubeconfig 읽기 실패: %v", err)
+	}
+
+	var cfg kubeconfig
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("kubeconfig 파싱 실패: %v", err)
+	}
+
+	if contextName == "" {
+		contextName = cfg.CurrentContext
+	}
+
+	// 컨텍스트 → 클러스터/사용자 찾기
+	var clusterName, userName, namespace string
+	found := false
+	for _, c := range cfg.Contexts {
+		if c.Name == contextName {
+			clusterName, userName, namespace = c.Context.Cluster, c.Context.User, c.Context.Namespace
+			found = true
+			break
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("kubeconfig 컨텍스트를 찾을 수 없음: %q", contextName)
+	}
+
+	// 상대 경로는 kubeconfig 파일 기준
+	baseDir := filepath.Dir(path)
+	readFile := func(name string) ([]byte, error) {
+		if !filepath.IsAbs(name) {
+			name = filepath.Join(baseDir, name)
+		}
+		return os.ReadFile(name)
+	}
+
+	client := &kubeClient{
+		namespace: namespace,
+		tlsConfig: &tls.Config{MinVersion: tls.VersionTLS12},
+	}
+
+	for _, c := range cfg.Clusters {
+		if c.Name != clusterName {
+			continue
+		}
+		client.server = strings.TrimSuffix(c.Cluster.Server, "/")
+		client.tlsConfig.InsecureSkipVerify = c.Cluster.InsecureSkipTLSVerify
+
+		caData, err := decodeOrReadFile(c.Cluster.CertificateAuthorityData, c.Cluster.CertificateAuthority, readFile)
+		if err != nil {
+			return nil, fmt.Errorf("클러스터 CA 로드 실패: %v", err)
+		}
+		if caData != nil {
+			pool := x509.NewCertPool()
+			if !pool.AppendCertsFromPEM(caData) {
+				return nil, fmt.Errorf("클러스터 CA 인증서 파싱 실패")
+			}
+			client.tlsConfig.RootCAs = pool
+		}
+	}
+	if client.server == "" {
+		return nil, fmt.Errorf("kubeconfig 클러스터를 찾을 수 없음: %q", clusterName)
+	}
+
+	for _, u := range cfg.Users {
+		if u.Name != userName {
+			continue
+		}
+		// exec / auth-provider 로 받는 자격 증명은 여기서 만들 수 없으므로 인증 없이 요청하지 않도록 거부
+		switch {
+		case u.User.Exec != nil:
+			return nil, fmt.Errorf("kubeconfig 사용자 %q 의 exec 인증(%s)은 지원하지 않습니다 (token, tokenFile 또는 클라이언트 인증서 사용)", userName, u.User.Exec.Command)
+		case u.User.AuthProvider != nil:
+			return nil, fmt.Errorf("kubeconfig 사용자 %q 의 auth-provider 인증(%s)은 지원하지 않습니다 (token, tokenFile 또는 클라이언트 인증서 사용)", userName, u.User.AuthProvider.Name)
+		}
+		client.token = u.User.Token
+		if client.token == "" && u.User.TokenFile != "" {
+			token, err := readFile(u.User.TokenFile)
+			if err != nil {
+				return nil, fmt.Errorf("토큰 파일 읽기 실패: %v", err)
+			}
+			client.token = strings.TrimSpace(string(token))
+		}
+		client.username, client.password = u.User.Username, u.User.Password
+
+		certData, err := decodeOrReadFile(u.User.ClientCertificateData, u.User.ClientCertificate, readFile)
+		if err != nil {
+			return nil, fmt.Errorf("클라이언트 인증서 로드 실패: %v", err)
+		}
+		keyData, err := decodeOrReadFile(u.User.ClientKeyData, u.User.ClientKey, readFile)
+		if err != nil {
+			return nil, fmt.Errorf("클라이언트 키 로드 실패: %v", err)
+		}
+		if certData != nil && keyData != nil {
+			cert, err := tls.X509KeyPair(certData, keyData)
+			if err != nil {
+				return nil, fmt.Errorf("클라이언트 인증서 파싱 실패: %v", err)
+			}
+			client.tlsConfig.Certificates = []tls.Certificate{cert}
+		}
+	}
+
+	client.httpClient = &http.Client{
+		Transport: &http.Transport{TLSClientConfig: client.tlsConfig},
+		Timeout:   15 * time.Second,
+	}
+	return client, nil
+}
+
+// decodeOrReadFile - kubeconfig 의 *-data(base64) 값 또는 파일 경로 값 로드
+func decodeOrReadFile(data, file string, readFile func(string) ([]byte, error)) ([]byte, error) {
+	if data != "" {
+		return base64.StdEncoding.DecodeString(data)
+	}
+	if file != "" {
+		return readFile(file)
+	}
+	return nil, nil
+}
+
+// authorize - 요청에 인증 헤더 추가
+func (c *kubeClient) authorize(header http.Header) {
+	if c.token != "" {
+		header.Set("Authorization", "Bearer "+c.token)
+	} else if c.username != "" {
+		header.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(c.username+":"+c.password)))
+	}
+}
+
+// get - GET 요청 후 JSON 응답 디코딩
+func (c *kubeClient) get(ctx context.Context, path string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.server+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	c.authorize(req.Header)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("Kubernetes API 요청 실패 (%s): %v", path, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return kubeError(resp)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// dialWebSocket - 스트리밍 API(exec, attach 등)에 WebSocket 으로 연결
+func (c *kubeClient) dialWebSocket(ctx context.Context, path string, query url.Values, subprotocols []string) (*websocket.Conn, error) {
+	endpoint, err := url.Parse(c.server + path)
+	if err != nil {
+		return nil, err
+	}
+	endpoint.RawQuery = query.Encode()
+	switch endpoint.Scheme {
+	case "https":
+		endpoint.Scheme = "wss"
+	case "http":
+		endpoint.Scheme = "ws"
+	}
+
+	dialer := websocket.Dialer{
+		TLSClientConfig:  c.tlsConfig,
+		Subprotocols:     subprotocols,
+		HandshakeTimeout: 15 * time.Second,
+	}
+	header := http.Header{}
+	c.authorize(header)
+
+	conn, resp, err := dialer.DialContext(ctx, endpoint.String(), header)
+	if err != nil {
+		if resp != nil && resp.StatusCode != http.StatusSwitchingProtocols {
+			defer resp.Body.Close()
+			return nil, kubeError(resp)
+		}
+		return nil, fmt.Errorf("Kubernetes API 연결 실패 (%s): %v", path, err)
+	}
+	return conn, nil
+}
+
+// kubeError - Kubernetes Status 에러 응답 변환
+func kubeError(resp *http.Response) error {
+	var status struct {
+		Message string `json:"message"`
+	}
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if err := json.Unmarshal(data, &status); err != nil || status.Message == "" {
+		status.Message = string(data)
+	}
+	return fmt.Errorf("Kubernetes API 오류 (HTTP %d): %s", resp.StatusCode, status.Message)
+}
@@ -0,0 +1,371 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// KubernetesSource - Kubernetes 파드(컨테이너) 목록 제공자
+type KubernetesSource struct {
+	kubeconfig string // kubeconfig 파일 경로
+	context    string // 비어 있으면 current-context
+	namespace  string // 비어 있으면 전체 네임스페이스
+
+	mu     sync.Mutex
+	client *kubeClient // 만들지 못했으면 nil (다음 조회/접속 때 다시 생성)
+}
+
+// KubernetesTarget - Kubernetes 컨테이너 식별 정보
+type KubernetesTarget struct {
+	Namespace string `json:"namespace"`
+	Pod       string `json:"pod"`
+	Container string `json:"container"`
+}
+
+// NewKubernetesSource - kubeconfig 로 Kubernetes 소스 생성
+// namespace 가 비어 있으면 모든 네임스페이스의 파드 조회
+// 클라이언트 생성에 실패해도 소스를 만들고, 이후 목록 조회 때마다 kubeconfig 를 다시 읽어 생성 시도
+func NewKubernetesSource(kubeconfigPath, contextName, namespace string) *KubernetesSource {
+	k := &KubernetesSource{kubeconfig: kubeconfigPath, context: contextName, namespace: namespace}
+	k.kubeClient() // 실패하면 kubeClient 에서 기록
+	return k
+}
+
+// kubeClient - API 서버 클라이언트 (아직 만들지 못했으면 다시 생성)
+func (k *KubernetesSource) kubeClient() (*kubeClient, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if k.client != nil {
+		return k.client, nil
+	}
+
+	client, err := newKubeClientFromKubeconfig(k.kubeconfig, k.context)
+	if err != nil {
+		log.Printf("⚠️ Kubernetes 클라이언트 생성 실패 (다음 조회 때 다시 시도): %v", err)
+		return nil, fmt.Errorf("Kubernetes 클라이언트 생성 실패: %v", err)
+	}
+	log.Printf("Kubernetes API 서버: %s", client.server)
+	k.client = client
+	return client, nil
+}
+
+// kubePod - Pod 리소스 중 필요한 부분만
+type kubePod struct {
+	Metadata struct {
+		Name              string            `json:"name"`
+		Namespace         string            `json:"namespace"`
+		Labels            map[string]string `json:"labels"`
+		CreationTimestamp string            `json:"creationTimestamp"`
+	} `json:"metadata"`
+	Spec struct {
+		NodeName   string `json:"nodeName"`
+		Containers []struct {
+			Name  string `json:"name"`
+			Image string `json:"image"`
+			Ports []struct {
+				ContainerPort int    `json:"containerPort"`
+				Protocol      string `json:"protocol"`
+			} `json:"ports"`
+		} `json:"containers"`
+	} `json:"spec"`
+	Status struct {
+		Phase             string                `json:"phase"`
+		ContainerStatuses []kubeContainerStatus `json:"containerStatuses"`
+	} `json:"status"`
+}
+
+type kubeContainerStatus struct {
+	Name         string `json:"name"`
+	RestartCount int    `json:"restartCount"`
+	State        struct {
+		Running *struct {
+			StartedAt string `json:"startedAt"`
+		} `json:"running"`
+		Waiting *struct {
+			Reason string `json:"reason"`
+		} `json:"waiting"`
+		Terminated *struct {
+			ExitCode int    `json:"exitCode"`
+			Reason   string `json:"reason"`
+		} `json:"terminated"`
+	} `json:"state"`
+}
+
+// Name - 소스 이름
+func (k *KubernetesSource) Name() string {
+	return "kubernetes"
+}
+
+// ListContainers - 파드의 컨테이너마다 ContainerInfo 하나씩 반환
+func (k *KubernetesSource) ListContainers(ctx context.Context) ([]ContainerInfo, error) {
+	path := "/api/v1/pods"
+	if k.namespace != "" {
+		path = fmt.Sprintf("/api/v1/namespaces/%s/pods", url.PathEscape(k.namespace))
+	}
+
+	client, err := k.kubeClient()
+	if err != nil {
+		return nil, err
+	}
+	var podList struct {
+		Items []kubePod `json:"items"`
+	}
+	if err := client.get(ctx, path, &podList); err != nil {
+		return nil, err
+	}
+
+	var containers []ContainerInfo
+	for _, pod := range podList.Items {
+		containers = append(containers, podToContainerInfos(pod)...)
+	}
+	return containers, nil
+}
+
+// kubeContainerID - 네임스페이스/파드/컨테이너로 만든 ID
+// (쿠버네티스 이름에는 ':' 가 들어갈 수 없으므로 구분자로 사용)
+func kubeContainerID(namespace, pod, container string) string {
+	return strings.Join([]string{"k8s", namespace, pod, container}, ":")
+}
+
+// parseKubeContainerID - kubeContainerID 로 만든 ID 를 네임스페이스/파드/컨테이너로 분리
+func parseKubeContainerID(id string) (*KubernetesTarget, bool) {
+	parts := strings.Split(id, ":")
+	if len(parts) != 4 || parts[0] != "k8s" || parts[1] == "" || parts[2] == "" || parts[3] == "" {
+		return nil, false
+	}
+	return &KubernetesTarget{Namespace: parts[1], Pod: parts[2], Container: parts[3]}, true
+}
+
+// podToContainerInfos - 파드를 컨테이너별 ContainerInfo로 변환
+func podToContainerInfos(pod kubePod) []ContainerInfo {
+	statuses := make(map[string]kubeContainerStatus)
+	for _, s := range pod.Status.ContainerStatuses {
+		statuses[s.Name] = s
+	}
+
+	containers := make([]ContainerInfo, 0, len(pod.Spec.Containers))
+	for _, c := range pod.Spec.Containers {
+		labels := make(map[string]string, len(pod.Metadata.Labels))
+		for key, value := range pod.Metadata.Labels {
+			labels[key] = value
+		}
+
+		var ports []string
+		for _, p := range c.Ports {
+			ports = append(ports, fmt.Sprintf("%d/%s", p.ContainerPort, strings.ToLower(p.Protocol)))
+		}
+
+		name := pod.Metadata.Name
+		if len(pod.Spec.Containers) > 1 {
+			name = pod.Metadata.Name + "/" + c.Name
+		}
+
+		status, hasStatus := statuses[c.Name]
+		containers = append(containers, ContainerInfo{
+			ID:      kubeContainerID(pod.Metadata.Namespace, pod.Metadata.Name, c.Name),
+			Name:    name,
+			Status:  kubeStatus(pod.Status.Phase, status, hasStatus),
+			Labels:  labels,
+			Source:  "kubernetes",
+			Image:   c.Image,
+			Created: pod.Metadata.CreationTimestamp,
+			Ports:   ports,
+			Kubernetes: &KubernetesTarget{
+				Namespace: pod.Metadata.Namespace,
+				Pod:       pod.Metadata.Name,
+				Container: c.Name,
+			},
+		})
+	}
+	return containers
+}
+
+// kubeStatus - 파드 phase / 컨테이너 상태를 프론트엔드 ContainerStatus로 변환
+func kubeStatus(phase string, status kubeContainerStatus, hasStatus bool) string {
+	if phase == "Failed" {
+		return "error"
+	}
+	if !hasStatus {
+		return "pending"
+	}
+
+	switch {
+	case status.State.Running != nil:
+		return "running"
+	case status.State.Waiting != nil:
+		if strings.HasSuffix(status.State.Waiting.Reason, "BackOff") || strings.HasPrefix(status.State.Waiting.Reason, "Err") {
+			return "error"
+		}
+		return "pending"
+	case status.State.Terminated != nil:
+		if status.State.Terminated.ExitCode != 0 {
+			return "error"
+		}
+		return "stopped"
+	default:
+		return "unknown"
+	}
+}
+
+// kubePodDetail - 단건 Pod 조회 응답 중 상세 정보에 필요한 부분만
+type kubePodDetail struct {
+	Spec struct {
+		Containers []struct {
+			Name    string   `json:"name"`
+			Command []string `json:"command"`
+			Args    []string `json:"args"`
+			Env     []struct {
+				Name      string `json:"name"`
+				Value     string `json:"value"`
+				ValueFrom *struct {
+					SecretKeyRef *struct {
+						Name string `json:"name"`
+						Key  string `json:"key"`
+					} `json:"secretKeyRef"`
+					ConfigMapKeyRef *struct {
+						Name string `json:"name"`
+						Key  string `json:"key"`
+					} `json:"configMapKeyRef"`
+					FieldRef *struct {
+						FieldPath string `json:"fieldPath"`
+					} `json:"fieldRef"`
+					ResourceFieldRef *struct {
+						Resource string `json:"resource"`
+					} `json:"resourceFieldRef"`
+				} `json:"valueFrom"`
+			} `json:"env"`
+			Resources struct {
+				Limits   map[string]string `json:"limits"`
+				Requests map[string]string `json:"requests"`
+			} `json:"resources"`
+			VolumeMounts []struct {
+				Name      string `json:"name"`
+				MountPath string `json:"mountPath"`
+				SubPath   string `json:"subPath"`
+				ReadOnly  bool   `json:"readOnly"`
+			} `json:"volumeMounts"`
+		} `json:"containers"`
+		Volumes []map[string]json.RawMessage `json:"volumes"`
+	} `json:"spec"`
+	Status struct {
+		ContainerStatuses []kubeContainerStatus `json:"containerStatuses"`
+	} `json:"status"`
+}
+
+// ContainerDetail - Pod 조회로 컨테이너 런타임 상세 정보 조회
+func (k *KubernetesSource) ContainerDetail(ctx context.Context, c *ContainerInfo) (*ContainerDetail, error) {
+	if c.Kubernetes == nil {
+		return nil, fmt.Errorf("Kubernetes 대상 정보가 없음: %s", c.ID)
+	}
+	target := c.Kubernetes
+
+	client, err := k.kubeClient()
+	if err != nil {
+		return nil, err
+	}
+	var pod kubePodDetail
+	path := fmt.Sprintf("/api/v1/namespaces/%s/pods/%s", url.PathEscape(target.Namespace), url.PathEscape(target.Pod))
+	if err := client.get(ctx, path, &pod); err != nil {
+		return nil, err
+	}
+
+	detail := &ContainerDetail{ContainerInfo: *c}
+	found := false
+	for _, spec := range pod.Spec.Containers {
+		if spec.Name != target.Container {
+			continue
+		}
+		found = true
+
+		detail.Command = redactCommand(append(append([]string{}, spec.Command...), spec.Args...))
+		detail.Resources = kubeResources(spec.Resources.Limits, spec.Resources.Requests)
+
+		for _, e := range spec.Env {
+			env := EnvVar{Name: e.Name, Value: e.Value}
+			if from := e.ValueFrom; from != nil {
+				switch {
+				case from.SecretKeyRef != nil:
+					// Secret 값은 API 로 읽지 않고 출처만 표시
+					env.From = "secret:" + from.SecretKeyRef.Name + "/" + from.SecretKeyRef.Key
+				case from.ConfigMapKeyRef != nil:
+					env.From = "configmap:" + from.ConfigMapKeyRef.Name + "/" + from.ConfigMapKeyRef.Key
+				case from.FieldRef != nil:
+					env.From = "field:" + from.FieldRef.FieldPath
+				case from.ResourceFieldRef != nil:
+					env.From = "resource:" + from.ResourceFieldRef.Resource
+				}
+			}
+			detail.Env = append(detail.Env, redactEnv(env))
+		}
+
+		volumeTypes := kubeVolumeTypes(pod.Spec.Volumes)
+		for _, m := range spec.VolumeMounts {
+			source := m.Name
+			if m.SubPath != "" {
+				source += "/" + m.SubPath
+			}
+			detail.Mounts = append(detail.Mounts, Mount{
+				Type:        volumeTypes[m.Name],
+				Source:      source,
+				Destination: m.MountPath,
+				ReadOnly:    m.ReadOnly,
+			})
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("파드 %s/%s 에 컨테이너 %s 가 없음", target.Namespace, target.Pod, target.Container)
+	}
+
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.Name != target.Container {
+			continue
+		}
+		detail.RestartCount = status.RestartCount
+		switch state := status.State; {
+		case state.Running != nil:
+			detail.State = "running"
+			detail.StartedAt = state.Running.StartedAt
+		case state.Waiting != nil:
+			detail.State = state.Waiting.Reason
+		case state.Terminated != nil:
+			detail.State = fmt.Sprintf("terminated (%d, %s)", state.Terminated.ExitCode, state.Terminated.Reason)
+		}
+	}
+	return detail, nil
+}
+
+// kubeResources - limits/requests 변환 (둘 다 없으면 nil)
+func kubeResources(limits, requests map[string]string) *ResourceLimits {
+	if len(limits) == 0 && len(requests) == 0 {
+		return nil
+	}
+	return &ResourceLimits{
+		CPULimit:      limits["cpu"],
+		CPURequest:    requests["cpu"],
+		MemoryLimit:   limits["memory"],
+		MemoryRequest: requests["memory"],
+	}
+}
+
+// kubeVolumeTypes - 볼륨 이름 → 볼륨 종류 (예: configMap, secret, persistentVolumeClaim)
+func kubeVolumeTypes(volumes []map[string]json.RawMessage) map[string]string {
+	types := make(map[string]string, len(volumes))
+	for _, volume := range volumes {
+		var name string
+		if err := json.Unmarshal(volume["name"], &name); err != nil {
+			continue
+		}
+		for key := range volume {
+			if key != "name" {
+				types[name] = key
+				break
+			}
+		}
+	}
+	return types
+}
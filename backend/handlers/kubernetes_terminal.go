@@ -0,0 +1,192 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/url"
+	"strconv"
+	"sync"
+
+	"github.com/gorilla/websocket"
+)
+
+// Kubernetes 스트리밍 채널 번호 (v4/v5.channel.k8s.io)
+const (
+	kubeStreamStdin  = 0
+	kubeStreamStdout = 1
+	kubeStreamStderr = 2
+	kubeStreamError  = 3 // 종료 상태 (metav1.Status JSON)
+	kubeStreamResize = 4 // 터미널 크기 ({"Width","Height"})
+)
+
+// 지원하는 exec WebSocket 서브프로토콜 (앞쪽 우선)
+var kubeExecSubprotocols = []string{"v5.channel.k8s.io", "v4.channel.k8s.io"}
+
+// KubernetesExecTarget - pods/exec 대상
+type KubernetesExecTarget struct {
+	Namespace string
+	Pod       string
+	Container string
+	Command   []string
+}
+
+// KubernetesExecBackend - pods/exec TTY 세션
+type KubernetesExecBackend struct {
+	source   *KubernetesSource
+	target   KubernetesExecTarget
+	stream   *websocket.Conn // API 서버 exec 스트림
+	streamMu sync.Mutex      // exec 스트림 동시 쓰기 방지 (stdin / resize)
+	pending  []byte          // 아직 Read 로 넘기지 않은 stdout/stderr
+
+	exitCode int   // 에러 채널로 받은 종료 코드 (받기 전에는 -1)
+	exitErr  error // 종료 상태 파싱 오류 또는 exec 실패
+}
+
+// NewKubernetesExecBackend - pods/exec 터미널 백엔드 생성 (Start 에서 연결)
+func (k *KubernetesSource) NewKubernetesExecBackend(target KubernetesExecTarget) *KubernetesExecBackend {
+	return &KubernetesExecBackend{
+		source:   k,
+		target:   target,
+		exitCode: -1,
+	}
+}
+
+// Start - pods/exec 를 TTY 로 열고 초기 크기 전송
+func (b *KubernetesExecBackend) Start(ctx context.Context, cols, rows int) error {
+	query := url.Values{}
+	query.Set("container", b.target.Container)
+	query.Set("stdin", "true")
+	query.Set("stdout", "true")
+	query.Set("stderr", "true")
+	query.Set("tty", "true")
+	for _, arg := range b.target.Command {
+		query.Add("command", arg)
+	}
+
+	path := fmt.Sprintf("/api/v1/namespaces/%s/pods/%s/exec", url.PathEscape(b.target.Namespace), url.PathEscape(b.target.Pod))
+	client, err := b.source.kubeClient()
+	if err != nil {
+		return err
+	}
+	stream, err := client.dialWebSocket(ctx, path, query, kubeExecSubprotocols)
+	if err != nil {
+		return fmt.Errorf("kubernetes exec 연결 실패: %v", err)
+	}
+	b.stream = stream
+
+	log.Printf("kubernetes exec 시작: %s/%s/%s %v (프로토콜: %s)", b.target.Namespace, b.target.Pod, b.target.Container, b.target.Command, stream.Subprotocol())
+	return b.Resize(cols, rows)
+}
+
+// Read - stdout/stderr 채널 데이터 읽기
+// 에러 채널의 종료 상태는 Wait 에서 반환하고, 스트림이 닫히면 io.EOF
+func (b *KubernetesExecBackend) Read(p []byte) (int, error) {
+	for len(b.pending) == 0 {
+		_, data, err := b.stream.ReadMessage()
+		if err != nil {
+			if _, ok := err.(*websocket.CloseError); ok {
+				return 0, io.EOF
+			}
+			return 0, err
+		}
+		if len(data) == 0 {
+			continue
+		}
+
+		switch data[0] {
+		case kubeStreamStdout, kubeStreamStderr:
+			b.pending = data[1:]
+		case kubeStreamError:
+			b.exitCode, b.exitErr = kubeExecExitCode(data[1:])
+		}
+	}
+
+	n := copy(p, b.pending)
+	b.pending = b.pending[n:]
+	return n, nil
+}
+
+// Write - stdin 채널로 입력 전송
+func (b *KubernetesExecBackend) Write(p []byte) (int, error) {
+	if err := b.writeStream(kubeStreamStdin, p); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Resize - resize 채널로 터미널 크기 전송
+func (b *KubernetesExecBackend) Resize(cols, rows int) error {
+	data, _ := json.Marshal(map[string]int{"Width": cols, "Height": rows})
+	return b.writeStream(kubeStreamResize, data)
+}
+
+// Signal - exec 에는 시그널 채널이 없으므로 TTY 제어 문자로 전달
+func (b *KubernetesExecBackend) Signal(sig string) error {
+	return ttySignal(b, sig)
+}
+
+// Wait - 에러 채널로 받은 종료 코드 반환 (Read 가 io.EOF 를 반환한 뒤 호출)
+func (b *KubernetesExecBackend) Wait() (int, error) {
+	return b.exitCode, b.exitErr
+}
+
+// Close - exec 스트림 닫기
+func (b *KubernetesExecBackend) Close() error {
+	if b.stream != nil {
+		return b.stream.Close()
+	}
+	return nil
+}
+
+// Info - 터미널 정보
+func (b *KubernetesExecBackend) Info() map[string]interface{} {
+	return map[string]interface{}{
+		"backend":   MethodKubernetes,
+		"namespace": b.target.Namespace,
+		"pod":       b.target.Pod,
+		"container": b.target.Container,
+	}
+}
+
+// writeStream - 채널 번호를 붙여 exec 스트림으로 전송
+func (b *KubernetesExecBackend) writeStream(channel byte, data []byte) error {
+	b.streamMu.Lock()
+	defer b.streamMu.Unlock()
+	return b.stream.WriteMessage(websocket.BinaryMessage, append([]byte{channel}, data...))
+}
+
+// kubeExecExitCode - 에러 채널의 Status 를 종료 코드로 변환
+// 명령이 0 이 아닌 코드로 끝난 경우는 오류로 보지 않음
+func kubeExecExitCode(data []byte) (int, error) {
+	var status struct {
+		Status  string `json:"status"`
+		Message string `json:"message"`
+		Reason  string `json:"reason"`
+		Details struct {
+			Causes []struct {
+				Reason  string `json:"reason"`
+				Message string `json:"message"`
+			} `json:"causes"`
+		} `json:"details"`
+	}
+	if err := json.Unmarshal(data, &status); err != nil {
+		return -1, fmt.Errorf("exec 상태 파싱 실패: %v", err)
+	}
+
+	if status.Status == "Success" {
+		return 0, nil
+	}
+	if status.Reason == "NonZeroExitCode" {
+		for _, cause := range status.Details.Causes {
+			if cause.Reason == "ExitCode" {
+				if code, err := strconv.Atoi(cause.Message); err == nil {
+					return code, nil
+				}
+			}
+		}
+	}
+	return -1, fmt.Errorf("exec 실패: %s", status.Message)
+}
@@ -0,0 +1,434 @@
+package handlers
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gorilla/websocket"
+)
+
+const testKubeToken = "kube-test-token"
+
+// 테스트 파드 (prod/web-7d9f: 컨테이너 둘, jobs/batch-1: 실패, prod/queued: 아직 상태 없음)
+var testKubePods = []string{`{
+	"metadata": {"name": "web-7d9f", "namespace": "prod", "labels": {"app": "web"}, "creationTimestamp": "2024-05-01T09:00:00Z"},
+	"spec": {
+		"nodeName": "node-a",
+		"containers": [
+			{
+				"name": "app", "image": "nginx:1.27",
+				"command": ["nginx"], "args": ["-g", "daemon off;"],
+				"ports": [{"containerPort": 80, "protocol": "TCP"}],
+				"env": [
+					{"name": "LOG_LEVEL", "value": "debug"},
+					{"name": "API_TOKEN", "value": "s3cr3t"},
+					{"name": "DB_PASSWORD", "valueFrom": {"secretKeyRef": {"name": "db", "key": "password"}}},
+					{"name": "POD_IP", "valueFrom": {"fieldRef": {"fieldPath": "status.podIP"}}}
+				],
+				"resources": {"limits": {"cpu": "500m", "memory": "256Mi"}, "requests": {"cpu": "250m"}},
+				"volumeMounts": [
+					{"name": "config", "mountPath": "/etc/nginx", "readOnly": true},
+					{"name": "data", "mountPath": "/data", "subPath": "www"}
+				]
+			},
+			{"name": "sidecar", "image": "envoy:1.30"}
+		],
+		"volumes": [
+			{"name": "config", "configMap": {"name": "nginx"}},
+			{"name": "data", "persistentVolumeClaim": {"claimName": "web-data"}}
+		]
+	},
+	"status": {
+		"phase": "Running",
+		"containerStatuses": [
+			{"name": "app", "restartCount": 0, "state": {"running": {"startedAt": "2024-05-01T09:00:05Z"}}},
+			{"name": "sidecar", "restartCount": 5, "state": {"waiting": {"reason": "CrashLoopBackOff"}}}
+		]
+	}
+}`, `{
+	"metadata": {"name": "batch-1", "namespace": "jobs", "creationTimestamp": "2024-05-02T00:00:00Z"},
+	"spec": {"containers": [{"name": "job", "image": "busybox"}]},
+	"status": {"phase": "Failed"}
+}`, `{
+	"metadata": {"name": "queued", "namespace": "prod", "creationTimestamp": "2024-05-03T00:00:00Z"},
+	"spec": {"containers": [{"name": "worker", "image": "worker:2"}]},
+	"status": {"phase": "Pending"}
+}`}
+
+// fakeKube - httptest TLS 로 띄운 Kubernetes API 서버
+// Bearer 토큰을 확인하고, 파드 목록/단건 조회와 pods/exec (WebSocket, v4.channel.k8s.io) 를 처리
+// exec 는 stdin 을 stdout 으로 돌려주고, "exit <코드>" 입력을 받으면 에러 채널로 종료 상태를 보낸 뒤 종료
+type fakeKube struct {
+	server *httptest.Server
+
+	mu      sync.Mutex
+	paths   []string   // 요청 경로
+	exec    url.Values // 마지막 exec 요청 쿼리
+	resizes []string   // resize 채널로 받은 크기
+}
+
+func newFakeKube(t *testing.T) *fakeKube {
+	k := &fakeKube{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/pods", k.listPods)
+	mux.HandleFunc("GET /api/v1/namespaces/{namespace}/pods", k.listPods)
+	mux.HandleFunc("GET /api/v1/namespaces/{namespace}/pods/{pod}", k.getPod)
+	mux.HandleFunc("GET /api/v1/namespaces/{namespace}/pods/{pod}/exec", k.execPod)
+
+	k.server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+testKubeToken {
+			writeKubeStatus(w, http.StatusUnauthorized, "Unauthorized")
+			return
+		}
+		k.mu.Lock()
+		k.paths = append(k.paths, r.URL.Path)
+		k.mu.Unlock()
+		mux.ServeHTTP(w, r)
+	}))
+	t.Cleanup(k.server.Close)
+	return k
+}
+
+// kubeconfig - 이 서버를 가리키는 kubeconfig 파일 (prod: 토큰 파일, intruder: 틀린 토큰)
+func (k *fakeKube) kubeconfig(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: k.server.Certificate().Raw})
+	kubeconfig := fmt.Sprintf(`apiVersion: v1
+kind: Config
+current-context: prod
+clusters:
+- name: test
+  cluster:
+    server: %s/
+    certificate-authority-data: %s
+contexts:
+- name: prod
+  context: {cluster: test, user: deployer, namespace: prod}
+- name: intruder
+  context: {cluster: test, user: intruder}
+users:
+- name: deployer
+  user: {tokenFile: token}
+- name: intruder
+  user: {token: wrong-token}
+`, k.server.URL, base64.StdEncoding.EncodeToString(ca))
+
+	if err := os.WriteFile(filepath.Join(dir, "config"), []byte(kubeconfig), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	// 상대 경로 tokenFile 은 kubeconfig 기준
+	if err := os.WriteFile(filepath.Join(dir, "token"), []byte(testKubeToken+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return filepath.Join(dir, "config")
+}
+
+func (k *fakeKube) requested() []string {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return append([]string(nil), k.paths...)
+}
+
+func (k *fakeKube) listPods(w http.ResponseWriter, r *http.Request) {
+	namespace := r.PathValue("namespace")
+	var items []json.RawMessage
+	for _, pod := range testKubePods {
+		var meta kubePod
+		json.Unmarshal([]byte(pod), &meta)
+		if namespace == "" || meta.Metadata.Namespace == namespace {
+			items = append(items, json.RawMessage(pod))
+		}
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{"kind": "PodList", "items": items})
+}
+
+func (k *fakeKube) getPod(w http.ResponseWriter, r *http.Request) {
+	for _, pod := range testKubePods {
+		var meta kubePod
+		json.Unmarshal([]byte(pod), &meta)
+		if meta.Metadata.Namespace == r.PathValue("namespace") && meta.Metadata.Name == r.PathValue("pod") {
+			io.WriteString(w, pod)
+			return
+		}
+	}
+	writeKubeStatus(w, http.StatusNotFound, fmt.Sprintf("pods %q not found", r.PathValue("pod")))
+}
+
+func (k *fakeKube) execPod(w http.ResponseWriter, r *http.Request) {
+	upgrader := websocket.Upgrader{Subprotocols: []string{"v4.channel.k8s.io"}}
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	k.mu.Lock()
+	k.exec = r.URL.Query()
+	k.mu.Unlock()
+
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil || len(data) == 0 {
+			return
+		}
+		switch data[0] {
+		case kubeStreamResize:
+			k.mu.Lock()
+			k.resizes = append(k.resizes, string(data[1:]))
+			k.mu.Unlock()
+		case kubeStreamStdin:
+			input := string(data[1:])
+			if code, ok := strings.CutPrefix(strings.TrimSpace(input), "exit "); ok {
+				status := `{"status":"Success"}`
+				if code != "0" {
+					status = `{"status":"Failure","reason":"NonZeroExitCode","message":"command terminated with non-zero exit code","details":{"causes":[{"reason":"ExitCode","message":"` + code + `"}]}}`
+				}
+				conn.WriteMessage(websocket.BinaryMessage, append([]byte{kubeStreamError}, status...))
+				conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+				return
+			}
+			conn.WriteMessage(websocket.BinaryMessage, append([]byte{kubeStreamStdout}, input...))
+		}
+	}
+}
+
+func writeKubeStatus(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]interface{}{"kind": "Status", "status": "Failure", "message": message, "code": status})
+}
+
+func TestKubernetesSourceListContainers(t *testing.T) {
+	k := newFakeKube(t)
+	kubeconfig := k.kubeconfig(t)
+
+	source := NewKubernetesSource(kubeconfig, "", "")
+	containers, err := source.ListContainers(context.Background())
+	if err != nil {
+		t.Fatalf("ListContainers: %v", err)
+	}
+
+	type summary struct{ ID, Name, Status string }
+	var got []summary
+	for _, c := range containers {
+		got = append(got, summary{c.ID, c.Name, c.Status})
+	}
+	want := []summary{
+		{"k8s:prod:web-7d9f:app", "web-7d9f/app", "running"},
+		{"k8s:prod:web-7d9f:sidecar", "web-7d9f/sidecar", "error"},
+		{"k8s:jobs:batch-1:job", "batch-1", "error"},
+		{"k8s:prod:queued:worker", "queued", "pending"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ListContainers = %+v, want %+v", got, want)
+	}
+	app := containers[0]
+	if app.Source != "kubernetes" || app.Image != "nginx:1.27" || app.Created != "2024-05-01T09:00:00Z" ||
+		!reflect.DeepEqual(app.Ports, []string{"80/tcp"}) || app.Labels["app"] != "web" ||
+		*app.Kubernetes != (KubernetesTarget{Namespace: "prod", Pod: "web-7d9f", Container: "app"}) {
+		t.Errorf("app 컨테이너 = %+v", app)
+	}
+
+	// 네임스페이스를 지정하면 그 네임스페이스만 조회
+	source = NewKubernetesSource(kubeconfig, "", "jobs")
+	if containers, err := source.ListContainers(context.Background()); err != nil || len(containers) != 1 || containers[0].ID != "k8s:jobs:batch-1:job" {
+		t.Errorf("jobs 네임스페이스 목록 = %+v, %v", containers, err)
+	}
+	if paths := k.requested(); !reflect.DeepEqual(paths, []string{"/api/v1/pods", "/api/v1/namespaces/jobs/pods"}) {
+		t.Errorf("요청 경로 = %q", paths)
+	}
+
+	// 인증 실패는 Status 메시지를 포함한 에러
+	source = NewKubernetesSource(kubeconfig, "intruder", "")
+	if _, err := source.ListContainers(context.Background()); err == nil || !strings.Contains(err.Error(), "HTTP 401") || !strings.Contains(err.Error(), "Unauthorized") {
+		t.Errorf("틀린 토큰 에러 = %v", err)
+	}
+
+	if _, err := NewKubernetesSource(kubeconfig, "missing", "").ListContainers(context.Background()); err == nil {
+		t.Error("없는 컨텍스트로 목록이 조회됨")
+	}
+}
+
+func TestKubernetesSourceRetriesClient(t *testing.T) {
+	k := newFakeKube(t)
+	kubeconfig := k.kubeconfig(t)
+	valid, err := os.ReadFile(kubeconfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	// exec / auth-provider 사용자는 인증 없이 요청하지 않고 에러
+	plugins := map[string]string{
+		"exec":          "user: {exec: {apiVersion: client.authentication.k8s.io/v1, command: aws}}",
+		"auth-provider": "user: {auth-provider: {name: oidc}}",
+	}
+	for name, user := range plugins {
+		data := strings.Replace(string(valid), "user: {tokenFile: token}", user, 1)
+		if err := os.WriteFile(kubeconfig, []byte(data), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := NewKubernetesSource(kubeconfig, "", "").ListContainers(ctx); err == nil || !strings.Contains(err.Error(), name) {
+			t.Errorf("%s 사용자 에러 = %v", name, err)
+		}
+	}
+	if paths := k.requested(); len(paths) != 0 {
+		t.Errorf("플러그인 인증 사용자로 요청함: %q", paths)
+	}
+
+	// kubeconfig 를 고치면 다음 조회 때 클라이언트를 다시 만듦
+	source := NewKubernetesSource(kubeconfig, "", "")
+	if _, err := source.ListContainers(ctx); err == nil {
+		t.Fatal("잘못된 kubeconfig 로 목록이 조회됨")
+	}
+	if err := os.WriteFile(kubeconfig, valid, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if containers, err := source.ListContainers(ctx); err != nil || len(containers) != 4 {
+		t.Errorf("kubeconfig 수정 후 목록 = %d 개, %v", len(containers), err)
+	}
+}
+
+func TestKubernetesSourceContainerDetail(t *testing.T) {
+	k := newFakeKube(t)
+	source := NewKubernetesSource(k.kubeconfig(t), "", "")
+	ctx := context.Background()
+	target := func(container string) *ContainerInfo {
+		return &ContainerInfo{
+			ID:         kubeContainerID("prod", "web-7d9f", container),
+			Kubernetes: &KubernetesTarget{Namespace: "prod", Pod: "web-7d9f", Container: container},
+		}
+	}
+
+	detail, err := source.ContainerDetail(ctx, target("app"))
+	if err != nil {
+		t.Fatalf("ContainerDetail: %v", err)
+	}
+	if want := []string{"nginx", "-g", "daemon off;"}; !reflect.DeepEqual(detail.Command, want) {
+		t.Errorf("Command = %q", detail.Command)
+	}
+	if detail.State != "running" || detail.StartedAt != "2024-05-01T09:00:05Z" {
+		t.Errorf("State = %q, StartedAt = %q", detail.State, detail.StartedAt)
+	}
+	if want := (ResourceLimits{CPULimit: "500m", CPURequest: "250m", MemoryLimit: "256Mi"}); detail.Resources == nil || *detail.Resources != want {
+		t.Errorf("Resources = %+v", detail.Resources)
+	}
+	wantEnv := []EnvVar{
+		{Name: "LOG_LEVEL", Value: "debug"},
+		{Name: "API_TOKEN", Value: redactedValue, Redacted: true},
+		{Name: "DB_PASSWORD", From: "secret:db/password"},
+		{Name: "POD_IP", From: "field:status.podIP"},
+	}
+	if !reflect.DeepEqual(detail.Env, wantEnv) {
+		t.Errorf("Env = %+v", detail.Env)
+	}
+	wantMounts := []Mount{
+		{Type: "configMap", Source: "config", Destination: "/etc/nginx", ReadOnly: true},
+		{Type: "persistentVolumeClaim", Source: "data/www", Destination: "/data"},
+	}
+	if !reflect.DeepEqual(detail.Mounts, wantMounts) {
+		t.Errorf("Mounts = %+v", detail.Mounts)
+	}
+
+	detail, err = source.ContainerDetail(ctx, target("sidecar"))
+	if err != nil || detail.State != "CrashLoopBackOff" || detail.RestartCount != 5 || detail.Resources != nil {
+		t.Errorf("sidecar 상세 = %+v, %v", detail, err)
+	}
+	if _, err := source.ContainerDetail(ctx, target("missing")); err == nil {
+		t.Error("없는 컨테이너의 상세 정보가 반환됨")
+	}
+	missingPod := &ContainerInfo{Kubernetes: &KubernetesTarget{Namespace: "prod", Pod: "gone", Container: "app"}}
+	if _, err := source.ContainerDetail(ctx, missingPod); err == nil || !strings.Contains(err.Error(), "HTTP 404") {
+		t.Errorf("없는 파드 에러 = %v", err)
+	}
+}
+
+func TestKubernetesExecBackend(t *testing.T) {
+	k := newFakeKube(t)
+	source := NewKubernetesSource(k.kubeconfig(t), "", "")
+
+	for _, tc := range []struct {
+		exit string
+		code int
+	}{
+		{"exit 0\n", 0},
+		{"exit 2\n", 2},
+	} {
+		b := source.NewKubernetesExecBackend(KubernetesExecTarget{Namespace: "prod", Pod: "web-7d9f", Container: "app", Command: []string{"/bin/sh", "-c", "exec bash"}})
+		if err := b.Start(context.Background(), 120, 40); err != nil {
+			t.Fatalf("Start: %v", err)
+		}
+		if protocol := b.stream.Subprotocol(); protocol != "v4.channel.k8s.io" {
+			t.Errorf("서브프로토콜 = %q", protocol)
+		}
+
+		if _, err := b.Write([]byte("ls\n")); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+		readUntil(t, b, "ls\n")
+		if err := b.Resize(100, 30); err != nil {
+			t.Fatalf("Resize: %v", err)
+		}
+		if err := b.Signal("INT"); err != nil {
+			t.Fatalf("Signal: %v", err)
+		}
+		readUntil(t, b, "\x03")
+
+		if _, err := b.Write([]byte(tc.exit)); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+		if _, err := io.Copy(io.Discard, b); err != nil {
+			t.Fatalf("종료 후 읽기: %v", err)
+		}
+		if code, err := b.Wait(); err != nil || code != tc.code {
+			t.Errorf("%q: Wait = %d, %v", tc.exit, code, err)
+		}
+		b.Close()
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	want := url.Values{
+		"container": {"app"}, "command": {"/bin/sh", "-c", "exec bash"},
+		"stdin": {"true"}, "stdout": {"true"}, "stderr": {"true"}, "tty": {"true"},
+	}
+	if !reflect.DeepEqual(k.exec, want) {
+		t.Errorf("exec 쿼리 = %v", k.exec)
+	}
+	// 세션마다 시작할 때 초기 크기, 이후 Resize
+	if want := strings.Repeat(`{"Height":40,"Width":120},{"Height":30,"Width":100},`, 2); strings.Join(k.resizes, ",")+"," != want {
+		t.Errorf("크기 조정 = %q", k.resizes)
+	}
+}
+
+func TestKubeExecExitCode(t *testing.T) {
+	for _, tc := range []struct {
+		status  string
+		code    int
+		wantErr bool
+	}{
+		{`{"status":"Success"}`, 0, false},
+		{`{"status":"Failure","reason":"NonZeroExitCode","details":{"causes":[{"reason":"ExitCode","message":"137"}]}}`, 137, false},
+		{`{"status":"Failure","message":"container not found (\"app\")"}`, -1, true},
+		{`not json`, -1, true},
+	} {
+		code, err := kubeExecExitCode([]byte(tc.status))
+		if code != tc.code || (err != nil) != tc.wantErr {
+			t.Errorf("kubeExecExitCode(%s) = %d, %v", tc.status, code, err)
+		}
+	}
+}
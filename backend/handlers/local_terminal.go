@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"runtime"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/creack/pty"
+)
+
+// SIGHUP 뒤 SIGKILL 까지 기다리는 시간
+const localTerminateGrace = 2 * time.Second
+
+// LocalBackend - 백엔드 호스트의 셸을 PTY 로 실행 (개발용)
+type LocalBackend struct {
+	cmd       *exec.Cmd // 실행 중인 명령어
+	pty       *os.File  // 가상 터미널
+	closeOnce sync.Once
+
+	waitOnce sync.Once // cmd.Wait 는 한 번만 호출 가능
+	waitErr  error
+}
+
+// NewLocalBackend - 로컬 셸 백엔드 생성 (Start 에서 실행)
+func NewLocalBackend() *LocalBackend {
+	return &LocalBackend{}
+}
+
+// Start - OS에 맞는 셸을 PTY 로 시작
+func (b *LocalBackend) Start(ctx context.Context, cols, rows int) error {
+	// OS에 따른 셸 명령어 결정
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd")
+	} else {
+		// Linux/macOS
+		shellPath := "/bin/bash"
+		if _, err := os.Stat(shellPath); err != nil {
+			shellPath = "/bin/sh"
+		}
+		cmd = exec.Command(shellPath)
+	}
+
+	// 환경 변수 설정
+	cmd.Env = append(os.Environ(),
+		"TERM=xterm-256color",
+		"PS1=🐳 container:$ ",
+		"LANG=en_US.UTF-8",
+	)
+
+	// PTY 생성 및 명령어 시작
+	ptyFile, err := pty.StartWithSize(cmd, &pty.Winsize{Cols: uint16(cols), Rows: uint16(rows)})
+	if err != nil {
+		return fmt.Errorf("PTY 시작 실패: %v", err)
+	}
+	log.Printf("로컬 셸 시작: %s (PID: %d)", cmd.Path, cmd.Process.Pid)
+
+	b.cmd = cmd
+	b.pty = ptyFile
+	return nil
+}
+
+// Read - PTY 출력 읽기
+// (셸이 끝나면 Linux 에서는 EIO 가 반환되므로 EOF 로 처리)
+func (b *LocalBackend) Read(p []byte) (int, error) {
+	n, err := b.pty.Read(p)
+	if err != nil && errors.Is(err, syscall.EIO) {
+		err = io.EOF
+	}
+	return n, err
+}
+
+// Write - PTY 입력
+func (b *LocalBackend) Write(p []byte) (int, error) {
+	return b.pty.Write(p)
+}
+
+// Resize - 터미널 크기 조정
+func (b *LocalBackend) Resize(cols, rows int) error {
+	return pty.Setsize(b.pty, &pty.Winsize{Cols: uint16(cols), Rows: uint16(rows)})
+}
+
+// Signal - 셸 프로세스에 시그널 전송
+func (b *LocalBackend) Signal(sig string) error {
+	signals := map[string]syscall.Signal{
+		"INT":  syscall.SIGINT,
+		"TERM": syscall.SIGTERM,
+		"KILL": syscall.SIGKILL,
+		"HUP":  syscall.SIGHUP,
+		"QUIT": syscall.SIGQUIT,
+	}
+	signal, ok := signals[sig]
+	if !ok {
+		return fmt.Errorf("%w: %s", errSignalUnsupported, sig)
+	}
+	return b.cmd.Process.Signal(signal)
+}
+
+// Wait - 프로세스 종료 대기
+func (b *LocalBackend) Wait() (int, error) {
+	b.waitOnce.Do(func() {
+		b.waitErr = b.cmd.Wait()
+	})
+	exitCode := -1
+	if b.cmd.ProcessState != nil {
+		exitCode = b.cmd.ProcessState.ExitCode()
+	}
+	return exitCode, b.waitErr
+}
+
+// Close - PTY 를 닫고 프로세스 종료
+func (b *LocalBackend) Close() error {
+	b.closeOnce.Do(func() {
+		if b.pty != nil {
+			b.pty.Close()
+		}
+		if b.cmd != nil && b.cmd.Process != nil {
+			if runtime.GOOS == "windows" {
+				b.cmd.Process.Kill()
+				// 좀비 프로세스가 남지 않도록 회수
+				go b.Wait()
+				return
+			}
+			// Unix 계열에서는 SIGHUP 을 보내고 (대화형 셸은 SIGTERM 을 무시함),
+			// 유예 시간 안에 끝나지 않으면 강제 종료
+			b.cmd.Process.Signal(syscall.SIGHUP)
+			go b.terminate()
+		}
+	})
+	return nil
+}
+
+// terminate - 유예 시간 동안 종료를 기다린 뒤 남아 있으면 SIGKILL (프로세스 회수 포함)
+func (b *LocalBackend) terminate() {
+	exited := make(chan struct{})
+	go func() {
+		b.Wait()
+		close(exited)
+	}()
+
+	select {
+	case <-exited:
+	case <-time.After(localTerminateGrace):
+		log.Printf("로컬 셸이 SIGHUP 후 종료되지 않아 강제 종료 (PID: %d)", b.cmd.Process.Pid)
+		b.cmd.Process.Kill()
+		<-exited
+	}
+}
+
+// Info - 터미널 정보
+func (b *LocalBackend) Info() map[string]interface{} {
+	info := map[string]interface{}{"backend": MethodLocal}
+	if b.cmd != nil && b.cmd.Process != nil {
+		info["pid"] = b.cmd.Process.Pid
+	}
+	return info
+}
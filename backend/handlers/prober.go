@@ -0,0 +1,232 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 동시에 검사할 최대 주소 수
+const probeConcurrency = 16
+
+// ProbeResult - NodeAddr 접속 검사 결과
+type ProbeResult struct {
+	Reachable   bool
+	Latency     time.Duration // TCP 연결 시간
+	Banner      string        // SSH 서버 배너 (SSH-2.0-...)
+	Error       string
+	LastChecked time.Time
+	LastSeen    time.Time // 마지막으로 접속에 성공한 시각
+	UpSince     time.Time // 연속으로 접속 가능했던 구간의 시작 시각
+}
+
+// NodeProber - NodeAddr 에 주기적으로 TCP 연결 + SSH 배너 확인
+type NodeProber struct {
+	interval time.Duration
+	timeout  time.Duration
+
+	mu      sync.RWMutex
+	targets []ContainerInfo         // 검사할 컨테이너 (주소마다 하나)
+	results map[string]*ProbeResult // 주소 → 마지막 결과
+}
+
+// NewNodeProber - 접속 검사기 생성
+func NewNodeProber(interval, timeout time.Duration) *NodeProber {
+	return &NodeProber{
+		interval: interval,
+		timeout:  timeout,
+		results:  make(map[string]*ProbeResult),
+	}
+}
+
+// SetTargets - 검사 대상 갱신 (InventoryCache.OnRefresh 에 등록)
+// 직접 접속하지 않는 컨테이너(NodeAddr 가 없거나 Teleport Proxy 경유)는 제외
+// 일부 소스가 조회에 실패했으면(partial) 그 범위의 이전 대상과 검사 결과는 유지
+func (p *NodeProber) SetTargets(containers []ContainerInfo, partial *PartialListError) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	seen := make(map[string]bool)
+	var targets []ContainerInfo
+	add := func(c ContainerInfo) {
+		if probed(&c) && !seen[c.NodeAddr] {
+			seen[c.NodeAddr] = true
+			targets = append(targets, c)
+		}
+	}
+	for _, c := range containers {
+		add(c)
+	}
+	if partial != nil {
+		for _, prev := range p.targets {
+			if partial.Covers(prev) {
+				add(prev)
+			}
+		}
+	}
+
+	p.targets = targets
+	// 더 이상 없는 주소의 결과는 삭제
+	for addr := range p.results {
+		if !seen[addr] {
+			delete(p.results, addr)
+		}
+	}
+}
+
+// probed - 접속 검사 대상인지 확인
+// Teleport 노드는 Proxy 를 거쳐 접속하므로 NodeAddr 에 직접 연결할 수 없어도(리버스 터널, 사설망) 접속 가능
+func probed(c *ContainerInfo) bool {
+	return c.NodeAddr != "" && terminalMethod(c) != MethodTeleport
+}
+
+// Run - ctx 가 끝날 때까지 주기적으로 전체 대상 검사
+func (p *NodeProber) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		p.probeAll(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// probeAll - 대상 주소를 병렬로 검사
+func (p *NodeProber) probeAll(ctx context.Context) {
+	p.mu.RLock()
+	targets := make([]string, 0, len(p.targets))
+	for _, c := range p.targets {
+		targets = append(targets, c.NodeAddr)
+	}
+	p.mu.RUnlock()
+
+	sem := make(chan struct{}, probeConcurrency)
+	var wg sync.WaitGroup
+	for _, addr := range targets {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(addr string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			p.record(addr, p.probe(ctx, addr))
+		}(addr)
+	}
+	wg.Wait()
+}
+
+// probe - 주소 하나 검사 (TCP 연결 후 SSH 배너 한 줄 읽기)
+func (p *NodeProber) probe(ctx context.Context, addr string) ProbeResult {
+	result := ProbeResult{LastChecked: time.Now()}
+
+	dialer := net.Dialer{Timeout: p.timeout}
+	start := time.Now()
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		result.Error = fmt.Sprintf("TCP 연결 실패: %v", err)
+		return result
+	}
+	defer conn.Close()
+	result.Latency = time.Since(start)
+
+	conn.SetReadDeadline(time.Now().Add(p.timeout))
+	line, err := bufio.NewReaderSize(conn, 256).ReadString('\n')
+	if err != nil && line == "" {
+		result.Error = fmt.Sprintf("SSH 배너 수신 실패: %v", err)
+		return result
+	}
+
+	line = strings.TrimSpace(line)
+	if !strings.HasPrefix(line, "SSH-") {
+		result.Error = fmt.Sprintf("SSH 서버가 아님 (배너: %q)", line)
+		return result
+	}
+
+	result.Reachable = true
+	result.Banner = line
+	return result
+}
+
+// record - 검사 결과 저장 (LastSeen/UpSince 유지)
+func (p *NodeProber) record(addr string, result ProbeResult) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	prev, exists := p.results[addr]
+	if result.Reachable {
+		result.LastSeen = result.LastChecked
+		result.UpSince = result.LastChecked
+		if exists && prev.Reachable {
+			result.UpSince = prev.UpSince
+		}
+	} else if exists {
+		result.LastSeen = prev.LastSeen
+	}
+
+	if exists && prev.Reachable != result.Reachable {
+		log.Printf("노드 접속 상태 변경: %s (reachable: %v, %s)", addr, result.Reachable, result.Error)
+	}
+	p.results[addr] = &result
+}
+
+// Result - 주소의 마지막 검사 결과 (아직 검사 전이면 nil)
+func (p *NodeProber) Result(addr string) *ProbeResult {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	result, exists := p.results[addr]
+	if !exists {
+		return nil
+	}
+	copied := *result
+	return &copied
+}
+
+// Annotate - 컨테이너 정보에 검사 결과(lastSeen/uptime/reachable) 반영
+func (p *NodeProber) Annotate(c *ContainerInfo) {
+	if !probed(c) {
+		return
+	}
+	result := p.Result(c.NodeAddr)
+	if result == nil {
+		return
+	}
+
+	reachable := result.Reachable
+	c.Reachable = &reachable
+	if !result.LastSeen.IsZero() {
+		c.LastSeen = result.LastSeen.UTC().Format(time.RFC3339)
+	}
+	if result.Reachable {
+		c.Uptime = formatUptime(time.Since(result.UpSince))
+		c.LatencyMs = float64(result.Latency.Microseconds()) / 1000
+	}
+}
+
+// formatUptime - "2d 5h 30m" 형식 (프론트엔드 Mock 데이터와 동일)
+func formatUptime(d time.Duration) string {
+	d = d.Truncate(time.Minute)
+	days := d / (24 * time.Hour)
+	d -= days * 24 * time.Hour
+	hours := d / time.Hour
+	d -= hours * time.Hour
+	minutes := d / time.Minute
+
+	switch {
+	case days > 0:
+		return fmt.Sprintf("%dd %dh %dm", days, hours, minutes)
+	case hours > 0:
+		return fmt.Sprintf("%dh %dm", hours, minutes)
+	default:
+		return fmt.Sprintf("%dm", minutes)
+	}
+}
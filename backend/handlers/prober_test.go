@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"context"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// newBannerServer - 연결마다 SSH 배너를 보내는 주소
+func newBannerServer(t *testing.T) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			conn.Write([]byte("SSH-2.0-test\r\n"))
+			conn.Close()
+		}
+	}()
+	return listener.Addr().String()
+}
+
+// proberTargets - 검사 대상 주소
+func proberTargets(p *NodeProber) []string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	addrs := []string{}
+	for _, c := range p.targets {
+		addrs = append(addrs, c.NodeAddr)
+	}
+	return addrs
+}
+
+func TestNodeProberTargets(t *testing.T) {
+	ssh := ContainerInfo{ID: "web-1", Source: "file", Method: MethodSSH, NodeAddr: newBannerServer(t), Status: "online"}
+	teleport := ContainerInfo{ID: "node-1", Source: "teleport", NodeAddr: "10.0.0.1:3022", Status: "online"}
+	viaProxy := ContainerInfo{ID: "db-1", Source: "file", Method: MethodTeleport, NodeAddr: "10.0.0.2:3022", Status: "online"}
+	docker := ContainerInfo{ID: "abc", Source: "docker", Status: "running"}
+
+	p := NewNodeProber(time.Minute, time.Second)
+	p.SetTargets([]ContainerInfo{ssh, teleport, viaProxy, docker, ssh}, nil)
+	if got := proberTargets(p); !reflect.DeepEqual(got, []string{ssh.NodeAddr}) {
+		t.Fatalf("검사 대상 = %q", got)
+	}
+	p.probeAll(context.Background())
+
+	annotated := ssh
+	p.Annotate(&annotated)
+	if annotated.Reachable == nil || !*annotated.Reachable || annotated.LastSeen == "" {
+		t.Errorf("SSH 노드 검사 결과 = %+v", annotated)
+	}
+
+	// Proxy 를 거치는 노드는 검사 결과가 없고 접속 가능 여부에 영향 없음
+	h := &TeleportHandler{prober: p}
+	for _, c := range []ContainerInfo{teleport, viaProxy} {
+		p.Annotate(&c)
+		if c.Reachable != nil {
+			t.Errorf("%s: Reachable = %v", c.ID, *c.Reachable)
+		}
+		if ok, reason := h.checkConnectable(&c); !ok {
+			t.Errorf("%s: 접속 불가 (%s)", c.ID, reason)
+		}
+	}
+
+	// file 소스 조회 실패: 그 소스의 이전 대상과 검사 결과 유지
+	p.SetTargets([]ContainerInfo{teleport}, &PartialListError{Failed: []FailedScope{{Source: "file"}}})
+	if got := proberTargets(p); !reflect.DeepEqual(got, []string{ssh.NodeAddr}) {
+		t.Errorf("일부 실패 후 검사 대상 = %q", got)
+	}
+	if p.Result(ssh.NodeAddr) == nil {
+		t.Error("일부 실패 후 검사 결과가 지워짐")
+	}
+
+	// 다른 소스만 실패했으면 빠진 대상은 삭제
+	p.SetTargets(nil, &PartialListError{Failed: []FailedScope{{Source: "teleport"}}})
+	if got := proberTargets(p); len(got) != 0 || p.Result(ssh.NodeAddr) != nil {
+		t.Errorf("삭제 후 검사 대상 = %q, 결과 %+v", got, p.Result(ssh.NodeAddr))
+	}
+}
+
+func TestNodeProberUnreachable(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := listener.Addr().String()
+	listener.Close()
+
+	c := ContainerInfo{ID: "web-1", Method: MethodSSH, NodeAddr: addr, Status: "online"}
+	p := NewNodeProber(time.Minute, time.Second)
+	p.SetTargets([]ContainerInfo{c}, nil)
+	p.probeAll(context.Background())
+
+	p.Annotate(&c)
+	if c.Reachable == nil || *c.Reachable {
+		t.Fatalf("닫힌 주소 검사 결과 = %+v", c)
+	}
+	if ok, _ := (&TeleportHandler{prober: p}).checkConnectable(&c); ok {
+		t.Error("접속할 수 없는 노드가 접속 가능")
+	}
+}
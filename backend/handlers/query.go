@@ -0,0 +1,369 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 500
+)
+
+// 라벨 셀렉터 연산자
+const (
+	selectorEquals    = "="
+	selectorNotEquals = "!="
+	selectorIn        = "in"
+	selectorNotIn     = "notin"
+	selectorExists    = "exists"
+	selectorNotExists = "!exists"
+)
+
+// labelRequirement - 라벨 조건 하나 (예: env=production, service in (web,api))
+type labelRequirement struct {
+	key      string
+	operator string
+	values   []string
+}
+
+// LabelSelector - Kubernetes 스타일 라벨 셀렉터 (조건은 모두 AND)
+type LabelSelector []labelRequirement
+
+// 집합 조건: "key in (a,b)", "key notin (a,b)"
+var setRequirementPattern = regexp.MustCompile(`^([^\s=!(),]+)\s+(in|notin)\s*\(([^()]*)\)$`)
+
+// ParseLabelSelector - 라벨 셀렉터 문자열 파싱
+//
+//	env=production,team!=frontend,service in (web,api),tier,!canary
+func ParseLabelSelector(selector string) (LabelSelector, error) {
+	var result LabelSelector
+	for _, term := range splitSelectorTerms(selector) {
+		term = strings.TrimSpace(term)
+		if term == "" {
+			continue
+		}
+
+		req, err := parseRequirement(term)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, req)
+	}
+	return result, nil
+}
+
+// splitSelectorTerms - 괄호 안의 쉼표는 무시하고 조건 단위로 분리
+func splitSelectorTerms(selector string) []string {
+	var (
+		terms []string
+		depth int
+		start int
+	)
+	for i, ch := range selector {
+		switch ch {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				terms = append(terms, selector[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(terms, selector[start:])
+}
+
+// parseRequirement - 조건 하나 파싱
+func parseRequirement(term string) (labelRequirement, error) {
+	if m := setRequirementPattern.FindStringSubmatch(term); m != nil {
+		var values []string
+		for _, v := range strings.Split(m[3], ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				values = append(values, v)
+			}
+		}
+		if len(values) == 0 {
+			return labelRequirement{}, fmt.Errorf("셀렉터 값 목록이 비어 있음: %q", term)
+		}
+		return labelRequirement{key: m[1], operator: m[2], values: values}, nil
+	}
+
+	var req labelRequirement
+	switch {
+	case strings.HasPrefix(term, "!"):
+		req = labelRequirement{key: strings.TrimSpace(term[1:]), operator: selectorNotExists}
+	case strings.Contains(term, "!="):
+		parts := strings.SplitN(term, "!=", 2)
+		req = labelRequirement{key: strings.TrimSpace(parts[0]), operator: selectorNotEquals, values: []string{strings.TrimSpace(parts[1])}}
+	case strings.Contains(term, "=="):
+		parts := strings.SplitN(term, "==", 2)
+		req = labelRequirement{key: strings.TrimSpace(parts[0]), operator: selectorEquals, values: []string{strings.TrimSpace(parts[1])}}
+	case strings.Contains(term, "="):
+		parts := strings.SplitN(term, "=", 2)
+		req = labelRequirement{key: strings.TrimSpace(parts[0]), operator: selectorEquals, values: []string{strings.TrimSpace(parts[1])}}
+	default:
+		req = labelRequirement{key: term, operator: selectorExists}
+	}
+
+	if req.key == "" || strings.ContainsAny(req.key, " ()=!,") {
+		return labelRequirement{}, fmt.Errorf("잘못된 셀렉터 조건: %q", term)
+	}
+	return req, nil
+}
+
+// Matches - 라벨이 모든 조건을 만족하는지 확인
+func (s LabelSelector) Matches(labels map[string]string) bool {
+	for _, req := range s {
+		value, exists := labels[req.key]
+		switch req.operator {
+		case selectorEquals:
+			if !exists || value != req.values[0] {
+				return false
+			}
+		case selectorNotEquals:
+			// 라벨이 없는 경우도 != 조건을 만족 (Kubernetes 와 동일)
+			if exists && value == req.values[0] {
+				return false
+			}
+		case selectorIn:
+			if !exists || !containsString(req.values, value) {
+				return false
+			}
+		case selectorNotIn:
+			if exists && containsString(req.values, value) {
+				return false
+			}
+		case selectorExists:
+			if !exists {
+				return false
+			}
+		case selectorNotExists:
+			if exists {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// containsString - 목록에 값이 있는지 확인
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// ContainerQuery - /api/containers 조회 조건
+type ContainerQuery struct {
+	Selector LabelSelector // ?selector=env=production,team!=frontend
+	Search   string        // ?q= 이름/ID 부분 일치 (대소문자 무시)
+	Statuses []string      // ?status=online,running
+	Clusters []string      // ?cluster=prod,staging
+	SortBy   string        // ?sort=name (앞에 '-' 를 붙이면 내림차순)
+	Desc     bool
+
+	// 오프셋 페이지네이션: ?page=1&pageSize=50
+	Page     int
+	PageSize int
+
+	// 커서 페이지네이션: ?cursor=...&limit=50 (응답의 nextCursor 사용)
+	Cursor string
+	Limit  int
+}
+
+// 정렬 가능한 필드
+var containerSortFields = map[string]func(c *ContainerInfo) string{
+	"id":      func(c *ContainerInfo) string { return c.ID },
+	"name":    func(c *ContainerInfo) string { return strings.ToLower(c.Name) },
+	"status":  func(c *ContainerInfo) string { return c.Status },
+	"source":  func(c *ContainerInfo) string { return c.Source },
+	"cluster": func(c *ContainerInfo) string { return c.Cluster },
+	"created": func(c *ContainerInfo) string { return c.Created },
+}
+
+// ParseContainerQuery - URL 쿼리에서 조회 조건 파싱
+func ParseContainerQuery(values url.Values) (*ContainerQuery, error) {
+	q := &ContainerQuery{
+		Search: strings.ToLower(strings.TrimSpace(values.Get("q"))),
+		SortBy: "name",
+		Cursor: values.Get("cursor"),
+	}
+
+	selector := values.Get("selector")
+	if selector == "" {
+		selector = values.Get("labelSelector")
+	}
+	var err error
+	if q.Selector, err = ParseLabelSelector(selector); err != nil {
+		return nil, err
+	}
+
+	q.Statuses = splitQueryList(values.Get("status"))
+	q.Clusters = splitQueryList(values.Get("cluster"))
+
+	if sortBy := values.Get("sort"); sortBy != "" {
+		q.Desc = strings.HasPrefix(sortBy, "-")
+		q.SortBy = strings.TrimPrefix(sortBy, "-")
+		if _, ok := containerSortFields[q.SortBy]; !ok {
+			return nil, fmt.Errorf("지원하지 않는 정렬 필드: %q", q.SortBy)
+		}
+	}
+
+	if q.Page, err = parsePositiveInt(values, "page"); err != nil {
+		return nil, err
+	}
+	if q.PageSize, err = parsePositiveInt(values, "pageSize"); err != nil {
+		return nil, err
+	}
+	if q.Limit, err = parsePositiveInt(values, "limit"); err != nil {
+		return nil, err
+	}
+
+	if q.PageSize > 0 && q.Page == 0 {
+		q.Page = 1
+	}
+	if q.Page > 0 && q.PageSize == 0 {
+		q.PageSize = defaultPageSize
+	}
+	if q.Cursor != "" && q.Limit == 0 {
+		q.Limit = defaultPageSize
+	}
+	if q.PageSize > maxPageSize {
+		q.PageSize = maxPageSize
+	}
+	if q.Limit > maxPageSize {
+		q.Limit = maxPageSize
+	}
+	return q, nil
+}
+
+// splitQueryList - 쉼표로 구분된 쿼리 값 목록 파싱
+func splitQueryList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// parsePositiveInt - 양의 정수 쿼리 값 파싱 (없으면 0)
+func parsePositiveInt(values url.Values, key string) (int, error) {
+	value := values.Get(key)
+	if value == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("%s 는 1 이상의 정수여야 합니다: %q", key, value)
+	}
+	return n, nil
+}
+
+// Apply - 필터/정렬/페이지네이션 적용
+// total 은 필터 후 전체 개수, nextCursor 는 다음 페이지가 있을 때만 설정
+func (q *ContainerQuery) Apply(containers []ContainerInfo) (page []ContainerInfo, total int, nextCursor string, err error) {
+	filtered := make([]ContainerInfo, 0, len(containers))
+	for _, c := range containers {
+		if q.matches(&c) {
+			filtered = append(filtered, c)
+		}
+	}
+	total = len(filtered)
+
+	// 정렬 (같은 값이면 ID 순)
+	keyOf := containerSortFields[q.SortBy]
+	sort.SliceStable(filtered, func(i, j int) bool {
+		return q.less(keyOf(&filtered[i]), filtered[i].ID, keyOf(&filtered[j]), filtered[j].ID)
+	})
+
+	switch {
+	case q.Cursor != "" || q.Limit > 0:
+		start := 0
+		if q.Cursor != "" {
+			cursorKey, cursorID, err := decodeContainerCursor(q.Cursor)
+			if err != nil {
+				return nil, 0, "", err
+			}
+			start = sort.Search(len(filtered), func(i int) bool {
+				return q.less(cursorKey, cursorID, keyOf(&filtered[i]), filtered[i].ID)
+			})
+		}
+		end := start + q.Limit
+		if end < len(filtered) {
+			last := &filtered[end-1]
+			nextCursor = encodeContainerCursor(keyOf(last), last.ID)
+		} else {
+			end = len(filtered)
+		}
+		return filtered[start:end], total, nextCursor, nil
+
+	case q.PageSize > 0:
+		start := (q.Page - 1) * q.PageSize
+		if start > len(filtered) {
+			start = len(filtered)
+		}
+		end := start + q.PageSize
+		if end > len(filtered) {
+			end = len(filtered)
+		}
+		return filtered[start:end], total, "", nil
+	}
+
+	return filtered, total, "", nil
+}
+
+// matches - 검색/상태/라벨 조건 확인
+func (q *ContainerQuery) matches(c *ContainerInfo) bool {
+	if q.Search != "" &&
+		!strings.Contains(strings.ToLower(c.Name), q.Search) &&
+		!strings.Contains(strings.ToLower(c.ID), q.Search) {
+		return false
+	}
+	if len(q.Statuses) > 0 && !containsString(q.Statuses, c.Status) {
+		return false
+	}
+	if len(q.Clusters) > 0 && !containsString(q.Clusters, c.Cluster) {
+		return false
+	}
+	return q.Selector.Matches(c.Labels)
+}
+
+// less - 정렬 순서 비교 (정렬 키, 같으면 ID 오름차순)
+// 같은 항목은 false 여야 커서 위치의 항목을 다음 페이지에서 다시 보내지 않음
+func (q *ContainerQuery) less(keyA, idA, keyB, idB string) bool {
+	if keyA != keyB {
+		return (keyA < keyB) != q.Desc
+	}
+	return idA < idB
+}
+
+// encodeContainerCursor - 마지막 항목의 (정렬 키, ID)를 커서로 인코딩
+func encodeContainerCursor(key, id string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(key + "\x00" + id))
+}
+
+// decodeContainerCursor - 커서 디코딩
+func decodeContainerCursor(cursor string) (string, string, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return "", "", fmt.Errorf("잘못된 커서: %q", cursor)
+	}
+	parts := strings.SplitN(string(data), "\x00", 2)
+	if len(parts) != 2 {
+		return "", "", fmt.Errorf("잘못된 커서: %q", cursor)
+	}
+	return parts[0], parts[1], nil
+}
@@ -0,0 +1,225 @@
+package handlers
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseLabelSelector(t *testing.T) {
+	for _, tc := range []struct {
+		selector string
+		want     LabelSelector
+	}{
+		{"", nil},
+		{"env=production", LabelSelector{{key: "env", operator: selectorEquals, values: []string{"production"}}}},
+		{"env==production", LabelSelector{{key: "env", operator: selectorEquals, values: []string{"production"}}}},
+		{"team != frontend", LabelSelector{{key: "team", operator: selectorNotEquals, values: []string{"frontend"}}}},
+		{"service in (web, api)", LabelSelector{{key: "service", operator: selectorIn, values: []string{"web", "api"}}}},
+		{"tier notin (cache)", LabelSelector{{key: "tier", operator: selectorNotIn, values: []string{"cache"}}}},
+		{"tier", LabelSelector{{key: "tier", operator: selectorExists}}},
+		{"!canary", LabelSelector{{key: "canary", operator: selectorNotExists}}},
+		{"env=production,,service in (web,api),!canary", LabelSelector{
+			{key: "env", operator: selectorEquals, values: []string{"production"}},
+			{key: "service", operator: selectorIn, values: []string{"web", "api"}},
+			{key: "canary", operator: selectorNotExists},
+		}},
+	} {
+		got, err := ParseLabelSelector(tc.selector)
+		if err != nil || !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("ParseLabelSelector(%q) = %+v, %v, want %+v", tc.selector, got, err, tc.want)
+		}
+	}
+
+	for _, selector := range []string{
+		"=production",
+		"!",
+		"env in ()",
+		"env in (a,b",
+		"bad key=x",
+	} {
+		if got, err := ParseLabelSelector(selector); err == nil {
+			t.Errorf("ParseLabelSelector(%q) = %+v, 에러 없음", selector, got)
+		}
+	}
+}
+
+func TestLabelSelectorMatches(t *testing.T) {
+	labels := map[string]string{"env": "production", "service": "web"}
+	for _, tc := range []struct {
+		selector string
+		want     bool
+	}{
+		{"env=production", true},
+		{"env=staging", false},
+		{"team=web", false},
+		{"env!=staging", true},
+		{"team!=frontend", true}, // 라벨이 없어도 != 는 만족
+		{"service in (web,api)", true},
+		{"team in (web)", false},
+		{"service notin (web)", false},
+		{"team notin (web)", true},
+		{"env", true},
+		{"team", false},
+		{"!team", true},
+		{"!env", false},
+		{"env=production,service=api", false},
+	} {
+		selector, err := ParseLabelSelector(tc.selector)
+		if err != nil {
+			t.Fatalf("ParseLabelSelector(%q): %v", tc.selector, err)
+		}
+		if got := selector.Matches(labels); got != tc.want {
+			t.Errorf("%q.Matches = %v, want %v", tc.selector, got, tc.want)
+		}
+	}
+}
+
+func TestParseContainerQuery(t *testing.T) {
+	for _, tc := range []struct {
+		query                 string
+		page, pageSize, limit int
+		sortBy                string
+		desc                  bool
+	}{
+		{"", 0, 0, 0, "name", false},
+		{"page=2", 2, defaultPageSize, 0, "name", false},
+		{"pageSize=10", 1, 10, 0, "name", false},
+		{"pageSize=100000", 1, maxPageSize, 0, "name", false},
+		{"cursor=abc", 0, 0, defaultPageSize, "name", false},
+		{"limit=100000", 0, 0, maxPageSize, "name", false},
+		{"sort=-created", 0, 0, 0, "created", true},
+	} {
+		values, _ := url.ParseQuery(tc.query)
+		q, err := ParseContainerQuery(values)
+		if err != nil {
+			t.Errorf("ParseContainerQuery(%q): %v", tc.query, err)
+			continue
+		}
+		if q.Page != tc.page || q.PageSize != tc.pageSize || q.Limit != tc.limit || q.SortBy != tc.sortBy || q.Desc != tc.desc {
+			t.Errorf("ParseContainerQuery(%q) = %+v", tc.query, q)
+		}
+	}
+
+	for _, query := range []string{"page=0", "pageSize=-1", "limit=x", "sort=memory", "selector=env in ()"} {
+		values, _ := url.ParseQuery(query)
+		if _, err := ParseContainerQuery(values); err == nil {
+			t.Errorf("ParseContainerQuery(%q) 에러 없음", query)
+		}
+	}
+}
+
+// testQueryContainers - c1 ~ c<n> (이름 역순이 ID 순)
+func testQueryContainers(n int) []ContainerInfo {
+	containers := make([]ContainerInfo, 0, n)
+	for i := 1; i <= n; i++ {
+		status := "online"
+		if i%2 == 0 {
+			status = "offline"
+		}
+		containers = append(containers, ContainerInfo{
+			ID:     fmt.Sprintf("c%d", i),
+			Name:   fmt.Sprintf("node-%02d", n+1-i),
+			Status: status,
+			Labels: map[string]string{"env": "production"},
+		})
+	}
+	return containers
+}
+
+func containerIDs(containers []ContainerInfo) string {
+	ids := make([]string, 0, len(containers))
+	for _, c := range containers {
+		ids = append(ids, c.ID)
+	}
+	return strings.Join(ids, ",")
+}
+
+func TestContainerQueryOffsetPagination(t *testing.T) {
+	containers := testQueryContainers(5)
+	for _, tc := range []struct {
+		query string
+		want  string
+		total int
+	}{
+		{"", "c5,c4,c3,c2,c1", 5},
+		{"page=1&pageSize=2", "c5,c4", 5},
+		{"page=3&pageSize=2", "c1", 5},
+		{"page=4&pageSize=2", "", 5}, // 범위를 넘으면 빈 페이지
+		{"pageSize=5", "c5,c4,c3,c2,c1", 5},
+		{"status=online&page=2&pageSize=2", "c1", 3},
+		{"q=NODE-0&sort=-name&pageSize=3", "c1,c2,c3", 5},
+		{"selector=env!=production", "", 0},
+	} {
+		values, _ := url.ParseQuery(tc.query)
+		q, err := ParseContainerQuery(values)
+		if err != nil {
+			t.Fatalf("ParseContainerQuery(%q): %v", tc.query, err)
+		}
+		page, total, next, err := q.Apply(containers)
+		if err != nil || containerIDs(page) != tc.want || total != tc.total || next != "" {
+			t.Errorf("%q: Apply = %q, total %d, next %q, %v (want %q, %d)", tc.query, containerIDs(page), total, next, err, tc.want, tc.total)
+		}
+	}
+}
+
+func TestContainerQueryCursorPagination(t *testing.T) {
+	// 페이지를 끝까지 넘기면서 받은 항목과 마지막 페이지의 nextCursor 확인
+	walk := func(containers []ContainerInfo, query string) (pages []string) {
+		t.Helper()
+		values, _ := url.ParseQuery(query)
+		for cursor := ""; ; {
+			values.Set("cursor", cursor)
+			if cursor == "" {
+				values.Del("cursor")
+			}
+			q, err := ParseContainerQuery(values)
+			if err != nil {
+				t.Fatalf("ParseContainerQuery(%q): %v", query, err)
+			}
+			page, total, next, err := q.Apply(containers)
+			if err != nil || total != len(containers) {
+				t.Fatalf("%q: Apply total %d, %v", query, total, err)
+			}
+			pages = append(pages, containerIDs(page))
+			if next == "" {
+				return pages
+			}
+			cursor = next
+		}
+	}
+
+	for _, tc := range []struct {
+		n     int
+		query string
+		want  []string
+	}{
+		{5, "limit=2", []string{"c5,c4", "c3,c2", "c1"}},
+		{4, "limit=2", []string{"c4,c3", "c2,c1"}}, // 나누어 떨어지면 빈 페이지 없이 끝남
+		{4, "limit=4", []string{"c4,c3,c2,c1"}},
+		{0, "limit=2", []string{""}},
+		{3, "limit=2&sort=-name", []string{"c1,c2", "c3"}},
+		{5, "limit=2&sort=-status", []string{"c1,c3", "c5,c2", "c4"}}, // 같은 값이면 내림차순에서도 ID 순
+	} {
+		if got := walk(testQueryContainers(tc.n), tc.query); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%d개, %q: 페이지 = %q, want %q", tc.n, tc.query, got, tc.want)
+		}
+	}
+
+	// 다음 페이지를 받기 전에 커서 위치의 항목(c4)이 삭제되어도 그 뒤부터 이어짐
+	containers := testQueryContainers(5)
+	q, _ := ParseContainerQuery(url.Values{"limit": {"2"}})
+	_, _, next, _ := q.Apply(containers)
+	q, _ = ParseContainerQuery(url.Values{"limit": {"2"}, "cursor": {next}})
+	page, total, _, err := q.Apply(append(containers[:3:3], containers[4:]...))
+	if err != nil || containerIDs(page) != "c3,c2" || total != 4 {
+		t.Errorf("삭제 후 다음 페이지 = %q, total %d, %v", containerIDs(page), total, err)
+	}
+
+	q, _ = ParseContainerQuery(url.Values{"cursor": {"%%%"}})
+	if _, _, _, err := q.Apply(containers); err == nil {
+		t.Error("잘못된 커서 에러 없음")
+	}
+}
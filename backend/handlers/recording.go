@@ -0,0 +1,342 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Heo-YJ/teleport-opensource/config"
+)
+
+// 터미널 세션 녹화 (asciicast v2, asciinema 플레이어로 재생 가능)
+// 첫 줄은 헤더, 이후 한 줄에 이벤트 하나: [시작 후 경과 시간(초), 종류, 데이터]
+// 종류: o (출력), i (입력, 정책이 all 인 경우), r (크기 조정 "80x24"), m (표시: 크기 제한 초과)
+
+// recordingUploadTimeout - 끝난 녹화를 저장소에 넘기는 최대 시간
+const recordingUploadTimeout = 10 * time.Minute
+
+// RecordingMeta - 녹화 정보 (녹화 파일 옆 <세션 ID>.json)
+type RecordingMeta struct {
+	SessionID   string     `json:"sessionId"`
+	ContainerID string     `json:"containerId"`
+	Container   string     `json:"container,omitempty"` // 컨테이너 이름
+	Cluster     string     `json:"cluster,omitempty"`
+	User        string     `json:"userId,omitempty"`
+	StartedAt   time.Time  `json:"startedAt"`
+	EndedAt     *time.Time `json:"endedAt,omitempty"` // 녹화 중이면 없음
+	Duration    float64    `json:"duration"`          // 마지막 이벤트 시각 (초)
+	Size        int64      `json:"size"`              // 녹화 파일 크기 (바이트)
+	Input       bool       `json:"input"`             // 입력 포함 여부
+	Truncated   bool       `json:"truncated"`         // 크기 제한으로 중간부터 녹화하지 않음
+
+	Encryption *RecordingEncryption `json:"encryption,omitempty"` // 암호화한 경우 (감싼 데이터 키)
+}
+
+// Recorder - 터미널 세션 하나의 녹화
+// nil 이면 녹화하지 않음 (모든 메서드를 nil 에서 호출 가능)
+type Recorder struct {
+	mu       sync.Mutex
+	file     *os.File
+	writer   *bufio.Writer
+	path     string // .cast 파일 경로
+	metaPath string // .json 파일 경로
+	meta     RecordingMeta
+	maxSize  int64
+	closed   bool
+	storage  RecordingStorage // 녹화가 끝나면 파일을 넘길 저장소
+
+	// 녹화 파일 암호화 (writer → encrypter → file, 암호화하지 않으면 nil)
+	// 마지막 청크를 기록하면 dataKey 를 완료로 다시 감쌈
+	encrypter *recordingEncrypter
+	keys      *RecordingKeyring
+	dataKey   []byte
+
+	// 끝에 잘린 UTF-8 문자 (다음 데이터와 합쳐서 기록)
+	pendingOutput []byte
+	pendingInput  []byte
+}
+
+// NewRecorder - 녹화 파일을 만들고 헤더 기록 (녹화가 끝나면 storage 에 저장, keys 가 있으면 암호화)
+func NewRecorder(cfg *config.RecordingConfig, storage RecordingStorage, keys *RecordingKeyring, meta RecordingMeta, cols, rows int, term string) (*Recorder, error) {
+	if err := os.MkdirAll(cfg.Dir, 0o700); err != nil {
+		return nil, fmt.Errorf("녹화 디렉터리 생성 실패: %v", err)
+	}
+
+	name := recordingFileName(meta.SessionID)
+	path := filepath.Join(cfg.Dir, name+".cast")
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("녹화 파일 생성 실패: %v", err)
+	}
+
+	meta.Input = cfg.RecordInput()
+	r := &Recorder{
+		file:     file,
+		path:     path,
+		metaPath: filepath.Join(cfg.Dir, name+".json"),
+		meta:     meta,
+		maxSize:  int64(cfg.MaxSize),
+		storage:  storage,
+	}
+	var out io.Writer = file
+	if keys != nil {
+		dataKey, encryption, err := keys.newDataKey(meta.SessionID)
+		if err == nil {
+			r.encrypter, err = newRecordingEncrypter(file, dataKey, meta.SessionID)
+		}
+		if err != nil {
+			file.Close()
+			os.Remove(path)
+			return nil, fmt.Errorf("녹화 암호화 준비 실패: %v", err)
+		}
+		r.meta.Encryption = encryption
+		r.keys, r.dataKey = keys, dataKey
+		out = r.encrypter
+	}
+	r.writer = bufio.NewWriterSize(out, 32*1024)
+
+	title := meta.Container
+	if title == "" {
+		title = meta.ContainerID
+	}
+	if err := r.writeLine(map[string]interface{}{
+		"version":   2,
+		"width":     cols,
+		"height":    rows,
+		"timestamp": meta.StartedAt.Unix(),
+		"title":     title,
+		"env":       map[string]string{"TERM": term},
+	}); err != nil {
+		file.Close()
+		os.Remove(path)
+		return nil, fmt.Errorf("녹화 헤더 기록 실패: %v", err)
+	}
+	if keys != nil {
+		keys.track(r)
+	}
+	if err := r.saveMeta(); err != nil {
+		log.Printf("녹화 정보 저장 실패: %v", err)
+	}
+
+	log.Printf("⏺️ 세션 녹화 시작: %s (%s, 입력 포함: %v, 암호화: %v)", meta.SessionID, path, meta.Input, keys != nil)
+	return r, nil
+}
+
+// Output - 터미널 출력 기록
+func (r *Recorder) Output(data []byte) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.pendingOutput = r.recordLocked("o", r.pendingOutput, data)
+}
+
+// Input - 터미널 입력 기록 (입력 녹화 정책일 때만)
+func (r *Recorder) Input(data []byte) {
+	if r == nil || !r.meta.Input {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.pendingInput = r.recordLocked("i", r.pendingInput, data)
+}
+
+// Resize - 터미널 크기 조정 기록
+func (r *Recorder) Resize(cols, rows int) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.eventLocked("r", fmt.Sprintf("%dx%d", cols, rows))
+}
+
+// recordLocked - 잘린 UTF-8 문자를 남겨 두고 이벤트 기록 (남은 바이트 반환)
+func (r *Recorder) recordLocked(code string, pending, data []byte) []byte {
+	data = append(pending, data...)
+	complete, rest := splitUTF8(data)
+	if len(complete) > 0 {
+		r.eventLocked(code, string(complete))
+	}
+	return append([]byte(nil), rest...)
+}
+
+// eventLocked - 이벤트 한 줄 기록 (크기 제한을 넘으면 표시 이벤트를 남기고 이후는 무시)
+func (r *Recorder) eventLocked(code, data string) {
+	if r.closed || r.meta.Truncated {
+		return
+	}
+
+	elapsed := time.Since(r.meta.StartedAt).Seconds()
+	event := []interface{}{float64(int64(elapsed*1e6)) / 1e6, code, data}
+	line, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+	if r.meta.Size+int64(len(line))+1 > r.maxSize {
+		r.meta.Truncated = true
+		log.Printf("⚠️ 녹화 크기 제한(%d 바이트) 초과, 이후는 녹화하지 않음: %s", r.maxSize, r.meta.SessionID)
+		event[1], event[2] = "m", "녹화 크기 제한 초과"
+	}
+	if err := r.writeLine(event); err != nil {
+		log.Printf("녹화 기록 실패: %v (세션: %s)", err, r.meta.SessionID)
+		r.meta.Truncated = true
+		return
+	}
+	r.meta.Duration = event[0].(float64)
+}
+
+// writeLine - JSON 한 줄 쓰기
+func (r *Recorder) writeLine(v interface{}) error {
+	line, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+	n, err := r.writer.Write(line)
+	r.meta.Size += int64(n)
+	return err
+}
+
+// Close - 남은 데이터를 기록하고 녹화 종료 (여러 번 호출 가능)
+func (r *Recorder) Close() error {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		return nil
+	}
+	// 끝까지 완성되지 않은 문자는 그대로 기록 (JSON 인코딩에서 대체 문자로 바뀜)
+	if len(r.pendingOutput) > 0 {
+		r.eventLocked("o", string(r.pendingOutput))
+	}
+	if len(r.pendingInput) > 0 {
+		r.eventLocked("i", string(r.pendingInput))
+	}
+	r.closed = true
+
+	err := r.writer.Flush()
+	if r.encrypter != nil && err == nil {
+		err = r.encrypter.Close()
+		if err == nil {
+			// 마지막 청크까지 기록한 경우만 완료 (실패하면 미완료로 남아 잘린 녹화로 열림)
+			var encryption *RecordingEncryption
+			if encryption, err = r.keys.wrap(r.dataKey, r.meta.SessionID, true); err == nil {
+				r.meta.Encryption = encryption
+			}
+		}
+		r.dataKey = nil
+	}
+	if closeErr := r.file.Close(); err == nil {
+		err = closeErr
+	}
+	now := time.Now()
+	r.meta.EndedAt = &now
+	if metaErr := r.saveMeta(); err == nil {
+		err = metaErr
+	}
+	if r.keys != nil {
+		r.keys.untrack(r)
+	}
+
+	log.Printf("⏹️ 세션 녹화 종료: %s (%d 바이트, 잘림: %v)", r.meta.SessionID, r.meta.Size, r.meta.Truncated)
+	if err != nil {
+		return fmt.Errorf("녹화 파일 정리 실패: %v", err)
+	}
+	// 업로드가 오래 걸릴 수 있으므로 터미널 종료를 기다리게 하지 않음
+	go r.store(r.meta)
+	return nil
+}
+
+// store - 끝난 녹화를 저장소에 넘김 (실패하면 로컬 파일이 남음)
+func (r *Recorder) store(meta RecordingMeta) {
+	ctx, cancel := context.WithTimeout(context.Background(), recordingUploadTimeout)
+	defer cancel()
+	if err := r.storage.Save(ctx, meta, r.path, r.metaPath); err != nil {
+		log.Printf("녹화 저장 실패 (%s): %v", meta.SessionID, err)
+	}
+}
+
+// Discard - 녹화 취소 (터미널을 시작하지 못한 경우, 파일 삭제)
+func (r *Recorder) Discard() {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.closed {
+		r.closed = true
+		r.file.Close()
+	}
+	os.Remove(r.path)
+	os.Remove(r.metaPath)
+	if r.keys != nil {
+		r.keys.untrack(r)
+	}
+}
+
+// Info - 녹화 정보
+func (r *Recorder) Info() RecordingMeta {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.meta
+}
+
+// rewrapKey - 녹화 중인 데이터 키를 활성 마스터 키로 다시 감싸서 녹화 정보 저장 (이미 끝났으면 false)
+func (r *Recorder) rewrapKey() (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		return false, nil
+	}
+	encryption, err := r.keys.wrap(r.dataKey, r.meta.SessionID, false)
+	if err != nil {
+		return true, err
+	}
+	r.meta.Encryption = encryption
+	return true, r.saveMeta()
+}
+
+// saveMeta - 녹화 정보 파일 저장
+func (r *Recorder) saveMeta() error {
+	return writeRecordingMeta(r.metaPath, r.meta)
+}
+
+// writeRecordingMeta - 녹화 정보 파일 저장 (임시 파일에 쓰고 이름 변경)
+func writeRecordingMeta(path string, meta RecordingMeta) error {
+	data, err := json.MarshalIndent(meta, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// recordingFileName - 세션 ID 를 파일 이름으로 (경로 구분자 등은 _ 로)
+func recordingFileName(sessionID string) string {
+	return strings.Map(func(c rune) rune {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c == '-', c == '.', c == '_':
+			return c
+		default:
+			return '_'
+		}
+	}, sessionID)
+}
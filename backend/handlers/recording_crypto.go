@@ -0,0 +1,547 @@
+package handlers
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+// 녹화 암호화 (RECORDING_KEYRING)
+// 녹화마다 임의의 데이터 키(AES-256)를 만들어 녹화 파일을 암호화하고,
+// 데이터 키는 마스터 키로 감싸서 녹화 정보(.json)에 저장
+//
+// 녹화 파일 형식: 청크의 연속, 청크 = [4바이트 길이 (최상위 비트: 마지막 청크)] + AES-GCM 암호문
+// nonce 는 청크 번호, AAD 는 세션 ID + 마지막 청크 여부 (순서 변경, 잘라내기, 다른 녹화와 바꾸기 검출)
+//
+// 데이터 키를 감쌀 때 AAD 는 세션 ID + 완료 여부: 녹화 중에는 미완료로 감싸고,
+// 마지막 청크를 기록하면 완료로 다시 감쌈. 완료로 감싼 녹화는 마지막 청크가 있어야 열림
+// (녹화 정보 파일은 서명되지 않으므로 endedAt / truncated 로 판단하지 않음)
+//
+// 키 파일 (YAML, 키는 base64 32바이트: openssl rand -base64 32)
+//
+//	active: 2025-09
+//	keys:
+//	  - id: 2025-08
+//	    key: <base64>
+//	  - id: 2025-09
+//	    key: <base64>
+//
+// 키 교체: 새 키를 추가하고 active 를 바꾸면 새 녹화부터 새 키를 쓰고,
+// 이전 키로 감싼 데이터 키는 백그라운드에서 새 키로 다시 감쌈 (모두 끝나면 이전 키 삭제 가능)
+
+const (
+	recordingCipher          = "AES-256-GCM"
+	recordingChunkSize       = 64 * 1024
+	recordingFinalChunk      = 1 << 31
+	recordingKeyringInterval = time.Minute // 키 파일 변경 확인 간격
+	recordingRewrapTimeout   = 10 * time.Minute
+)
+
+// errRecordingTampered - 인증 태그 검증 실패 (녹화 파일이 바뀌었거나 잘림)
+var errRecordingTampered = errors.New("녹화 무결성 검증 실패")
+
+// RecordingEncryption - 녹화 암호화 정보 (녹화 정보 파일에 저장)
+type RecordingEncryption struct {
+	Algorithm  string `json:"algorithm"`  // AES-256-GCM
+	KeyID      string `json:"keyId"`      // 데이터 키를 감싼 마스터 키 ID
+	WrappedKey string `json:"wrappedKey"` // 마스터 키로 감싼 데이터 키 (base64, nonce + 암호문)
+	Complete   bool   `json:"complete"`   // 마지막 청크까지 기록함 (감싼 데이터 키의 AAD 에 포함되어 바꿀 수 없음)
+}
+
+// RecordingKeyring - 녹화 마스터 키 (키 파일이 바뀌면 다시 읽음)
+type RecordingKeyring struct {
+	path string
+
+	mu      sync.RWMutex
+	active  string
+	keys    map[string]cipher.AEAD
+	modTime time.Time
+
+	liveMu sync.Mutex
+	live   map[string]*Recorder // 녹화 중인 세션 ID → Recorder (키 교체 때 Recorder 가 직접 다시 감쌈)
+}
+
+// recordingKeyFile - 키 파일 형식
+type recordingKeyFile struct {
+	Active string `yaml:"active"`
+	Keys   []struct {
+		ID  string `yaml:"id"`
+		Key string `yaml:"key"`
+	} `yaml:"keys"`
+}
+
+// EncryptedRecordingStorage - 암호화된 녹화를 복호화해서 여는 저장소 (keys 가 nil 이면 암호화된 녹화를 열지 않음)
+// 녹화 파일 주소는 항상 서명된 API 주소 (S3 서명 주소로는 암호문이 그대로 내려가므로)
+type EncryptedRecordingStorage struct {
+	RecordingStorage
+	keys *RecordingKeyring
+	urls *recordingURLSigner
+}
+
+// LoadRecordingKeyring - 키 파일 읽기
+func LoadRecordingKeyring(path string) (*RecordingKeyring, error) {
+	k := &RecordingKeyring{path: path}
+	if _, err := k.reload(); err != nil {
+		return nil, err
+	}
+	return k, nil
+}
+
+// reload - 키 파일이 바뀌었으면 다시 읽음 (활성 키가 바뀌었으면 true, 실패하면 이전 키 유지)
+func (k *RecordingKeyring) reload() (bool, error) {
+	info, err := os.Stat(k.path)
+	if err != nil {
+		return false, fmt.Errorf("녹화 키 파일 확인 실패: %v", err)
+	}
+	k.mu.RLock()
+	unchanged := info.ModTime().Equal(k.modTime)
+	k.mu.RUnlock()
+	if unchanged {
+		return false, nil
+	}
+	if info.Mode().Perm()&0o077 != 0 {
+		log.Printf("⚠️ 녹화 키 파일을 다른 사용자도 읽을 수 있음: %s (%v)", k.path, info.Mode().Perm())
+	}
+
+	data, err := os.ReadFile(k.path)
+	if err != nil {
+		return false, fmt.Errorf("녹화 키 파일 읽기 실패: %v", err)
+	}
+	var file recordingKeyFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return false, fmt.Errorf("녹화 키 파일 파싱 실패: %v", err)
+	}
+
+	keys := make(map[string]cipher.AEAD, len(file.Keys))
+	for _, entry := range file.Keys {
+		if entry.ID == "" {
+			return false, fmt.Errorf("녹화 키 파일: id 가 없는 키")
+		}
+		raw, err := base64.StdEncoding.DecodeString(entry.Key)
+		if err != nil || len(raw) != 32 {
+			return false, fmt.Errorf("녹화 키 파일: 키 %s 가 base64 32바이트가 아님", entry.ID)
+		}
+		aead, err := newRecordingAEAD(raw)
+		if err != nil {
+			return false, err
+		}
+		keys[entry.ID] = aead
+	}
+	if _, ok := keys[file.Active]; !ok {
+		return false, fmt.Errorf("녹화 키 파일: 활성 키 %q 가 없음", file.Active)
+	}
+
+	k.mu.Lock()
+	changed := k.active != file.Active
+	k.active = file.Active
+	k.keys = keys
+	k.modTime = info.ModTime()
+	k.mu.Unlock()
+	return changed, nil
+}
+
+// activeID - 새 녹화에 쓰는 마스터 키 ID
+func (k *RecordingKeyring) activeID() string {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return k.active
+}
+
+// track - 녹화 중인 Recorder 등록 (녹화 정보 파일을 처음 쓰기 전에)
+func (k *RecordingKeyring) track(r *Recorder) {
+	k.liveMu.Lock()
+	defer k.liveMu.Unlock()
+	if k.live == nil {
+		k.live = make(map[string]*Recorder)
+	}
+	k.live[r.meta.SessionID] = r
+}
+
+// untrack - 끝난 Recorder 등록 해제 (마지막 녹화 정보 파일을 쓴 뒤에)
+func (k *RecordingKeyring) untrack(r *Recorder) {
+	k.liveMu.Lock()
+	defer k.liveMu.Unlock()
+	if k.live[r.meta.SessionID] == r {
+		delete(k.live, r.meta.SessionID)
+	}
+}
+
+// recorder - 세션 ID 로 녹화 중인 Recorder 찾기 (없으면 nil)
+func (k *RecordingKeyring) recorder(sessionID string) *Recorder {
+	k.liveMu.Lock()
+	defer k.liveMu.Unlock()
+	return k.live[sessionID]
+}
+
+// newDataKey - 녹화용 데이터 키를 만들어 활성 마스터 키로 감쌈 (미완료)
+func (k *RecordingKeyring) newDataKey(sessionID string) ([]byte, *RecordingEncryption, error) {
+	dataKey := make([]byte, 32)
+	if _, err := rand.Read(dataKey); err != nil {
+		return nil, nil, fmt.Errorf("데이터 키 생성 실패: %v", err)
+	}
+	encryption, err := k.wrap(dataKey, sessionID, false)
+	if err != nil {
+		return nil, nil, err
+	}
+	return dataKey, encryption, nil
+}
+
+// wrap - 데이터 키를 활성 마스터 키로 감쌈 (AAD: 세션 ID + 완료 여부)
+func (k *RecordingKeyring) wrap(dataKey []byte, sessionID string, complete bool) (*RecordingEncryption, error) {
+	k.mu.RLock()
+	keyID, aead := k.active, k.keys[k.active]
+	k.mu.RUnlock()
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("nonce 생성 실패: %v", err)
+	}
+	wrapped := aead.Seal(nonce, nonce, dataKey, recordingKeyAAD(sessionID, complete))
+	return &RecordingEncryption{
+		Algorithm:  recordingCipher,
+		KeyID:      keyID,
+		WrappedKey: base64.StdEncoding.EncodeToString(wrapped),
+		Complete:   complete,
+	}, nil
+}
+
+// unwrap - 감싼 데이터 키 풀기 (태그가 맞지 않으면 errRecordingTampered, Complete 를 바꿨어도 실패)
+func (k *RecordingKeyring) unwrap(encryption *RecordingEncryption, sessionID string) ([]byte, error) {
+	if encryption.Algorithm != recordingCipher {
+		return nil, fmt.Errorf("지원하지 않는 녹화 암호화 방식: %s", encryption.Algorithm)
+	}
+	k.mu.RLock()
+	aead, ok := k.keys[encryption.KeyID]
+	k.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("녹화 마스터 키 %q 가 키 파일에 없음", encryption.KeyID)
+	}
+
+	wrapped, err := base64.StdEncoding.DecodeString(encryption.WrappedKey)
+	if err != nil || len(wrapped) < aead.NonceSize() {
+		return nil, fmt.Errorf("%w: 잘못된 데이터 키", errRecordingTampered)
+	}
+	nonce, sealed := wrapped[:aead.NonceSize()], wrapped[aead.NonceSize():]
+	dataKey, err := aead.Open(nil, nonce, sealed, recordingKeyAAD(sessionID, encryption.Complete))
+	if err != nil {
+		return nil, fmt.Errorf("%w: 데이터 키 인증 실패", errRecordingTampered)
+	}
+	return dataKey, nil
+}
+
+// newRecordingAEAD - AES-256-GCM
+func newRecordingAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("암호화 키 설정 실패: %v", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// recordingEncrypter - 녹화 파일을 청크 단위로 암호화해서 쓰는 Writer (Close 에서 마지막 청크 기록)
+type recordingEncrypter struct {
+	w         io.Writer
+	aead      cipher.AEAD
+	sessionID string
+	buffer    []byte
+	counter   uint64
+}
+
+// newRecordingEncrypter - 데이터 키로 암호화하는 Writer 생성
+func newRecordingEncrypter(w io.Writer, dataKey []byte, sessionID string) (*recordingEncrypter, error) {
+	aead, err := newRecordingAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	return &recordingEncrypter{
+		w:         w,
+		aead:      aead,
+		sessionID: sessionID,
+		buffer:    make([]byte, 0, recordingChunkSize),
+	}, nil
+}
+
+// Write - 청크 크기만큼 모이면 암호화해서 기록
+func (e *recordingEncrypter) Write(p []byte) (int, error) {
+	written := len(p)
+	for len(p) > 0 {
+		n := min(recordingChunkSize-len(e.buffer), len(p))
+		e.buffer = append(e.buffer, p[:n]...)
+		p = p[n:]
+		if len(e.buffer) == recordingChunkSize {
+			if err := e.seal(false); err != nil {
+				return written - len(p), err
+			}
+		}
+	}
+	return written, nil
+}
+
+// Close - 남은 데이터를 마지막 청크로 기록 (아래 Writer 는 닫지 않음)
+func (e *recordingEncrypter) Close() error {
+	return e.seal(true)
+}
+
+// seal - 버퍼를 청크 하나로 암호화해서 기록
+func (e *recordingEncrypter) seal(final bool) error {
+	sealed := e.aead.Seal(nil, recordingChunkNonce(e.aead, e.counter), e.buffer, recordingChunkAAD(e.sessionID, final))
+	header := uint32(len(sealed))
+	if final {
+		header |= recordingFinalChunk
+	}
+	var prefix [4]byte
+	binary.BigEndian.PutUint32(prefix[:], header)
+	if _, err := e.w.Write(prefix[:]); err != nil {
+		return err
+	}
+	if _, err := e.w.Write(sealed); err != nil {
+		return err
+	}
+	e.counter++
+	e.buffer = e.buffer[:0]
+	return nil
+}
+
+// recordingDecrypter - 녹화 파일을 청크 단위로 복호화해서 읽는 Reader
+// 인증에 실패한 청크는 내보내지 않고 그 시점의 Read 가 errRecordingTampered 반환
+// (앞쪽 청크는 이미 읽었을 수 있으므로 끝까지 에러 없이 읽은 경우만 온전한 녹화)
+type recordingDecrypter struct {
+	r         io.ReadCloser
+	aead      cipher.AEAD
+	sessionID string
+	partial   bool // 마지막 청크가 없어도 됨 (녹화 중이거나 서버가 녹화 중에 종료된 경우)
+	counter   uint64
+	plain     []byte // 아직 Read 로 넘기지 않은 평문
+	err       error  // 다음 Read 에서 반환할 에러 (끝까지 읽었으면 io.EOF)
+}
+
+// newRecordingDecrypter - 데이터 키로 복호화하는 Reader 생성 (Close 하면 r 도 닫음)
+func newRecordingDecrypter(r io.ReadCloser, dataKey []byte, sessionID string, partial bool) (*recordingDecrypter, error) {
+	aead, err := newRecordingAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	return &recordingDecrypter{r: r, aead: aead, sessionID: sessionID, partial: partial}, nil
+}
+
+// Read - 복호화한 평문 읽기 (남은 평문이 없으면 다음 청크 복호화)
+func (d *recordingDecrypter) Read(p []byte) (int, error) {
+	for len(d.plain) == 0 {
+		if d.err != nil {
+			return 0, d.err
+		}
+		d.err = d.open()
+	}
+	n := copy(p, d.plain)
+	d.plain = d.plain[n:]
+	return n, nil
+}
+
+// Close - 녹화 파일 닫기
+func (d *recordingDecrypter) Close() error {
+	return d.r.Close()
+}
+
+// open - 다음 청크를 읽어 복호화 (마지막 청크였으면 io.EOF)
+func (d *recordingDecrypter) open() error {
+	var prefix [4]byte
+	if _, err := io.ReadFull(d.r, prefix[:]); err != nil {
+		return d.endOfChunks(err)
+	}
+	header := binary.BigEndian.Uint32(prefix[:])
+	final := header&recordingFinalChunk != 0
+	size := int(header &^ recordingFinalChunk)
+	if size > recordingChunkSize+d.aead.Overhead() {
+		return fmt.Errorf("%w: 잘못된 청크 크기 %d", errRecordingTampered, size)
+	}
+
+	sealed := make([]byte, size)
+	if _, err := io.ReadFull(d.r, sealed); err != nil {
+		return d.endOfChunks(err)
+	}
+	plain, err := d.aead.Open(sealed[:0], recordingChunkNonce(d.aead, d.counter), sealed, recordingChunkAAD(d.sessionID, final))
+	if err != nil {
+		return fmt.Errorf("%w: 청크 %d 인증 실패", errRecordingTampered, d.counter)
+	}
+	d.counter++
+
+	if final {
+		// 마지막 청크 뒤에 덧붙인 데이터 (평문을 내보내기 전에 확인)
+		if n, _ := io.ReadFull(d.r, prefix[:1]); n > 0 {
+			return fmt.Errorf("%w: 마지막 청크 뒤에 데이터가 있음", errRecordingTampered)
+		}
+		d.plain = plain
+		return io.EOF
+	}
+	d.plain = plain
+	return nil
+}
+
+// endOfChunks - 마지막 청크 전에 파일이 끝난 경우
+func (d *recordingDecrypter) endOfChunks(err error) error {
+	if err != io.EOF && err != io.ErrUnexpectedEOF {
+		return err
+	}
+	if d.partial {
+		return io.EOF
+	}
+	return fmt.Errorf("%w: 녹화 파일이 잘림", errRecordingTampered)
+}
+
+// recordingChunkNonce - 청크 번호로 만든 nonce (녹화마다 데이터 키가 다르므로 겹치지 않음)
+func recordingChunkNonce(aead cipher.AEAD, counter uint64) []byte {
+	nonce := make([]byte, aead.NonceSize())
+	binary.BigEndian.PutUint64(nonce[len(nonce)-8:], counter)
+	return nonce
+}
+
+// recordingKeyAAD - 세션 ID + 녹화 완료 여부
+func recordingKeyAAD(sessionID string, complete bool) []byte {
+	return recordingChunkAAD(sessionID, complete)
+}
+
+// recordingChunkAAD - 세션 ID + 마지막 청크 여부
+func recordingChunkAAD(sessionID string, final bool) []byte {
+	aad := []byte(sessionID)
+	if final {
+		return append(aad, 1)
+	}
+	return append(aad, 0)
+}
+
+// NewEncryptedRecordingStorage - 녹화 저장소에 복호화 추가
+func NewEncryptedRecordingStorage(storage RecordingStorage, keys *RecordingKeyring, urls *recordingURLSigner) *EncryptedRecordingStorage {
+	return &EncryptedRecordingStorage{RecordingStorage: storage, keys: keys, urls: urls}
+}
+
+// Open - 녹화 파일 열기 (암호화된 녹화는 읽으면서 청크 단위로 복호화)
+// 데이터 키나 첫 청크의 검증에 실패하면 열지 않고, 이후 청크는 Read 에서 errRecordingTampered
+func (s *EncryptedRecordingStorage) Open(ctx context.Context, sessionID string) (io.ReadCloser, *RecordingMeta, error) {
+	reader, meta, err := s.RecordingStorage.Open(ctx, sessionID)
+	if err != nil || meta.Encryption == nil {
+		return reader, meta, err
+	}
+
+	if s.keys == nil {
+		reader.Close()
+		return nil, nil, fmt.Errorf("암호화된 녹화지만 녹화 키 파일이 설정되지 않음 (RECORDING_KEYRING)")
+	}
+	dataKey, err := s.keys.unwrap(meta.Encryption, meta.SessionID)
+	if err != nil {
+		reader.Close()
+		return nil, nil, err
+	}
+	// 완료로 감싼 녹화만 마지막 청크를 요구 (미완료: 녹화 중이거나 서버가 녹화 중에 종료됨)
+	decrypter, err := newRecordingDecrypter(reader, dataKey, meta.SessionID, !meta.Encryption.Complete)
+	if err != nil {
+		reader.Close()
+		return nil, nil, err
+	}
+	if decrypter.err = decrypter.open(); decrypter.err != nil && decrypter.err != io.EOF {
+		decrypter.Close()
+		return nil, nil, decrypter.err
+	}
+	return decrypter, meta, nil
+}
+
+// DownloadURL - 서명된 API 주소 (서버에서 복호화)
+func (s *EncryptedRecordingStorage) DownloadURL(sessionID string) string {
+	if s.keys == nil {
+		return s.RecordingStorage.DownloadURL(sessionID)
+	}
+	return s.urls.sign(recordingURL(sessionID), sessionID)
+}
+
+// runKeyRotation - 키 파일 변경을 확인하고, 이전 마스터 키로 감싼 데이터 키를 활성 키로 다시 감쌈
+func (s *EncryptedRecordingStorage) runKeyRotation() {
+	ticker := time.NewTicker(recordingKeyringInterval)
+	defer ticker.Stop()
+
+	s.rewrap()
+	for range ticker.C {
+		changed, err := s.keys.reload()
+		if err != nil {
+			log.Printf("⚠️ %v (이전 키 계속 사용)", err)
+			continue
+		}
+		if changed {
+			log.Printf("🔑 녹화 마스터 키 교체: %s", s.keys.activeID())
+			s.rewrap()
+		}
+	}
+}
+
+// rewrap - 활성 키가 아닌 키로 감싼 데이터 키를 다시 감쌈 (녹화 파일과 완료 여부는 그대로)
+// 녹화 중인 세션은 Recorder 가 직접 다시 감싸고, 서버가 녹화 중에 종료되어 끝나지 않은 녹화도 다시 감쌈
+func (s *EncryptedRecordingStorage) rewrap() {
+	ctx, cancel := context.WithTimeout(context.Background(), recordingRewrapTimeout)
+	defer cancel()
+
+	recordings, err := s.RecordingStorage.List(ctx, &RecordingQuery{})
+	if err != nil {
+		log.Printf("녹화 목록 조회 실패 (키 교체): %v", err)
+		return
+	}
+
+	active := s.keys.activeID()
+	rewrapped := 0
+	for _, meta := range recordings {
+		if meta.Encryption == nil || meta.Encryption.KeyID == active {
+			continue
+		}
+		if meta.EndedAt == nil {
+			// 녹화 정보 파일을 Recorder 와 동시에 쓰지 않도록 녹화 중이면 Recorder 에 맡김
+			if recorder := s.keys.recorder(meta.SessionID); recorder != nil {
+				if live, err := recorder.rewrapKey(); live {
+					if err != nil {
+						log.Printf("녹화 중인 데이터 키 다시 감싸기 실패 (%s): %v", meta.SessionID, err)
+					} else {
+						rewrapped++
+					}
+					continue
+				}
+			}
+			// 목록을 읽은 뒤에 끝났을 수 있으므로 최신 정보로 다시 감쌈
+			latest, err := s.RecordingStorage.Get(ctx, meta.SessionID)
+			if err != nil {
+				if !isRecordingNotFound(err) {
+					log.Printf("녹화 정보 조회 실패 (%s): %v", meta.SessionID, err)
+				}
+				continue
+			}
+			if latest.Encryption == nil || latest.Encryption.KeyID == active {
+				continue
+			}
+			meta = *latest
+		}
+		dataKey, err := s.keys.unwrap(meta.Encryption, meta.SessionID)
+		if err != nil {
+			log.Printf("데이터 키 풀기 실패 (%s): %v", meta.SessionID, err)
+			continue
+		}
+		if meta.Encryption, err = s.keys.wrap(dataKey, meta.SessionID, meta.Encryption.Complete); err != nil {
+			log.Printf("데이터 키 감싸기 실패 (%s): %v", meta.SessionID, err)
+			continue
+		}
+		if err := s.RecordingStorage.UpdateMeta(ctx, meta); err != nil {
+			log.Printf("녹화 정보 저장 실패 (%s): %v", meta.SessionID, err)
+			continue
+		}
+		rewrapped++
+	}
+	if rewrapped > 0 {
+		log.Printf("🔑 녹화 %d개의 데이터 키를 마스터 키 %s 로 다시 감쌈", rewrapped, active)
+	}
+}
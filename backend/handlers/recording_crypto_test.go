@@ -0,0 +1,339 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Heo-YJ/teleport-opensource/config"
+)
+
+// testKeyring - 테스트용 녹화 키 파일 (키 ID 마다 임의의 키)
+type testKeyring struct {
+	path   string
+	keys   map[string]string // 키 ID → base64 키
+	writes int
+}
+
+func newTestKeyring(t *testing.T) *testKeyring {
+	return &testKeyring{path: filepath.Join(t.TempDir(), "keyring.yaml"), keys: map[string]string{}}
+}
+
+// write - ids 키를 담고 active 를 활성 키로 하는 키 파일 쓰기 (처음 쓰는 ID 는 새 키 생성)
+func (k *testKeyring) write(t *testing.T, active string, ids ...string) {
+	t.Helper()
+	var file strings.Builder
+	fmt.Fprintf(&file, "active: %s\nkeys:\n", active)
+	for _, id := range ids {
+		if k.keys[id] == "" {
+			raw := make([]byte, 32)
+			rand.Read(raw)
+			k.keys[id] = base64.StdEncoding.EncodeToString(raw)
+		}
+		fmt.Fprintf(&file, "  - id: %s\n    key: %s\n", id, k.keys[id])
+	}
+	if err := os.WriteFile(k.path, []byte(file.String()), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	// 수정 시각으로 변경을 확인하므로 쓸 때마다 다른 시각
+	k.writes++
+	modTime := time.Now().Add(time.Duration(k.writes) * time.Second)
+	os.Chtimes(k.path, modTime, modTime)
+}
+
+// encryptTestRecording - 데이터 키로 암호화한 녹화 (final 이면 마지막 청크까지)
+func encryptTestRecording(t *testing.T, dataKey []byte, sessionID string, plain []byte, final bool) []byte {
+	t.Helper()
+	var out bytes.Buffer
+	e, err := newRecordingEncrypter(&out, dataKey, sessionID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := e.Write(plain); err != nil {
+		t.Fatal(err)
+	}
+	if final {
+		if err := e.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return out.Bytes()
+}
+
+// decryptTestRecording - 녹화 전체를 복호화해서 읽음
+func decryptTestRecording(sealed []byte, dataKey []byte, sessionID string, partial bool) ([]byte, error) {
+	d, err := newRecordingDecrypter(io.NopCloser(bytes.NewReader(sealed)), dataKey, sessionID, partial)
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(d)
+}
+
+func TestRecordingEncryptionRoundTrip(t *testing.T) {
+	dataKey := make([]byte, 32)
+	rand.Read(dataKey)
+
+	for _, size := range []int{0, 10, recordingChunkSize, 2*recordingChunkSize + 100} {
+		plain := bytes.Repeat([]byte("x"), size)
+		sealed := encryptTestRecording(t, dataKey, "s1", plain, true)
+		if bytes.Contains(sealed, []byte("xxxx")) {
+			t.Errorf("%d 바이트: 평문이 그대로 기록됨", size)
+		}
+		got, err := decryptTestRecording((sealed), dataKey, "s1", false)
+		if err != nil || !bytes.Equal(got, plain) {
+			t.Errorf("%d 바이트: 복호화 = %d 바이트, %v", size, len(got), err)
+		}
+	}
+
+	// 마지막 청크가 없는 녹화(녹화 중, 서버 종료)는 partial 일 때만 기록된 청크까지
+	plain := bytes.Repeat([]byte("y"), recordingChunkSize+10)
+	sealed := encryptTestRecording(t, dataKey, "s1", plain, false)
+	if got, err := decryptTestRecording((sealed), dataKey, "s1", true); err != nil || !bytes.Equal(got, plain[:recordingChunkSize]) {
+		t.Errorf("partial 복호화 = %d 바이트, %v", len(got), err)
+	}
+	if _, err := decryptTestRecording((sealed), dataKey, "s1", false); !isRecordingTampered(err) {
+		t.Errorf("마지막 청크 없는 녹화 = %v", err)
+	}
+}
+
+func TestRecordingEncryptionDetectsTampering(t *testing.T) {
+	dataKey := make([]byte, 32)
+	rand.Read(dataKey)
+	plain := bytes.Repeat([]byte("z"), 2*recordingChunkSize+100)
+	sealed := encryptTestRecording(t, dataKey, "s1", plain, true)
+	chunk := 4 + recordingChunkSize + 16 // 길이 + 암호문 + 태그
+
+	flipped := bytes.Clone(sealed)
+	flipped[chunk+10] ^= 1
+	swapped := append(append(bytes.Clone(sealed[chunk:2*chunk]), sealed[:chunk]...), sealed[2*chunk:]...)
+	otherKey := make([]byte, 32)
+	rand.Read(otherKey)
+
+	for _, tc := range []struct {
+		name      string
+		data      []byte
+		key       []byte
+		sessionID string
+	}{
+		{"바뀐 바이트", flipped, dataKey, "s1"},
+		{"청크 순서 변경", swapped, dataKey, "s1"},
+		{"마지막 청크 잘라냄", sealed[:2*chunk], dataKey, "s1"},
+		{"청크 중간에서 잘림", sealed[:len(sealed)-5], dataKey, "s1"},
+		{"뒤에 덧붙임", append(bytes.Clone(sealed), 0), dataKey, "s1"},
+		{"다른 녹화", sealed, dataKey, "s2"},
+		{"다른 키", sealed, otherKey, "s1"},
+	} {
+		if _, err := decryptTestRecording((tc.data), tc.key, tc.sessionID, false); !isRecordingTampered(err) {
+			t.Errorf("%s: 에러 = %v", tc.name, err)
+		}
+	}
+}
+
+func TestEncryptedRecordingStorage(t *testing.T) {
+	dir := t.TempDir()
+	keyring := newTestKeyring(t)
+	keyring.write(t, "k1", "k1")
+	keys, err := LoadRecordingKeyring(keyring.path)
+	if err != nil {
+		t.Fatalf("LoadRecordingKeyring: %v", err)
+	}
+	cfg := &config.RecordingConfig{Policy: config.RecordingOutput, Dir: dir, MaxSize: 1024 * 1024, URLTTL: time.Minute}
+	urls := newRecordingURLSigner(cfg)
+	local := NewLocalRecordingStorage(dir, urls)
+	storage := NewEncryptedRecordingStorage(local, keys, urls)
+	ctx := context.Background()
+
+	r, err := NewRecorder(cfg, local, keys, RecordingMeta{SessionID: "s1", StartedAt: time.Now()}, 80, 24, "xterm")
+	if err != nil {
+		t.Fatalf("NewRecorder: %v", err)
+	}
+	r.Output([]byte("top secret"))
+	if err := r.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	if data, _ := os.ReadFile(filepath.Join(dir, "s1.cast")); bytes.Contains(data, []byte("top secret")) || bytes.Contains(data, []byte("version")) {
+		t.Error("녹화 파일이 암호화되지 않음")
+	}
+	open := func() (string, error) {
+		reader, _, err := storage.Open(ctx, "s1")
+		if err != nil {
+			return "", err
+		}
+		defer reader.Close()
+		data, err := io.ReadAll(reader)
+		return string(data), err
+	}
+	if data, err := open(); err != nil || !strings.Contains(data, `"top secret"`) {
+		t.Fatalf("Open = %q, %v", data, err)
+	}
+	if meta, err := local.Get(ctx, "s1"); err != nil || !meta.Encryption.Complete {
+		t.Fatalf("끝난 녹화 정보 = %+v, %v", meta, err)
+	}
+
+	// 키 교체: 새 키를 활성으로 바꾸면 데이터 키만 새 키로 다시 감쌈
+	keyring.write(t, "k2", "k1", "k2")
+	if changed, err := keys.reload(); err != nil || !changed {
+		t.Fatalf("reload = %v, %v", changed, err)
+	}
+	storage.rewrap()
+	meta, err := local.Get(ctx, "s1")
+	if err != nil || meta.Encryption.KeyID != "k2" {
+		t.Fatalf("다시 감싼 녹화 정보 = %+v, %v", meta, err)
+	}
+	keyring.write(t, "k2", "k2") // 이전 키 삭제
+	if _, err := keys.reload(); err != nil {
+		t.Fatal(err)
+	}
+	if data, err := open(); err != nil || !strings.Contains(data, `"top secret"`) {
+		t.Errorf("키 교체 후 Open = %q, %v", data, err)
+	}
+
+	// 완료 여부를 미완료로 바꿔 마지막 청크 없이 열려고 하면 데이터 키를 풀 수 없음
+	metaPath := filepath.Join(dir, "s1.json")
+	incomplete := *meta
+	encryption := *meta.Encryption
+	encryption.Complete = false
+	incomplete.Encryption = &encryption
+	if err := writeRecordingMeta(metaPath, incomplete); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := open(); !isRecordingTampered(err) {
+		t.Errorf("완료 여부를 바꾼 녹화 Open 에러 = %v", err)
+	}
+	if err := writeRecordingMeta(metaPath, *meta); err != nil {
+		t.Fatal(err)
+	}
+
+	// 녹화 파일을 바꾸면 열지 않음
+	path := filepath.Join(dir, "s1.cast")
+	data, _ := os.ReadFile(path)
+	data[len(data)-1] ^= 1
+	os.WriteFile(path, data, 0o600)
+	if _, err := open(); !isRecordingTampered(err) {
+		t.Errorf("바뀐 녹화 Open 에러 = %v", err)
+	}
+
+	// 다른 녹화의 데이터 키로 바꿔치기
+	meta.SessionID = "s2"
+	if _, err := keys.unwrap(meta.Encryption, meta.SessionID); !errors.Is(err, errRecordingTampered) {
+		t.Errorf("다른 녹화의 데이터 키 = %v", err)
+	}
+
+	// 뒤쪽 청크가 바뀐 녹화는 열리지만 그 청크를 읽을 때 에러 (바뀐 청크의 평문은 내보내지 않음)
+	plain := bytes.Repeat([]byte("w"), 2*recordingChunkSize+100)
+	dataKey := make([]byte, 32)
+	rand.Read(dataKey)
+	wrapped, err := keys.wrap(dataKey, "s1", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sealed := encryptTestRecording(t, dataKey, "s1", plain, true)
+	sealed[len(sealed)-1] ^= 1
+	os.WriteFile(path, sealed, 0o600)
+	meta.SessionID, meta.Encryption = "s1", wrapped
+	if err := writeRecordingMeta(metaPath, *meta); err != nil {
+		t.Fatal(err)
+	}
+	reader, _, err := storage.Open(ctx, "s1")
+	if err != nil {
+		t.Fatalf("뒤쪽 청크가 바뀐 녹화 Open: %v", err)
+	}
+	got, err := io.ReadAll(reader)
+	reader.Close()
+	if !isRecordingTampered(err) || !bytes.Equal(got, plain[:2*recordingChunkSize]) {
+		t.Errorf("뒤쪽 청크가 바뀐 녹화 = %d 바이트, %v", len(got), err)
+	}
+
+	// 키 파일 없이는 암호화된 녹화를 열지 않음
+	if _, _, err := NewEncryptedRecordingStorage(local, nil, urls).Open(ctx, "s1"); err == nil {
+		t.Error("키 파일 없이 암호화된 녹화가 열림")
+	}
+}
+
+func TestEncryptedRecordingStorageRewrapsUnfinished(t *testing.T) {
+	dir := t.TempDir()
+	keyring := newTestKeyring(t)
+	keyring.write(t, "k1", "k1")
+	keys, err := LoadRecordingKeyring(keyring.path)
+	if err != nil {
+		t.Fatalf("LoadRecordingKeyring: %v", err)
+	}
+	cfg := &config.RecordingConfig{Policy: config.RecordingOutput, Dir: dir, MaxSize: 1024 * 1024, URLTTL: time.Minute}
+	urls := newRecordingURLSigner(cfg)
+	local := NewLocalRecordingStorage(dir, urls)
+	storage := NewEncryptedRecordingStorage(local, keys, urls)
+	ctx := context.Background()
+
+	// 녹화 중인 세션
+	r, err := NewRecorder(cfg, local, keys, RecordingMeta{SessionID: "live", StartedAt: time.Now()}, 80, 24, "xterm")
+	if err != nil {
+		t.Fatalf("NewRecorder: %v", err)
+	}
+	r.Output([]byte("still running"))
+
+	// 서버가 녹화 중에 종료되어 끝나지 않은 녹화 (마지막 청크 없음)
+	dataKey := make([]byte, 32)
+	rand.Read(dataKey)
+	encryption, err := keys.wrap(dataKey, "orphan", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	header := []byte(`{"version": 2, "width": 80, "height": 24}` + "\n")
+	plain := append(header, bytes.Repeat([]byte(`[0.5, "o", "orphaned"]`+"\n"), recordingChunkSize/20)...)
+	if err := os.WriteFile(filepath.Join(dir, "orphan.cast"), encryptTestRecording(t, dataKey, "orphan", plain, false), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	writeTestRecordingMeta(t, dir, RecordingMeta{SessionID: "orphan", StartedAt: time.Now().Add(-time.Hour), Encryption: encryption})
+
+	keyring.write(t, "k2", "k1", "k2")
+	if changed, err := keys.reload(); err != nil || !changed {
+		t.Fatalf("reload = %v, %v", changed, err)
+	}
+	storage.rewrap()
+	for _, sessionID := range []string{"live", "orphan"} {
+		meta, err := local.Get(ctx, sessionID)
+		if err != nil || meta.Encryption.KeyID != "k2" || meta.Encryption.Complete || meta.EndedAt != nil {
+			t.Errorf("%s: 다시 감싼 녹화 정보 = %+v, %v", sessionID, meta, err)
+		}
+	}
+	if info := r.Info(); info.Encryption.KeyID != "k2" {
+		t.Errorf("녹화 중인 Recorder 의 키 = %s", info.Encryption.KeyID)
+	}
+
+	// 이전 키를 삭제해도 두 녹화 모두 열림
+	keyring.write(t, "k2", "k2")
+	if _, err := keys.reload(); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if keys.recorder("live") != nil {
+		t.Error("끝난 Recorder 가 등록되어 있음")
+	}
+	for sessionID, want := range map[string]string{"live": "still running", "orphan": "orphaned"} {
+		reader, meta, err := storage.Open(ctx, sessionID)
+		if err != nil {
+			t.Errorf("%s: Open: %v", sessionID, err)
+			continue
+		}
+		data, err := io.ReadAll(reader)
+		reader.Close()
+		if err != nil || !strings.Contains(string(data), want) {
+			t.Errorf("%s: 녹화 = %d 바이트, %v", sessionID, len(data), err)
+		}
+		if sessionID == "live" && !meta.Encryption.Complete {
+			t.Errorf("끝난 녹화가 미완료: %+v", meta.Encryption)
+		}
+	}
+}
@@ -0,0 +1,545 @@
+package handlers
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
+)
+
+// 녹화 재생 WebSocket (/api/ws/recordings/{sessionId})
+//
+// 쿼리: speed=2 (배속), maxIdle=2s (입력 없이 멈춘 구간을 최대 이 시간으로 줄임), at=30 (시작 위치, 초)
+// 목록의 playbackUrl 서명(expires, signature)이 맞아야 재생 (암호화된 녹화는 서버에서 복호화)
+// 서버 → 클라이언트: output, resize ({cols, rows}) 는 터미널과 같은 메시지라 기존 xterm 컴포넌트로 표시 가능
+// 재생 상태는 system 메시지 (event: start / pause / resume / seek / speed / marker / end, position, duration, speed, paused)
+// 클라이언트 → 서버: {"type":"pause"}, {"type":"resume"}, {"type":"seek","data":12.5}, {"type":"speed","data":2}, {"type":"ping"}
+
+// 재생 배속 범위
+const (
+	minPlaybackSpeed = 0.1
+	maxPlaybackSpeed = 16
+)
+
+// 위치 이동 시 터미널 화면 초기화 (RIS)
+const terminalReset = "\x1bc"
+
+// castEvent - asciicast v2 이벤트 하나
+type castEvent struct {
+	Time float64
+	Code string
+	Data string
+}
+
+// castRecording - 재생할 녹화 (헤더 크기와 이벤트 목록)
+type castRecording struct {
+	Width    int
+	Height   int
+	Events   []castEvent
+	Duration float64
+}
+
+// readCast - asciicast v2 파일 파싱
+// 녹화 중인 파일은 마지막 줄이 잘려 있을 수 있으므로 읽을 수 없는 줄은 건너뜀
+func readCast(r io.Reader) (*castRecording, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+
+	if !scanner.Scan() {
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("녹화 파일 읽기 실패: %w", err)
+		}
+		return nil, fmt.Errorf("빈 녹화 파일입니다")
+	}
+	var header struct {
+		Version int `json:"version"`
+		Width   int `json:"width"`
+		Height  int `json:"height"`
+	}
+	if err := json.Unmarshal(scanner.Bytes(), &header); err != nil || header.Version != 2 {
+		return nil, fmt.Errorf("asciicast v2 형식이 아닙니다")
+	}
+
+	cast := &castRecording{Width: header.Width, Height: header.Height}
+	skipped := 0
+	for scanner.Scan() {
+		var fields []json.RawMessage
+		var event castEvent
+		if json.Unmarshal(scanner.Bytes(), &fields) != nil || len(fields) != 3 ||
+			json.Unmarshal(fields[0], &event.Time) != nil ||
+			json.Unmarshal(fields[1], &event.Code) != nil ||
+			json.Unmarshal(fields[2], &event.Data) != nil {
+			skipped++
+			continue
+		}
+		cast.Events = append(cast.Events, event)
+		cast.Duration = event.Time
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("녹화 파일 읽기 실패: %w", err)
+	}
+	if skipped > 0 {
+		log.Printf("녹화 파일에서 읽을 수 없는 이벤트 %d개 건너뜀", skipped)
+	}
+	return cast, nil
+}
+
+// compressIdle - 이벤트 사이 간격을 최대 maxIdle 초로 줄임 (0 이면 그대로)
+func (c *castRecording) compressIdle(maxIdle float64) {
+	if maxIdle <= 0 {
+		return
+	}
+	var last, shift float64
+	for i := range c.Events {
+		original := c.Events[i].Time
+		if gap := original - last; gap > maxIdle {
+			shift += gap - maxIdle
+		}
+		last = original
+		c.Events[i].Time = original - shift
+	}
+	if n := len(c.Events); n > 0 {
+		c.Duration = c.Events[n-1].Time
+	}
+}
+
+// recordingPlayer - WebSocket 하나의 녹화 재생 상태 (쓰기는 run 고루틴만)
+type recordingPlayer struct {
+	conn     *websocket.Conn
+	cast     *castRecording
+	controls chan TerminalMessage
+	batch    int // output 프레임 하나의 최대 바이트
+
+	speed    float64
+	paused   bool
+	next     int       // 다음에 보낼 이벤트
+	position float64   // base 시각의 재생 위치 (초)
+	base     time.Time // 마지막으로 재생을 시작/이동한 실제 시각
+}
+
+// HandlePlayRecording - 녹화 재생 WebSocket
+func (h *TeleportHandler) HandlePlayRecording(w http.ResponseWriter, r *http.Request) {
+	sessionID := mux.Vars(r)["sessionId"]
+	query := r.URL.Query()
+	if !h.terminalHandler.recordingURLs.verify(sessionID, query) {
+		h.auditRecording(r, AuditError, sessionID, nil, map[string]interface{}{"message": "녹화 재생 주소 서명 불일치"})
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return
+	}
+
+	speed, err := parsePlaybackFloat(query.Get("speed"), 1)
+	if err != nil {
+		http.Error(w, "Invalid speed", http.StatusBadRequest)
+		return
+	}
+	maxIdle, err := parsePlaybackDuration(query.Get("maxIdle"))
+	if err != nil {
+		http.Error(w, "Invalid maxIdle", http.StatusBadRequest)
+		return
+	}
+	at, err := parsePlaybackFloat(query.Get("at"), 0)
+	if err != nil {
+		http.Error(w, "Invalid at", http.StatusBadRequest)
+		return
+	}
+
+	reader, meta, err := h.terminalHandler.recordings.Open(r.Context(), sessionID)
+	if err != nil {
+		h.auditRecordingError(r, sessionID, err)
+		writeRecordingError(w, sessionID, err)
+		return
+	}
+	cast, err := readCast(reader)
+	reader.Close()
+	if isRecordingTampered(err) {
+		h.auditRecordingError(r, sessionID, err)
+		writeRecordingError(w, sessionID, err)
+		return
+	}
+	if err != nil {
+		log.Printf("녹화 파일 읽기 실패 (%s): %v", sessionID, err)
+		http.Error(w, "Invalid recording", http.StatusUnprocessableEntity)
+		return
+	}
+	cast.compressIdle(maxIdle)
+
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Printf("WebSocket 업그레이드 실패: %v", err)
+		return
+	}
+	defer conn.Close()
+	log.Printf("▶️ 녹화 재생: %s (%.1f초, 이벤트 %d개, 배속 %.1f)", meta.SessionID, cast.Duration, len(cast.Events), speed)
+	h.auditRecording(r, AuditFileAccessed, sessionID, meta, map[string]interface{}{"access": "playback"})
+
+	player := &recordingPlayer{
+		conn:     conn,
+		cast:     cast,
+		controls: make(chan TerminalMessage, 16),
+		batch:    h.terminalHandler.config.OutputBatchSize,
+		speed:    clampPlaybackSpeed(speed),
+	}
+
+	// 클라이언트 제어 메시지는 별도 고루틴에서 읽어 run 으로 전달
+	disconnected := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(disconnected)
+		for {
+			var message TerminalMessage
+			if err := conn.ReadJSON(&message); err != nil {
+				if _, ok := err.(*json.SyntaxError); ok {
+					continue
+				}
+				return
+			}
+			select {
+			case player.controls <- message:
+			case <-stopped:
+				return
+			}
+		}
+	}()
+
+	player.run(at, disconnected)
+	close(stopped)
+	log.Printf("⏹️ 녹화 재생 종료: %s", meta.SessionID)
+}
+
+// run - 녹화 시각에 맞춰 이벤트 전송 (클라이언트 연결이 끊길 때까지)
+func (p *recordingPlayer) run(at float64, done <-chan struct{}) {
+	if err := p.conn.WriteJSON(TerminalMessage{Type: "resize", Data: ResizeMessage{Cols: p.cast.Width, Rows: p.cast.Height}}); err != nil {
+		return
+	}
+	if at > 0 {
+		p.seek(at)
+	} else {
+		p.base = time.Now()
+	}
+	p.status("start")
+
+	for {
+		var timer <-chan time.Time
+		if !p.paused {
+			if p.next >= len(p.cast.Events) {
+				// 끝까지 재생하면 일시 정지 상태로 두고 seek 를 기다림
+				p.position, p.paused = p.cast.Duration, true
+				p.status("end")
+				continue
+			}
+			wait := (p.cast.Events[p.next].Time - p.current()) / p.speed
+			timer = time.After(time.Duration(wait * float64(time.Second)))
+		}
+
+		select {
+		case <-done:
+			return
+		case message := <-p.controls:
+			p.control(message)
+		case <-timer:
+			if err := p.sendUntil(p.current()); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// control - 클라이언트 제어 메시지 처리
+func (p *recordingPlayer) control(message TerminalMessage) {
+	switch message.Type {
+	case "pause":
+		if !p.paused {
+			p.position, p.paused = p.current(), true
+		}
+		p.status("pause")
+
+	case "resume", "play":
+		if p.paused {
+			if p.next >= len(p.cast.Events) {
+				// 끝난 뒤 다시 재생하면 처음부터
+				p.seek(0)
+			}
+			p.paused, p.base = false, time.Now()
+		}
+		p.status("resume")
+
+	case "seek":
+		if position, ok := message.Data.(float64); ok {
+			p.seek(position)
+			p.status("seek")
+		}
+
+	case "speed":
+		if speed, ok := message.Data.(float64); ok {
+			p.position, p.base = p.current(), time.Now()
+			p.speed = clampPlaybackSpeed(speed)
+			p.status("speed")
+		}
+
+	case "ping":
+		p.conn.WriteJSON(TerminalMessage{Type: "pong", Data: "재생 연결 정상"})
+
+	default:
+		log.Printf("알 수 없는 재생 제어 메시지: %s", message.Type)
+	}
+}
+
+// seek - 화면을 초기화하고 position 까지의 이벤트를 한 번에 보낸 뒤 그 위치부터 재생
+func (p *recordingPlayer) seek(position float64) {
+	position = min(max(position, 0), p.cast.Duration)
+
+	p.conn.WriteJSON(TerminalMessage{Type: "output", Data: terminalReset})
+	p.conn.WriteJSON(TerminalMessage{Type: "resize", Data: ResizeMessage{Cols: p.cast.Width, Rows: p.cast.Height}})
+	p.next = 0
+	p.sendUntil(position)
+	p.position, p.base = position, time.Now()
+}
+
+// sendUntil - position 까지의 이벤트 전송 (연속된 출력은 프레임 하나로 묶음)
+func (p *recordingPlayer) sendUntil(position float64) error {
+	var output strings.Builder
+	flush := func() error {
+		if output.Len() == 0 {
+			return nil
+		}
+		err := p.conn.WriteJSON(TerminalMessage{Type: "output", Data: output.String()})
+		output.Reset()
+		return err
+	}
+
+	for ; p.next < len(p.cast.Events) && p.cast.Events[p.next].Time <= position; p.next++ {
+		event := p.cast.Events[p.next]
+		switch event.Code {
+		case "o":
+			output.WriteString(event.Data)
+			if output.Len() >= p.batch {
+				if err := flush(); err != nil {
+					return err
+				}
+			}
+
+		case "r":
+			var cols, rows int
+			if _, err := fmt.Sscanf(event.Data, "%dx%d", &cols, &rows); err != nil {
+				continue
+			}
+			if err := flush(); err != nil {
+				return err
+			}
+			if err := p.conn.WriteJSON(TerminalMessage{Type: "resize", Data: ResizeMessage{Cols: cols, Rows: rows}}); err != nil {
+				return err
+			}
+
+		case "m":
+			if err := flush(); err != nil {
+				return err
+			}
+			p.sendStatus("marker", event.Data)
+		}
+		// i (입력) 는 화면에 표시하지 않음
+	}
+	return flush()
+}
+
+// current - 현재 재생 위치 (초)
+func (p *recordingPlayer) current() float64 {
+	if p.paused {
+		return p.position
+	}
+	return p.position + time.Since(p.base).Seconds()*p.speed
+}
+
+// status - 재생 상태 알림
+func (p *recordingPlayer) status(event string) {
+	p.sendStatus(event, "")
+}
+
+// sendStatus - 재생 상태 system 메시지 전송
+func (p *recordingPlayer) sendStatus(event, message string) {
+	data := map[string]interface{}{
+		"event":    event,
+		"position": math.Round(p.current()*1000) / 1000,
+		"duration": math.Round(p.cast.Duration*1000) / 1000,
+		"speed":    p.speed,
+		"paused":   p.paused,
+	}
+	if message != "" {
+		data["message"] = message
+	}
+	p.conn.WriteJSON(TerminalMessage{Type: "system", Data: data})
+}
+
+// clampPlaybackSpeed - 배속을 허용 범위로
+func clampPlaybackSpeed(speed float64) float64 {
+	return min(max(speed, minPlaybackSpeed), maxPlaybackSpeed)
+}
+
+// parsePlaybackFloat - 숫자 쿼리 값 파싱 (비어 있으면 기본값)
+func parsePlaybackFloat(value string, fallback float64) (float64, error) {
+	if value == "" {
+		return fallback, nil
+	}
+	n, err := strconv.ParseFloat(value, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("잘못된 숫자: %q", value)
+	}
+	return n, nil
+}
+
+// parsePlaybackDuration - 기간(2s) 또는 초(2.5) 쿼리 값 파싱
+func parsePlaybackDuration(value string) (float64, error) {
+	if d, err := time.ParseDuration(value); err == nil {
+		return d.Seconds(), nil
+	}
+	return parsePlaybackFloat(value, 0)
+}
+
+// HandleGetRecordings - 녹화 목록 조회 (?containerId=&user=&cluster=&from=&to=&limit=)
+// 목록에는 서명된 주소가 들어가므로 인증 프록시가 확인한 사용자만 조회 가능
+// RECORDING_ADMINS 가 아니면 자기 세션의 녹화만 보임
+func (h *TeleportHandler) HandleGetRecordings(w http.ResponseWriter, r *http.Request) {
+	query, err := ParseRecordingQuery(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	user, all := h.terminalHandler.recordingViewer(r)
+	if user == "" {
+		http.Error(w, "Authenticated user required", http.StatusUnauthorized)
+		return
+	}
+	if !all {
+		if query.User != "" && query.User != user {
+			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+			return
+		}
+		query.User = user
+	}
+
+	// total 은 limit 으로 자르기 전의 개수 (저장소는 어차피 모든 녹화 정보를 읽으므로 전체를 받아서 자름)
+	limit := query.Limit
+	query.Limit = 0
+	recordings, err := h.terminalHandler.recordings.List(r.Context(), query)
+	if err != nil {
+		log.Printf("녹화 목록 조회 실패: %v", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	total := len(recordings)
+	if limit > 0 && len(recordings) > limit {
+		recordings = recordings[:limit]
+	}
+
+	items := make([]RecordingInfo, 0, len(recordings))
+	for _, meta := range recordings {
+		items = append(items, h.terminalHandler.recordingInfo(meta))
+	}
+
+	response := map[string]interface{}{
+		"recordings": items,
+		"total":      total,
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Printf("JSON 인코딩 실패: %v", err)
+	}
+}
+
+// HandleGetRecording - 녹화 정보 조회 (목록과 같이 관리자이거나 녹화한 세션의 사용자만)
+func (h *TeleportHandler) HandleGetRecording(w http.ResponseWriter, r *http.Request) {
+	sessionID := mux.Vars(r)["sessionId"]
+	if h.terminalHandler.requestUser(r) == "" {
+		http.Error(w, "Authenticated user required", http.StatusUnauthorized)
+		return
+	}
+	meta, err := h.terminalHandler.recordings.Get(r.Context(), sessionID)
+	if err != nil {
+		writeRecordingError(w, sessionID, err)
+		return
+	}
+	if !h.terminalHandler.canViewRecording(r, meta) {
+		h.auditRecording(r, AuditError, sessionID, meta, map[string]interface{}{"message": "다른 사용자의 녹화 조회 거부"})
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.terminalHandler.recordingInfo(*meta))
+}
+
+// HandleDownloadRecording - asciicast 파일 그대로 전송 (asciinema 플레이어 등에서 재생)
+// recordingUrl 의 서명(?expires=&signature=)이 맞고 만료되지 않아야 함
+func (h *TeleportHandler) HandleDownloadRecording(w http.ResponseWriter, r *http.Request) {
+	sessionID := mux.Vars(r)["sessionId"]
+	if !h.terminalHandler.recordingURLs.verify(sessionID, r.URL.Query()) {
+		h.auditRecording(r, AuditError, sessionID, nil, map[string]interface{}{"message": "녹화 다운로드 주소 서명 불일치"})
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return
+	}
+
+	reader, meta, err := h.terminalHandler.recordings.Open(r.Context(), sessionID)
+	if err != nil {
+		h.auditRecordingError(r, sessionID, err)
+		writeRecordingError(w, sessionID, err)
+		return
+	}
+	defer reader.Close()
+	h.auditRecording(r, AuditFileAccessed, sessionID, meta, map[string]interface{}{"access": "download"})
+
+	w.Header().Set("Content-Type", "application/x-asciicast")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=%q", recordingFileName(meta.SessionID)+".cast"))
+	if _, err := io.Copy(w, reader); err != nil {
+		log.Printf("녹화 파일 전송 실패 (%s): %v", sessionID, err)
+		if isRecordingTampered(err) {
+			// 이미 보낸 앞부분이 온전한 녹화로 보이지 않도록 응답을 끝맺지 않고 연결을 끊음
+			h.auditRecordingError(r, sessionID, err)
+			panic(http.ErrAbortHandler)
+		}
+	}
+}
+
+// auditRecording - 녹화 접근 감사 로그 (meta 는 녹화를 열기 전이면 nil)
+// S3 에서 직접 받는 서명된 주소는 서버를 거치지 않으므로 기록되지 않음
+func (h *TeleportHandler) auditRecording(r *http.Request, eventType, sessionID string, meta *RecordingMeta, details map[string]interface{}) {
+	event := h.terminalHandler.requestClient(r).event(eventType, "recording:"+sessionID, details)
+	event.SessionID = sessionID
+	if meta != nil {
+		event.ContainerID = meta.ContainerID
+		event.Cluster = meta.Cluster
+		event.Details["recordingUser"] = meta.User
+		event.Details["encrypted"] = meta.Encryption != nil
+	}
+	h.terminalHandler.audit.Emit(event)
+}
+
+// auditRecordingError - 녹화 무결성 검증 실패 감사 로그 (없는 녹화는 기록하지 않음)
+func (h *TeleportHandler) auditRecordingError(r *http.Request, sessionID string, err error) {
+	if isRecordingTampered(err) {
+		h.auditRecording(r, AuditError, sessionID, nil, map[string]interface{}{"message": fmt.Sprintf("녹화 무결성 검증 실패: %v", err)})
+	}
+}
+
+// writeRecordingError - 녹화 조회 실패 응답 (없으면 404)
+func writeRecordingError(w http.ResponseWriter, sessionID string, err error) {
+	if isRecordingNotFound(err) {
+		http.Error(w, "Recording not found", http.StatusNotFound)
+		return
+	}
+	if isRecordingTampered(err) {
+		log.Printf("⚠️ 녹화 무결성 검증 실패, 전송하지 않음 (%s): %v", sessionID, err)
+		http.Error(w, "Recording integrity check failed", http.StatusUnprocessableEntity)
+		return
+	}
+	log.Printf("녹화 조회 실패 (%s): %v", sessionID, err)
+	http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+}
@@ -0,0 +1,254 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Heo-YJ/teleport-opensource/config"
+	"github.com/gorilla/mux"
+)
+
+func TestReadCast(t *testing.T) {
+	cast, err := readCast(strings.NewReader(`{"version":2,"width":120,"height":40}
+[0.5,"o","hello"]
+[1.25,"r","100x30"]
+not json
+[2,"o","bye"]
+[3,"o","잘린 줄`))
+	if err != nil {
+		t.Fatalf("readCast: %v", err)
+	}
+	want := []castEvent{{0.5, "o", "hello"}, {1.25, "r", "100x30"}, {2, "o", "bye"}}
+	if cast.Width != 120 || cast.Height != 40 || cast.Duration != 2 || !reflect.DeepEqual(cast.Events, want) {
+		t.Errorf("readCast = %+v", cast)
+	}
+
+	for _, data := range []string{"", `{"version":1}`, "[0.5]"} {
+		if _, err := readCast(strings.NewReader(data)); err == nil {
+			t.Errorf("readCast(%q) 에러 없음", data)
+		}
+	}
+}
+
+func TestCastCompressIdle(t *testing.T) {
+	cast := &castRecording{Events: []castEvent{{1, "o", "a"}, {10, "o", "b"}, {11, "o", "c"}, {30, "o", "d"}}}
+	cast.compressIdle(2)
+
+	var times []float64
+	for _, event := range cast.Events {
+		times = append(times, event.Time)
+	}
+	if want := []float64{1, 3, 4, 6}; !reflect.DeepEqual(times, want) || cast.Duration != 6 {
+		t.Errorf("시각 = %v, 길이 %v, want %v", times, cast.Duration, want)
+	}
+}
+
+// writeTestRecordingMeta - 녹화 정보 파일만 기록
+func writeTestRecordingMeta(t *testing.T, dir string, meta RecordingMeta) {
+	t.Helper()
+	data, _ := json.Marshal(meta)
+	if err := os.WriteFile(filepath.Join(dir, recordingFileName(meta.SessionID)+".json"), data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRecordingStoreList(t *testing.T) {
+	dir := t.TempDir()
+	base := time.Date(2025, 8, 21, 9, 0, 0, 0, time.Local)
+	for i, meta := range []RecordingMeta{
+		{SessionID: "s1", ContainerID: "web-1", User: "alice", Cluster: "prod"},
+		{SessionID: "s2", ContainerID: "web-1", User: "bob", Cluster: "prod"},
+		{SessionID: "s3", ContainerID: "db-1", User: "alice", Cluster: "staging"},
+		{SessionID: "s4", ContainerID: "web-1", User: "alice", Cluster: "prod"},
+	} {
+		meta.StartedAt = base.Add(time.Duration(i) * 24 * time.Hour)
+		writeTestRecordingMeta(t, dir, meta)
+	}
+	os.WriteFile(filepath.Join(dir, "broken.json"), []byte("{"), 0o600) // 읽을 수 없는 파일은 건너뜀
+	store := NewLocalRecordingStorage(dir, nil)
+
+	for _, tc := range []struct {
+		query string
+		want  []string
+	}{
+		{"", []string{"s4", "s3", "s2", "s1"}}, // 최근 시작 순
+		{"containerId=web-1&user=alice", []string{"s4", "s1"}},
+		{"cluster=staging", []string{"s3"}},
+		{"from=2025-08-22&to=2025-08-23", []string{"s3", "s2"}}, // to 는 그날 끝까지
+		{"limit=2", []string{"s4", "s3"}},
+		{"user=carol", []string{}},
+	} {
+		values, _ := url.ParseQuery(tc.query)
+		q, err := ParseRecordingQuery(values)
+		if err != nil {
+			t.Fatalf("ParseRecordingQuery(%q): %v", tc.query, err)
+		}
+		recordings, err := store.List(context.Background(), q)
+		if err != nil {
+			t.Fatalf("List(%q): %v", tc.query, err)
+		}
+		got := []string{}
+		for _, meta := range recordings {
+			got = append(got, meta.SessionID)
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("List(%q) = %q, want %q", tc.query, got, tc.want)
+		}
+	}
+
+	for _, query := range []string{"from=yesterday", "limit=-1"} {
+		values, _ := url.ParseQuery(query)
+		if _, err := ParseRecordingQuery(values); err == nil {
+			t.Errorf("ParseRecordingQuery(%q) 에러 없음", query)
+		}
+	}
+}
+
+func TestRecordingStoreGet(t *testing.T) {
+	dir := t.TempDir()
+	store := NewLocalRecordingStorage(dir, nil)
+	writeTestRecordingMeta(t, dir, RecordingMeta{SessionID: "a/b", StartedAt: time.Now()})
+
+	if meta, err := store.Get(context.Background(), "a/b"); err != nil || meta.SessionID != "a/b" {
+		t.Errorf("Get = %+v, %v", meta, err)
+	}
+	// 파일 이름이 같아도 세션 ID 가 다르면 없는 녹화
+	if _, err := store.Get(context.Background(), "a_b"); !isRecordingNotFound(err) {
+		t.Errorf("Get(a_b) = %v", err)
+	}
+	if _, _, err := store.Open(context.Background(), "missing"); !isRecordingNotFound(err) {
+		t.Errorf("Open(missing) = %v", err)
+	}
+}
+
+func TestRecordingURLSigner(t *testing.T) {
+	signer := newRecordingURLSigner(&config.RecordingConfig{URLSecret: "secret", URLTTL: time.Minute})
+
+	signed, err := url.Parse(signer.sign(recordingURL("a/b"), "a/b"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if signed.Path != "/api/recordings/a/b/cast" || !signer.verify("a/b", signed.Query()) {
+		t.Errorf("서명된 주소 = %s", signed)
+	}
+	// 다른 녹화, 바뀐 만료 시각, 다른 키는 거부
+	if signer.verify("a/c", signed.Query()) {
+		t.Error("다른 녹화의 서명이 통과됨")
+	}
+	query := signed.Query()
+	query.Set("expires", "9999999999")
+	if signer.verify("a/b", query) {
+		t.Error("만료 시각을 바꾼 서명이 통과됨")
+	}
+	other := newRecordingURLSigner(&config.RecordingConfig{URLTTL: time.Minute})
+	if other.verify("a/b", signed.Query()) {
+		t.Error("다른 키의 서명이 통과됨")
+	}
+
+	expired := newRecordingURLSigner(&config.RecordingConfig{URLSecret: "secret", URLTTL: -time.Minute})
+	signed, _ = url.Parse(expired.sign(recordingURL("a/b"), "a/b"))
+	if signer.verify("a/b", signed.Query()) {
+		t.Error("만료된 주소가 통과됨")
+	}
+}
+
+// newTestRecordingHandler - dir 의 녹화를 제공하는 핸들러 (httptest 요청 주소를 인증 프록시로 신뢰)
+func newTestRecordingHandler(t *testing.T, dir string, admins ...string) *TeleportHandler {
+	t.Helper()
+	_, network, _ := net.ParseCIDR("192.0.2.0/24")
+	cfg := &config.RecordingConfig{Dir: dir, URLTTL: time.Minute, Admins: admins}
+	urls := newRecordingURLSigner(cfg)
+	return &TeleportHandler{terminalHandler: &TerminalHandler{
+		recording:     cfg,
+		recordings:    NewLocalRecordingStorage(dir, urls),
+		recordingURLs: urls,
+		proxies:       trustedProxies{network},
+	}}
+}
+
+func TestRecordingAccess(t *testing.T) {
+	dir := t.TempDir()
+	for i, meta := range []RecordingMeta{
+		{SessionID: "s1", User: "alice"},
+		{SessionID: "s2", User: "bob"},
+		{SessionID: "s3", User: "alice"},
+	} {
+		meta.StartedAt = time.Now().Add(time.Duration(i) * time.Minute)
+		writeTestRecordingMeta(t, dir, meta)
+	}
+	h := newTestRecordingHandler(t, dir, "admin")
+
+	list := func(user, query string) (int, []string, int) {
+		r := httptest.NewRequest("GET", "/api/recordings?"+query, nil)
+		if user != "" {
+			r.Header.Set("X-Forwarded-User", user)
+		}
+		w := httptest.NewRecorder()
+		h.HandleGetRecordings(w, r)
+		var body struct {
+			Recordings []RecordingInfo `json:"recordings"`
+			Total      int             `json:"total"`
+		}
+		json.NewDecoder(w.Body).Decode(&body)
+		ids := []string{}
+		for _, recording := range body.Recordings {
+			ids = append(ids, recording.SessionID)
+		}
+		return w.Code, ids, body.Total
+	}
+	for _, tc := range []struct {
+		user, query string
+		status      int
+		want        []string
+		total       int
+	}{
+		{"alice", "", http.StatusOK, []string{"s3", "s1"}, 2}, // 자기 녹화만
+		{"alice", "user=alice", http.StatusOK, []string{"s3", "s1"}, 2},
+		{"alice", "user=bob", http.StatusForbidden, []string{}, 0},
+		{"admin", "", http.StatusOK, []string{"s3", "s2", "s1"}, 3},
+		{"admin", "user=bob", http.StatusOK, []string{"s2"}, 1},
+		{"admin", "limit=2", http.StatusOK, []string{"s3", "s2"}, 3}, // total 은 자르기 전 개수
+		{"alice", "limit=1", http.StatusOK, []string{"s3"}, 2},
+		{"", "", http.StatusUnauthorized, []string{}, 0},
+	} {
+		status, got, total := list(tc.user, tc.query)
+		if status != tc.status || !reflect.DeepEqual(got, tc.want) || total != tc.total {
+			t.Errorf("%s %q: 목록 = %d %q (total %d), want %d %q (total %d)", tc.user, tc.query, status, got, total, tc.status, tc.want, tc.total)
+		}
+	}
+
+	get := func(user, sessionID string) int {
+		r := httptest.NewRequest("GET", "/api/recordings/"+sessionID, nil)
+		r = mux.SetURLVars(r, map[string]string{"sessionId": sessionID})
+		if user != "" {
+			r.Header.Set("X-Forwarded-User", user)
+		}
+		w := httptest.NewRecorder()
+		h.HandleGetRecording(w, r)
+		return w.Code
+	}
+	for _, tc := range []struct {
+		user, sessionID string
+		status          int
+	}{
+		{"alice", "s1", http.StatusOK},
+		{"alice", "s2", http.StatusForbidden},
+		{"admin", "s2", http.StatusOK},
+		{"", "s1", http.StatusUnauthorized},
+		{"alice", "missing", http.StatusNotFound},
+	} {
+		if status := get(tc.user, tc.sessionID); status != tc.status {
+			t.Errorf("%s %s: 조회 = %d, want %d", tc.user, tc.sessionID, status, tc.status)
+		}
+	}
+}
@@ -0,0 +1,255 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Heo-YJ/teleport-opensource/config"
+)
+
+// S3RecordingStorage - S3 호환 녹화 저장소 (<접두어><세션 ID>.cast / .json 객체)
+// 세션이 끝나면 .cast 를 멀티파트 업로드하고 .json 을 올린 뒤 로컬 파일 삭제
+// 녹화 중이거나 아직 업로드하지 못한 녹화는 로컬 디렉터리(spool)에서 조회
+type S3RecordingStorage struct {
+	client   *s3Client
+	prefix   string
+	partSize int
+	urlTTL   time.Duration
+	spool    *LocalRecordingStorage
+
+	mu    sync.Mutex
+	metas map[string]RecordingMeta // .json 객체 키 → 녹화 정보 (UpdateMeta 외에는 바뀌지 않으므로 캐시)
+}
+
+// NewS3RecordingStorage - S3 호환 녹화 저장소 생성
+func NewS3RecordingStorage(cfg *config.RecordingConfig, spool *LocalRecordingStorage) (*S3RecordingStorage, error) {
+	client, err := newS3Client(cfg.S3)
+	if err != nil {
+		return nil, err
+	}
+	return &S3RecordingStorage{
+		client:   client,
+		prefix:   cfg.S3.Prefix,
+		partSize: cfg.S3.PartSize,
+		urlTTL:   cfg.URLTTL,
+		spool:    spool,
+		metas:    make(map[string]RecordingMeta),
+	}, nil
+}
+
+// Save - 녹화 파일 업로드 후 로컬 파일 삭제 (실패하면 로컬에 남겨 두고 다음 실행 때 다시 업로드)
+func (s *S3RecordingStorage) Save(ctx context.Context, meta RecordingMeta, castPath, metaPath string) error {
+	file, err := os.Open(castPath)
+	if err != nil {
+		return fmt.Errorf("녹화 파일 열기 실패: %v", err)
+	}
+	defer file.Close()
+
+	if err := s.client.uploadMultipart(ctx, s.key(meta.SessionID, ".cast"), file, s.partSize, "application/x-asciicast"); err != nil {
+		return fmt.Errorf("녹화 파일 업로드 실패: %v", err)
+	}
+	// 목록은 .json 객체로 만들므로 녹화 파일을 다 올린 뒤 업로드
+	data, err := json.MarshalIndent(meta, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := s.client.putObject(ctx, s.key(meta.SessionID, ".json"), data, "application/json"); err != nil {
+		return fmt.Errorf("녹화 정보 업로드 실패: %v", err)
+	}
+
+	file.Close()
+	os.Remove(castPath)
+	os.Remove(metaPath)
+	log.Printf("☁️ 녹화 업로드 완료: %s (%d 바이트)", meta.SessionID, meta.Size)
+	return nil
+}
+
+// List - 조건에 맞는 녹화 목록 (업로드한 녹화 + 로컬의 녹화 중/업로드 대기 녹화)
+func (s *S3RecordingStorage) List(ctx context.Context, q *RecordingQuery) ([]RecordingMeta, error) {
+	local := *q
+	local.Limit = 0
+	recordings, err := s.spool.List(ctx, &local)
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool, len(recordings))
+	for _, meta := range recordings {
+		seen[meta.SessionID] = true
+	}
+
+	objects, err := s.client.listObjects(ctx, s.prefix)
+	if err != nil {
+		return nil, fmt.Errorf("S3 녹화 목록 조회 실패: %v", err)
+	}
+	keys := make(map[string]bool, len(objects))
+	for _, object := range objects {
+		name := strings.TrimPrefix(object.Key, s.prefix)
+		if strings.Contains(name, "/") || !strings.HasSuffix(name, ".json") {
+			continue
+		}
+		keys[object.Key] = true
+
+		meta, err := s.readMeta(ctx, object.Key)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, err
+			}
+			log.Printf("녹화 정보 읽기 실패 (%s): %v", object.Key, err)
+			continue
+		}
+		if !seen[meta.SessionID] && q.Matches(meta) {
+			recordings = append(recordings, *meta)
+		}
+	}
+
+	// 삭제된 객체는 캐시에서도 제거
+	s.mu.Lock()
+	for key := range s.metas {
+		if !keys[key] {
+			delete(s.metas, key)
+		}
+	}
+	s.mu.Unlock()
+
+	return sortRecordings(recordings, q.Limit), nil
+}
+
+// Get - 세션 ID 로 녹화 정보 조회 (로컬에 있으면 로컬 우선)
+func (s *S3RecordingStorage) Get(ctx context.Context, sessionID string) (*RecordingMeta, error) {
+	if meta, err := s.spool.Get(ctx, sessionID); err == nil || !isRecordingNotFound(err) {
+		return meta, err
+	}
+
+	meta, err := s.readMeta(ctx, s.key(sessionID, ".json"))
+	if err != nil {
+		return nil, err
+	}
+	// 파일 이름으로 바꾸면서 겹칠 수 있으므로 원래 ID 확인
+	if meta.SessionID != sessionID {
+		return nil, os.ErrNotExist
+	}
+	return meta, nil
+}
+
+// Open - 녹화 파일 열기 (로컬에 있으면 로컬 파일, 없으면 S3 객체 스트림)
+func (s *S3RecordingStorage) Open(ctx context.Context, sessionID string) (io.ReadCloser, *RecordingMeta, error) {
+	if reader, meta, err := s.spool.Open(ctx, sessionID); err == nil || !isRecordingNotFound(err) {
+		return reader, meta, err
+	}
+
+	meta, err := s.Get(ctx, sessionID)
+	if err != nil {
+		return nil, nil, err
+	}
+	reader, err := s.client.getObject(ctx, s.key(sessionID, ".cast"))
+	if err != nil {
+		return nil, nil, err
+	}
+	return reader, meta, nil
+}
+
+// UpdateMeta - 녹화 정보 다시 저장 (아직 로컬에 있으면 로컬 파일)
+func (s *S3RecordingStorage) UpdateMeta(ctx context.Context, meta RecordingMeta) error {
+	if s.spool.exists(meta.SessionID) {
+		return s.spool.UpdateMeta(ctx, meta)
+	}
+	data, err := json.MarshalIndent(meta, "", "  ")
+	if err != nil {
+		return err
+	}
+	key := s.key(meta.SessionID, ".json")
+	if err := s.client.putObject(ctx, key, data, "application/json"); err != nil {
+		return fmt.Errorf("녹화 정보 업로드 실패: %v", err)
+	}
+	s.mu.Lock()
+	s.metas[key] = meta
+	s.mu.Unlock()
+	return nil
+}
+
+// Delete - S3 객체와 로컬 파일 삭제 (목록에서 먼저 빠지도록 .json 부터)
+func (s *S3RecordingStorage) Delete(ctx context.Context, sessionID string) error {
+	for _, ext := range []string{".json", ".cast"} {
+		if err := s.client.deleteObject(ctx, s.key(sessionID, ext)); err != nil {
+			return err
+		}
+	}
+	s.mu.Lock()
+	delete(s.metas, s.key(sessionID, ".json"))
+	s.mu.Unlock()
+	return s.spool.Delete(ctx, sessionID)
+}
+
+// DownloadURL - 서명된 S3 주소 (아직 로컬에 있으면 서명된 API 주소)
+func (s *S3RecordingStorage) DownloadURL(sessionID string) string {
+	if s.spool.exists(sessionID) {
+		return s.spool.DownloadURL(sessionID)
+	}
+	return s.client.presign(s.key(sessionID, ".cast"), s.urlTTL)
+}
+
+// uploadPending - 로컬 디렉터리에 남은 녹화 업로드 (시작할 때 한 번, 녹화 중인 세션이 없을 때)
+func (s *S3RecordingStorage) uploadPending() {
+	ctx := context.Background()
+	recordings, err := s.spool.List(ctx, &RecordingQuery{})
+	if err != nil {
+		log.Printf("업로드 대기 녹화 조회 실패: %v", err)
+		return
+	}
+
+	for _, meta := range recordings {
+		// 서버가 녹화 중에 종료된 경우 (기록된 부분까지 업로드, 끝부분이 없으므로 잘린 녹화)
+		if meta.EndedAt == nil {
+			endedAt := meta.StartedAt.Add(time.Duration(meta.Duration * float64(time.Second)))
+			meta.EndedAt = &endedAt
+			meta.Truncated = true
+		}
+		uploadCtx, cancel := context.WithTimeout(ctx, recordingUploadTimeout)
+		err := s.Save(uploadCtx, meta, s.spool.path(meta.SessionID, ".cast"), s.spool.path(meta.SessionID, ".json"))
+		cancel()
+		if err != nil {
+			log.Printf("녹화 업로드 실패 (%s): %v", meta.SessionID, err)
+		}
+	}
+}
+
+// readMeta - .json 객체 읽기 (캐시 사용)
+func (s *S3RecordingStorage) readMeta(ctx context.Context, key string) (*RecordingMeta, error) {
+	s.mu.Lock()
+	cached, ok := s.metas[key]
+	s.mu.Unlock()
+	if ok {
+		return &cached, nil
+	}
+
+	reader, err := s.client.getObject(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	data, err := io.ReadAll(io.LimitReader(reader, 1024*1024))
+	if err != nil {
+		return nil, err
+	}
+	meta, err := parseRecordingMeta(data)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	s.metas[key] = *meta
+	s.mu.Unlock()
+	return meta, nil
+}
+
+// key - 객체 키 (ext: .cast / .json)
+func (s *S3RecordingStorage) key(sessionID, ext string) string {
+	return s.prefix + recordingFileName(sessionID) + ext
+}
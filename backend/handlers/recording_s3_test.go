@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"context"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Heo-YJ/teleport-opensource/config"
+)
+
+// newTestS3RecordingStorage - fakeS3 와 임시 로컬 디렉터리(spool)를 쓰는 S3 녹화 저장소
+func newTestS3RecordingStorage(t *testing.T, s *fakeS3) *S3RecordingStorage {
+	t.Helper()
+	cfg := &config.RecordingConfig{Dir: t.TempDir(), URLTTL: time.Minute, S3: s.config()}
+	cfg.S3.Prefix = "rec/"
+	cfg.S3.PartSize = 16
+	spool := NewLocalRecordingStorage(cfg.Dir, newRecordingURLSigner(cfg))
+	storage, err := NewS3RecordingStorage(cfg, spool)
+	if err != nil {
+		t.Fatalf("NewS3RecordingStorage: %v", err)
+	}
+	return storage
+}
+
+// spoolRecording - 로컬 디렉터리에 녹화 파일 만들기 (끝난 녹화면 endedAt 지정)
+func spoolRecording(t *testing.T, storage *S3RecordingStorage, sessionID, cast string, startedAt time.Time, endedAt *time.Time) RecordingMeta {
+	t.Helper()
+	meta := RecordingMeta{SessionID: sessionID, ContainerID: "web-1", User: "alice", StartedAt: startedAt, EndedAt: endedAt, Size: int64(len(cast))}
+	if err := os.WriteFile(storage.spool.path(sessionID, ".cast"), []byte(cast), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeRecordingMeta(storage.spool.path(sessionID, ".json"), meta); err != nil {
+		t.Fatal(err)
+	}
+	return meta
+}
+
+func TestS3RecordingStorageSaveAndOpen(t *testing.T) {
+	s := newFakeS3(t)
+	storage := newTestS3RecordingStorage(t, s)
+	ctx := context.Background()
+
+	ended := time.Now()
+	cast := strings.Repeat("[0.1, \"o\", \"hello\"]\n", 4) // 파트 여러 개
+	meta := spoolRecording(t, storage, "session-1", cast, ended.Add(-time.Minute), &ended)
+
+	if err := storage.Save(ctx, meta, storage.spool.path("session-1", ".cast"), storage.spool.path("session-1", ".json")); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	if data, _ := s.object("rec/session-1.cast"); string(data) != cast {
+		t.Errorf("업로드된 녹화 = %q", data)
+	}
+	if storage.spool.exists("session-1") {
+		t.Error("업로드한 로컬 파일이 남아 있음")
+	}
+
+	// 업로드한 녹화는 S3 에서 조회, 주소는 S3 서명 주소
+	reader, got, err := storage.Open(ctx, "session-1")
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	data, _ := io.ReadAll(reader)
+	reader.Close()
+	if string(data) != cast || got.User != "alice" {
+		t.Errorf("Open = %q, %+v", data, got)
+	}
+	if url := storage.DownloadURL("session-1"); !strings.HasPrefix(url, s.server.URL+"/recordings/rec/session-1.cast?") || !strings.Contains(url, "X-Amz-Signature=") {
+		t.Errorf("DownloadURL = %s", url)
+	}
+}
+
+func TestS3RecordingRetention(t *testing.T) {
+	s := newFakeS3(t)
+	storage := newTestS3RecordingStorage(t, s)
+	ctx := context.Background()
+	now := time.Now()
+
+	save := func(sessionID string, startedAt, endedAt time.Time) {
+		meta := spoolRecording(t, storage, sessionID, "cast", startedAt, &endedAt)
+		if err := storage.Save(ctx, meta, storage.spool.path(sessionID, ".cast"), storage.spool.path(sessionID, ".json")); err != nil {
+			t.Fatalf("Save: %v", err)
+		}
+	}
+	save("old", now.Add(-72*time.Hour), now.Add(-71*time.Hour))
+	save("recent", now.Add(-time.Hour), now.Add(-30*time.Minute))
+	// 보관 기간 전에 시작했지만 최근에 끝난 녹화, 아직 녹화 중인 녹화
+	save("long", now.Add(-72*time.Hour), now.Add(-time.Minute))
+	spoolRecording(t, storage, "live", "cast", now.Add(-72*time.Hour), nil)
+
+	deleteExpiredRecordings(storage, now.Add(-24*time.Hour))
+
+	if keys := strings.Join(s.keys(), ","); keys != "rec/long.cast,rec/long.json,rec/recent.cast,rec/recent.json" {
+		t.Errorf("남은 객체 = %s", keys)
+	}
+	if !storage.spool.exists("live") {
+		t.Error("녹화 중인 녹화가 삭제됨")
+	}
+
+	recordings, err := storage.List(ctx, &RecordingQuery{})
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	var ids []string
+	for _, meta := range recordings {
+		ids = append(ids, meta.SessionID)
+	}
+	if strings.Join(ids, ",") != "recent,long,live" && strings.Join(ids, ",") != "recent,live,long" {
+		t.Errorf("목록 = %v", ids)
+	}
+	if _, err := storage.Get(ctx, "old"); !isRecordingNotFound(err) {
+		t.Errorf("삭제한 녹화 조회 에러 = %v", err)
+	}
+}
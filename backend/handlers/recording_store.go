@@ -0,0 +1,409 @@
+package handlers
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/Heo-YJ/teleport-opensource/config"
+)
+
+// RecordingStorage - 녹화 저장소 (로컬 디렉터리 / S3 호환)
+// 녹화 중에는 항상 RECORDING_DIR 에 기록하고, 세션이 끝나면 Save 로 저장소에 넘김
+// 찾는 녹화가 없으면 os.ErrNotExist 를 감싼 에러 반환
+type RecordingStorage interface {
+	// Save - 끝난 녹화(RECORDING_DIR 의 .cast / .json 파일)를 저장소에 보관
+	Save(ctx context.Context, meta RecordingMeta, castPath, metaPath string) error
+	// List - 조건에 맞는 녹화 목록 (최근 시작 순, Limit 이 0 이면 전체)
+	List(ctx context.Context, q *RecordingQuery) ([]RecordingMeta, error)
+	// Get - 세션 ID 로 녹화 정보 조회
+	Get(ctx context.Context, sessionID string) (*RecordingMeta, error)
+	// Open - 녹화 파일 열기
+	Open(ctx context.Context, sessionID string) (io.ReadCloser, *RecordingMeta, error)
+	// UpdateMeta - 녹화 정보 갱신 (데이터 키를 새 마스터 키로 다시 감쌀 때, 녹화 중인 세션은 Recorder 가 저장)
+	UpdateMeta(ctx context.Context, meta RecordingMeta) error
+	// Delete - 녹화 삭제 (없어도 성공)
+	Delete(ctx context.Context, sessionID string) error
+	// DownloadURL - 서명된 녹화 파일 다운로드 주소 (RECORDING_URL_TTL 동안 유효)
+	DownloadURL(sessionID string) string
+}
+
+// recordingRetentionInterval - 보관 기간이 지난 녹화를 확인하는 최대 간격
+const recordingRetentionInterval = time.Hour
+
+// LocalRecordingStorage - 로컬 디렉터리 녹화 저장소 (RECORDING_DIR 의 <세션 ID>.cast / .json)
+type LocalRecordingStorage struct {
+	dir  string
+	urls *recordingURLSigner
+}
+
+// recordingURLSigner - 녹화 다운로드 주소 서명 (HMAC-SHA256, 만료 시각 포함)
+type recordingURLSigner struct {
+	secret []byte
+	ttl    time.Duration
+}
+
+// RecordingInfo - 녹화 목록 항목 (녹화 정보 + 다운로드/재생 주소)
+type RecordingInfo struct {
+	RecordingMeta
+	RecordingURL string `json:"recordingUrl"` // asciicast 파일 (서명된 주소, asciinema 플레이어로 재생 가능)
+	PlaybackURL  string `json:"playbackUrl"`  // 재생 WebSocket (서명된 주소, output / resize 메시지)
+	Encrypted    bool   `json:"encrypted"`    // 저장소에 암호화되어 있음 (위 주소로 받으면 복호화됨)
+}
+
+// RecordingQuery - /api/recordings 조회 조건
+type RecordingQuery struct {
+	ContainerID string    // ?containerId=
+	User        string    // ?user=
+	Cluster     string    // ?cluster=
+	From        time.Time // ?from=2025-08-21 또는 RFC3339 (녹화 시작 시각 기준, 포함)
+	To          time.Time // ?to= (포함하지 않음, 날짜만 쓰면 그날 끝까지 포함)
+	Limit       int       // ?limit= (기본 50, 최대 500)
+}
+
+// newRecordingStorage - 설정에 맞는 녹화 저장소 생성 (S3 설정이 잘못되면 로컬 디렉터리 사용)
+func newRecordingStorage(cfg *config.RecordingConfig, urls *recordingURLSigner) RecordingStorage {
+	local := NewLocalRecordingStorage(cfg.Dir, urls)
+
+	switch cfg.Storage {
+	case config.RecordingStorageLocal:
+		return local
+	case config.RecordingStorageS3:
+		storage, err := NewS3RecordingStorage(cfg, local)
+		if err != nil {
+			log.Printf("⚠️ S3 녹화 저장소 설정 실패: %v, 로컬 디렉터리 사용", err)
+			return local
+		}
+		log.Printf("☁️ S3 녹화 저장소 사용: %s/%s", cfg.S3.Endpoint, cfg.S3.Bucket)
+		// 이전 실행에서 업로드하지 못한 녹화
+		go storage.uploadPending()
+		return storage
+	default:
+		log.Printf("⚠️ 알 수 없는 녹화 저장소 %q, %s 사용", cfg.Storage, config.RecordingStorageLocal)
+		return local
+	}
+}
+
+// NewLocalRecordingStorage - 로컬 디렉터리 녹화 저장소 생성
+func NewLocalRecordingStorage(dir string, urls *recordingURLSigner) *LocalRecordingStorage {
+	return &LocalRecordingStorage{dir: dir, urls: urls}
+}
+
+// ParseRecordingQuery - URL 쿼리에서 조회 조건 파싱
+func ParseRecordingQuery(values url.Values) (*RecordingQuery, error) {
+	q := &RecordingQuery{
+		ContainerID: values.Get("containerId"),
+		User:        values.Get("user"),
+		Cluster:     values.Get("cluster"),
+	}
+
+	var err error
+	if q.From, err = parseQueryTime(values, "from", false); err != nil {
+		return nil, err
+	}
+	if q.To, err = parseQueryTime(values, "to", true); err != nil {
+		return nil, err
+	}
+	if q.Limit, err = parsePositiveInt(values, "limit"); err != nil {
+		return nil, err
+	}
+	if q.Limit == 0 {
+		q.Limit = defaultPageSize
+	}
+	if q.Limit > maxPageSize {
+		q.Limit = maxPageSize
+	}
+	return q, nil
+}
+
+// parseQueryTime - 날짜(2006-01-02, 서버 시간대) 또는 RFC3339 시각 파싱
+// 날짜만 쓴 경우 endOfDay 이면 다음 날 0시 (그날 전체 포함)
+func parseQueryTime(values url.Values, key string, endOfDay bool) (time.Time, error) {
+	value := values.Get(key)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	day, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("잘못된 날짜 형식: %s=%q (2006-01-02 또는 RFC3339)", key, value)
+	}
+	if endOfDay {
+		day = day.AddDate(0, 0, 1)
+	}
+	return day, nil
+}
+
+// Matches - 녹화가 조건을 만족하는지 확인
+func (q *RecordingQuery) Matches(meta *RecordingMeta) bool {
+	switch {
+	case q.ContainerID != "" && meta.ContainerID != q.ContainerID:
+		return false
+	case q.User != "" && meta.User != q.User:
+		return false
+	case q.Cluster != "" && meta.Cluster != q.Cluster:
+		return false
+	case !q.From.IsZero() && meta.StartedAt.Before(q.From):
+		return false
+	case !q.To.IsZero() && !meta.StartedAt.Before(q.To):
+		return false
+	}
+	return true
+}
+
+// Save - 녹화한 디렉터리가 곧 저장소이므로 할 일 없음
+func (s *LocalRecordingStorage) Save(ctx context.Context, meta RecordingMeta, castPath, metaPath string) error {
+	return nil
+}
+
+// List - 조건에 맞는 녹화 목록 (최근 시작 순, 녹화 중인 세션 포함)
+func (s *LocalRecordingStorage) List(ctx context.Context, q *RecordingQuery) ([]RecordingMeta, error) {
+	entries, err := os.ReadDir(s.dir)
+	if os.IsNotExist(err) {
+		return []RecordingMeta{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("녹화 디렉터리 조회 실패: %v", err)
+	}
+
+	recordings := make([]RecordingMeta, 0)
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		meta, err := s.readMeta(filepath.Join(s.dir, entry.Name()))
+		if err != nil {
+			log.Printf("녹화 정보 읽기 실패 (%s): %v", entry.Name(), err)
+			continue
+		}
+		if q.Matches(meta) {
+			recordings = append(recordings, *meta)
+		}
+	}
+
+	return sortRecordings(recordings, q.Limit), nil
+}
+
+// Get - 세션 ID 로 녹화 정보 조회 (없으면 os.ErrNotExist)
+func (s *LocalRecordingStorage) Get(ctx context.Context, sessionID string) (*RecordingMeta, error) {
+	meta, err := s.readMeta(s.path(sessionID, ".json"))
+	if err != nil {
+		return nil, err
+	}
+	// 파일 이름으로 바꾸면서 겹칠 수 있으므로 원래 ID 확인
+	if meta.SessionID != sessionID {
+		return nil, os.ErrNotExist
+	}
+	return meta, nil
+}
+
+// Open - 녹화 파일 열기 (녹화 중이면 지금까지 기록된 부분)
+func (s *LocalRecordingStorage) Open(ctx context.Context, sessionID string) (io.ReadCloser, *RecordingMeta, error) {
+	meta, err := s.Get(ctx, sessionID)
+	if err != nil {
+		return nil, nil, err
+	}
+	file, err := os.Open(s.path(sessionID, ".cast"))
+	if err != nil {
+		return nil, nil, err
+	}
+	return file, meta, nil
+}
+
+// UpdateMeta - 녹화 정보 파일 다시 저장
+func (s *LocalRecordingStorage) UpdateMeta(ctx context.Context, meta RecordingMeta) error {
+	return writeRecordingMeta(s.path(meta.SessionID, ".json"), meta)
+}
+
+// Delete - 녹화 파일과 정보 파일 삭제
+func (s *LocalRecordingStorage) Delete(ctx context.Context, sessionID string) error {
+	for _, ext := range []string{".cast", ".json"} {
+		if err := os.Remove(s.path(sessionID, ext)); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("녹화 파일 삭제 실패: %v", err)
+		}
+	}
+	return nil
+}
+
+// DownloadURL - 서명된 API 다운로드 주소
+func (s *LocalRecordingStorage) DownloadURL(sessionID string) string {
+	return s.urls.sign(recordingURL(sessionID), sessionID)
+}
+
+// exists - 녹화 파일이 디렉터리에 있는지 확인
+func (s *LocalRecordingStorage) exists(sessionID string) bool {
+	_, err := os.Stat(s.path(sessionID, ".cast"))
+	return err == nil
+}
+
+// path - 녹화 파일 경로 (ext: .cast / .json)
+func (s *LocalRecordingStorage) path(sessionID, ext string) string {
+	return filepath.Join(s.dir, recordingFileName(sessionID)+ext)
+}
+
+// readMeta - 녹화 정보 파일 읽기
+func (s *LocalRecordingStorage) readMeta(path string) (*RecordingMeta, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return parseRecordingMeta(data)
+}
+
+// parseRecordingMeta - 녹화 정보 JSON 파싱
+func parseRecordingMeta(data []byte) (*RecordingMeta, error) {
+	var meta RecordingMeta
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return nil, err
+	}
+	return &meta, nil
+}
+
+// sortRecordings - 최근 시작 순으로 정렬하고 limit 개까지 자름 (0 이면 전체)
+func sortRecordings(recordings []RecordingMeta, limit int) []RecordingMeta {
+	sort.Slice(recordings, func(i, j int) bool {
+		return recordings[i].StartedAt.After(recordings[j].StartedAt)
+	})
+	if limit > 0 && len(recordings) > limit {
+		recordings = recordings[:limit]
+	}
+	return recordings
+}
+
+// runRecordingRetention - 끝난 지 보관 기간이 지난 녹화를 주기적으로 삭제
+func runRecordingRetention(storage RecordingStorage, retention time.Duration) {
+	ticker := time.NewTicker(min(retention, recordingRetentionInterval))
+	defer ticker.Stop()
+
+	for {
+		deleteExpiredRecordings(storage, time.Now().Add(-retention))
+		<-ticker.C
+	}
+}
+
+// deleteExpiredRecordings - cutoff 전에 끝난 녹화 삭제 (녹화 중인 세션은 제외)
+func deleteExpiredRecordings(storage RecordingStorage, cutoff time.Time) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	// 시작 시각이 cutoff 이후면 끝난 시각도 이후
+	recordings, err := storage.List(ctx, &RecordingQuery{To: cutoff})
+	if err != nil {
+		log.Printf("보관 기간이 지난 녹화 조회 실패: %v", err)
+		return
+	}
+
+	deleted := 0
+	for _, meta := range recordings {
+		if meta.EndedAt == nil || !meta.EndedAt.Before(cutoff) {
+			continue
+		}
+		if err := storage.Delete(ctx, meta.SessionID); err != nil {
+			log.Printf("녹화 삭제 실패 (%s): %v", meta.SessionID, err)
+			continue
+		}
+		deleted++
+	}
+	if deleted > 0 {
+		log.Printf("🗑️ 보관 기간이 지난 녹화 %d개 삭제", deleted)
+	}
+}
+
+// newRecordingURLSigner - 다운로드 주소 서명 키 준비 (설정이 없으면 임의 생성, 재시작하면 이전 주소는 무효)
+func newRecordingURLSigner(cfg *config.RecordingConfig) *recordingURLSigner {
+	secret := []byte(cfg.URLSecret)
+	if len(secret) == 0 {
+		secret = make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			log.Printf("⚠️ 녹화 주소 서명 키 생성 실패: %v", err)
+		}
+	}
+	return &recordingURLSigner{secret: secret, ttl: cfg.URLTTL}
+}
+
+// sign - 녹화 API 주소(다운로드 / 재생)에 만료 시각과 서명을 붙임
+func (s *recordingURLSigner) sign(base, sessionID string) string {
+	expires := strconv.FormatInt(time.Now().Add(s.ttl).Unix(), 10)
+	return base + "?expires=" + expires + "&signature=" + s.signature(sessionID, expires)
+}
+
+// verify - 다운로드 / 재생 요청의 서명과 만료 시각 확인
+func (s *recordingURLSigner) verify(sessionID string, query url.Values) bool {
+	expires, err := strconv.ParseInt(query.Get("expires"), 10, 64)
+	if err != nil || time.Now().Unix() > expires {
+		return false
+	}
+	expected := s.signature(sessionID, query.Get("expires"))
+	return hmac.Equal([]byte(query.Get("signature")), []byte(expected))
+}
+
+// signature - 세션 ID 와 만료 시각의 HMAC
+func (s *recordingURLSigner) signature(sessionID, expires string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(sessionID + "\n" + expires))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// recordingViewer - 녹화를 조회하는 사용자 (인증 프록시가 확인한 사용자만, 없으면 빈 문자열)
+// all 이면 RECORDING_ADMINS 에 있어 모든 녹화를 볼 수 있음
+func (t *TerminalHandler) recordingViewer(r *http.Request) (user string, all bool) {
+	user = t.requestUser(r)
+	if user == "" {
+		return "", false
+	}
+	for _, admin := range t.recording.Admins {
+		if admin == user {
+			return user, true
+		}
+	}
+	return user, false
+}
+
+// canViewRecording - 녹화를 볼 수 있는지 확인 (관리자이거나 녹화한 세션의 사용자)
+func (t *TerminalHandler) canViewRecording(r *http.Request, meta *RecordingMeta) bool {
+	user, all := t.recordingViewer(r)
+	return all || (user != "" && user == meta.User)
+}
+
+// recordingInfo - 목록 응답 항목 (감싼 데이터 키는 내보내지 않음)
+func (t *TerminalHandler) recordingInfo(meta RecordingMeta) RecordingInfo {
+	encrypted := meta.Encryption != nil
+	meta.Encryption = nil
+	return RecordingInfo{
+		RecordingMeta: meta,
+		RecordingURL:  t.recordings.DownloadURL(meta.SessionID),
+		PlaybackURL:   t.recordingURLs.sign("/api/ws/recordings/"+url.PathEscape(meta.SessionID), meta.SessionID),
+		Encrypted:     encrypted,
+	}
+}
+
+// recordingURL - 녹화 파일 다운로드 주소 (서명 제외)
+func recordingURL(sessionID string) string {
+	return "/api/recordings/" + url.PathEscape(sessionID) + "/cast"
+}
+
+// isRecordingNotFound - 녹화가 없어서 실패했는지 확인
+func isRecordingNotFound(err error) bool {
+	return errors.Is(err, os.ErrNotExist)
+}
+
+// isRecordingTampered - 암호화된 녹화의 인증 태그 검증에 실패했는지 확인
+func isRecordingTampered(err error) bool {
+	return errors.Is(err, errRecordingTampered)
+}
@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Heo-YJ/teleport-opensource/config"
+)
+
+// readRecording - 녹화 파일의 헤더와 이벤트 (종류, 데이터)
+func readRecording(t *testing.T, path string) (header map[string]interface{}, events [][2]string) {
+	t.Helper()
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if header == nil {
+			if err := json.Unmarshal(scanner.Bytes(), &header); err != nil {
+				t.Fatalf("헤더 파싱 실패: %v", err)
+			}
+			continue
+		}
+		var event []interface{}
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil || len(event) != 3 {
+			t.Fatalf("이벤트 파싱 실패: %s (%v)", scanner.Bytes(), err)
+		}
+		events = append(events, [2]string{event[1].(string), event[2].(string)})
+	}
+	return header, events
+}
+
+func readRecordingMeta(t *testing.T, path string) RecordingMeta {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var meta RecordingMeta
+	if err := json.Unmarshal(data, &meta); err != nil {
+		t.Fatal(err)
+	}
+	return meta
+}
+
+func TestRecorderAsciicast(t *testing.T) {
+	for _, policy := range []string{config.RecordingOutput, config.RecordingAll} {
+		t.Run(policy, func(t *testing.T) {
+			cfg := &config.RecordingConfig{Policy: policy, Dir: t.TempDir(), MaxSize: 1024 * 1024}
+			r, err := NewRecorder(cfg, NewLocalRecordingStorage(cfg.Dir, nil), nil, RecordingMeta{
+				SessionID: "session/1", ContainerID: "web-1", Container: "web", User: "alice", StartedAt: time.Now(),
+			}, 120, 40, "xterm-256color")
+			if err != nil {
+				t.Fatalf("NewRecorder: %v", err)
+			}
+
+			r.Output([]byte("hello \xed\x95")) // 잘린 "한" 은 다음 출력과 합쳐서 기록
+			r.Input([]byte("ls\r"))
+			r.Output([]byte("\x9c"))
+			r.Resize(100, 30)
+			if err := r.Close(); err != nil {
+				t.Fatalf("Close: %v", err)
+			}
+			r.Close()
+
+			// 세션 ID 의 경로 구분자는 파일 이름에서 _ 로
+			header, events := readRecording(t, filepath.Join(cfg.Dir, "session_1.cast"))
+			if header["version"] != float64(2) || header["width"] != float64(120) || header["height"] != float64(40) || header["title"] != "web" {
+				t.Errorf("헤더 = %v", header)
+			}
+			want := [][2]string{{"o", "hello "}, {"i", "ls\r"}, {"o", "한"}, {"r", "100x30"}}
+			if policy == config.RecordingOutput {
+				want = [][2]string{{"o", "hello "}, {"o", "한"}, {"r", "100x30"}}
+			}
+			if !reflect.DeepEqual(events, want) {
+				t.Errorf("이벤트 = %q, want %q", events, want)
+			}
+
+			meta := readRecordingMeta(t, filepath.Join(cfg.Dir, "session_1.json"))
+			info, _ := os.Stat(filepath.Join(cfg.Dir, "session_1.cast"))
+			if meta.EndedAt == nil || meta.Size != info.Size() || meta.Truncated || meta.Input != (policy == config.RecordingAll) {
+				t.Errorf("녹화 정보 = %+v (파일 크기 %d)", meta, info.Size())
+			}
+		})
+	}
+}
+
+func TestRecorderSizeLimit(t *testing.T) {
+	cfg := &config.RecordingConfig{Policy: config.RecordingOutput, Dir: t.TempDir(), MaxSize: 200}
+	r, err := NewRecorder(cfg, NewLocalRecordingStorage(cfg.Dir, nil), nil, RecordingMeta{SessionID: "s1", StartedAt: time.Now()}, 80, 24, "xterm")
+	if err != nil {
+		t.Fatalf("NewRecorder: %v", err)
+	}
+	for i := 0; i < 10; i++ {
+		r.Output([]byte("0123456789"))
+	}
+	if err := r.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	// 제한을 넘는 이벤트 대신 표시 이벤트 하나를 남기고 이후는 무시
+	_, events := readRecording(t, filepath.Join(cfg.Dir, "s1.cast"))
+	if len(events) < 2 || events[len(events)-1] != [2]string{"m", "녹화 크기 제한 초과"} {
+		t.Fatalf("이벤트 = %q", events)
+	}
+	for _, event := range events[:len(events)-1] {
+		if event != [2]string{"o", "0123456789"} {
+			t.Errorf("잘림 전 이벤트 = %q", event)
+		}
+	}
+	if meta := readRecordingMeta(t, filepath.Join(cfg.Dir, "s1.json")); !meta.Truncated {
+		t.Errorf("녹화 정보 = %+v", meta)
+	}
+}
+
+func TestRecorderDiscard(t *testing.T) {
+	cfg := &config.RecordingConfig{Policy: config.RecordingOutput, Dir: t.TempDir(), MaxSize: 1024}
+	r, err := NewRecorder(cfg, NewLocalRecordingStorage(cfg.Dir, nil), nil, RecordingMeta{SessionID: "s1", StartedAt: time.Now()}, 80, 24, "xterm")
+	if err != nil {
+		t.Fatalf("NewRecorder: %v", err)
+	}
+	r.Output([]byte("secret"))
+	r.Discard()
+
+	if entries, _ := os.ReadDir(cfg.Dir); len(entries) != 0 {
+		t.Errorf("취소한 녹화 파일이 남음: %v", entries)
+	}
+	// nil Recorder 는 녹화하지 않음
+	var none *Recorder
+	none.Output([]byte("x"))
+	if err := none.Close(); err != nil {
+		t.Errorf("nil Close: %v", err)
+	}
+}
@@ -0,0 +1,417 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Heo-YJ/teleport-opensource/config"
+)
+
+// s3Client - S3 호환 API 클라이언트 (AWS Signature V4, AWS S3 / MinIO 등)
+// 녹화 저장에 필요한 요청만 구현 (객체 업로드/다운로드/삭제, 멀티파트 업로드, 목록, 서명된 주소)
+type s3Client struct {
+	endpoint   *url.URL
+	region     string
+	bucket     string
+	accessKey  string
+	secretKey  string
+	pathStyle  bool
+	httpClient *http.Client
+	now        func() time.Time
+}
+
+// s3Part - 업로드한 멀티파트 조각
+type s3Part struct {
+	PartNumber int    `xml:"PartNumber"`
+	ETag       string `xml:"ETag"`
+}
+
+// s3Object - 목록 조회 항목
+type s3Object struct {
+	Key  string `xml:"Key"`
+	Size int64  `xml:"Size"`
+}
+
+// s3UnsignedPayload - 서명된 주소(쿼리 서명)의 본문 해시 자리
+const s3UnsignedPayload = "UNSIGNED-PAYLOAD"
+
+// newS3Client - S3 설정으로 클라이언트 생성
+func newS3Client(cfg config.S3Config) (*s3Client, error) {
+	if cfg.Bucket == "" {
+		return nil, fmt.Errorf("S3 버킷이 설정되지 않음 (RECORDING_S3_BUCKET)")
+	}
+	endpoint, err := url.Parse(strings.TrimRight(cfg.Endpoint, "/"))
+	if err != nil || endpoint.Host == "" {
+		return nil, fmt.Errorf("잘못된 S3 엔드포인트: %q", cfg.Endpoint)
+	}
+
+	return &s3Client{
+		endpoint:  endpoint,
+		region:    cfg.Region,
+		bucket:    cfg.Bucket,
+		accessKey: cfg.AccessKey,
+		secretKey: cfg.SecretKey,
+		pathStyle: cfg.PathStyle,
+		// 큰 녹화를 업로드할 수 있도록 전체 제한 시간 대신 요청별 context 사용
+		httpClient: &http.Client{},
+		now:        time.Now,
+	}, nil
+}
+
+// objectURL - 객체(key 가 비어 있으면 버킷) 주소 (쿼리는 서명 규칙에 맞게 정렬, 인코딩)
+func (c *s3Client) objectURL(key string, query url.Values) *url.URL {
+	u := *c.endpoint
+	path, rawPath := "/"+key, "/"+s3Escape(key, false)
+	if c.pathStyle {
+		path, rawPath = "/"+c.bucket+path, "/"+s3Escape(c.bucket, true)+rawPath
+	} else {
+		u.Host = c.bucket + "." + u.Host
+	}
+	u.Path = c.endpoint.Path + path
+	u.RawPath = c.endpoint.EscapedPath() + rawPath
+	u.RawQuery = s3CanonicalQuery(query)
+	return &u
+}
+
+// do - 요청 서명 후 전송 (2xx 가 아니면 S3 에러로 변환, 성공 시 응답 본문은 호출한 쪽에서 닫음)
+func (c *s3Client) do(ctx context.Context, method, key string, query url.Values, body []byte, contentType string) (*http.Response, error) {
+	u := c.objectURL(key, query)
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if body == nil {
+		req.Body = nil
+		req.ContentLength = 0
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	sum := sha256.Sum256(body)
+	c.sign(req, u, hex.EncodeToString(sum[:]))
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("S3 요청 실패 (%s %s): %v", method, key, err)
+	}
+	if resp.StatusCode/100 != 2 {
+		defer resp.Body.Close()
+		return nil, s3Error(resp, key)
+	}
+	return resp, nil
+}
+
+// sign - Authorization 헤더 서명 (host, x-amz-content-sha256, x-amz-date)
+func (c *s3Client) sign(req *http.Request, u *url.URL, payloadHash string) {
+	now := c.now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	host := s3Host(u)
+	signedHeaders := "host;x-amz-content-sha256;x-amz-date"
+	canonicalHeaders := "host:" + host + "\n" +
+		"x-amz-content-sha256:" + payloadHash + "\n" +
+		"x-amz-date:" + amzDate + "\n"
+	scope := c.scope(now)
+	signature := c.signature(now, scope, amzDate, req.Method, u, canonicalHeaders, signedHeaders, payloadHash)
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		c.accessKey, scope, signedHeaders, signature))
+}
+
+// presign - 서명된 GET 주소 (쿼리 서명, 인증 없이 expires 동안 다운로드 가능, 최대 7일)
+func (c *s3Client) presign(key string, expires time.Duration) string {
+	if expires > 7*24*time.Hour {
+		expires = 7 * 24 * time.Hour
+	}
+	if expires < time.Second {
+		expires = time.Second
+	}
+
+	now := c.now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	scope := c.scope(now)
+	query := url.Values{
+		"X-Amz-Algorithm":     {"AWS4-HMAC-SHA256"},
+		"X-Amz-Credential":    {c.accessKey + "/" + scope},
+		"X-Amz-Date":          {amzDate},
+		"X-Amz-Expires":       {strconv.Itoa(int(expires / time.Second))},
+		"X-Amz-SignedHeaders": {"host"},
+	}
+	u := c.objectURL(key, query)
+	canonicalHeaders := "host:" + s3Host(u) + "\n"
+	signature := c.signature(now, scope, amzDate, http.MethodGet, u, canonicalHeaders, "host", s3UnsignedPayload)
+
+	u.RawQuery += "&X-Amz-Signature=" + signature
+	return u.String()
+}
+
+// scope - 서명 범위 (날짜/리전/s3/aws4_request)
+func (c *s3Client) scope(now time.Time) string {
+	return now.Format("20060102") + "/" + c.region + "/s3/aws4_request"
+}
+
+// signature - Signature V4 서명 계산
+func (c *s3Client) signature(now time.Time, scope, amzDate, method string, u *url.URL, canonicalHeaders, signedHeaders, payloadHash string) string {
+	canonicalRequest := strings.Join([]string{
+		method,
+		u.EscapedPath(),
+		u.RawQuery,
+		canonicalHeaders,
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+
+	key := hmacSHA256([]byte("AWS4"+c.secretKey), now.Format("20060102"))
+	key = hmacSHA256(key, c.region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	return hex.EncodeToString(hmacSHA256(key, stringToSign))
+}
+
+// putObject - 객체 업로드 (작은 파일)
+func (c *s3Client) putObject(ctx context.Context, key string, data []byte, contentType string) error {
+	resp, err := c.do(ctx, http.MethodPut, key, nil, data, contentType)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// getObject - 객체 다운로드 (없으면 os.ErrNotExist)
+func (c *s3Client) getObject(ctx context.Context, key string) (io.ReadCloser, error) {
+	resp, err := c.do(ctx, http.MethodGet, key, nil, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// deleteObject - 객체 삭제 (없어도 성공)
+func (c *s3Client) deleteObject(ctx context.Context, key string) error {
+	resp, err := c.do(ctx, http.MethodDelete, key, nil, nil, "")
+	if err != nil {
+		if isRecordingNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// listObjects - 접두어로 객체 목록 조회 (ListObjectsV2, 페이지를 모두 읽음)
+func (c *s3Client) listObjects(ctx context.Context, prefix string) ([]s3Object, error) {
+	var objects []s3Object
+	token := ""
+	for {
+		query := url.Values{"list-type": {"2"}, "prefix": {prefix}}
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+		resp, err := c.do(ctx, http.MethodGet, "", query, nil, "")
+		if err != nil {
+			return nil, err
+		}
+		var result struct {
+			Contents              []s3Object `xml:"Contents"`
+			IsTruncated           bool       `xml:"IsTruncated"`
+			NextContinuationToken string     `xml:"NextContinuationToken"`
+		}
+		err = xml.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("S3 목록 응답 파싱 실패: %v", err)
+		}
+
+		objects = append(objects, result.Contents...)
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return objects, nil
+		}
+		token = result.NextContinuationToken
+	}
+}
+
+// uploadMultipart - 멀티파트 업로드 (partSize 씩 나눠 올리고, 실패하면 업로드 취소)
+func (c *s3Client) uploadMultipart(ctx context.Context, key string, reader io.Reader, partSize int, contentType string) error {
+	resp, err := c.do(ctx, http.MethodPost, key, url.Values{"uploads": {""}}, nil, contentType)
+	if err != nil {
+		return err
+	}
+	var initiate struct {
+		UploadID string `xml:"UploadId"`
+	}
+	err = xml.NewDecoder(resp.Body).Decode(&initiate)
+	resp.Body.Close()
+	if err != nil || initiate.UploadID == "" {
+		return fmt.Errorf("S3 멀티파트 업로드 시작 실패 (%s): 업로드 ID 없음", key)
+	}
+	uploadID := initiate.UploadID
+
+	var parts []s3Part
+	buffer := make([]byte, partSize)
+	for {
+		n, readErr := io.ReadFull(reader, buffer)
+		if readErr != nil && readErr != io.EOF && readErr != io.ErrUnexpectedEOF {
+			c.abortMultipart(key, uploadID)
+			return fmt.Errorf("업로드할 파일 읽기 실패: %v", readErr)
+		}
+		// 빈 파일도 파트 하나는 올림
+		if n > 0 || len(parts) == 0 {
+			etag, err := c.uploadPart(ctx, key, uploadID, len(parts)+1, buffer[:n])
+			if err != nil {
+				c.abortMultipart(key, uploadID)
+				return err
+			}
+			parts = append(parts, s3Part{PartNumber: len(parts) + 1, ETag: etag})
+		}
+		if readErr != nil {
+			break
+		}
+	}
+
+	if err := c.completeMultipart(ctx, key, uploadID, parts); err != nil {
+		c.abortMultipart(key, uploadID)
+		return err
+	}
+	return nil
+}
+
+// uploadPart - 파트 하나 업로드 (ETag 반환)
+func (c *s3Client) uploadPart(ctx context.Context, key, uploadID string, number int, data []byte) (string, error) {
+	query := url.Values{"partNumber": {strconv.Itoa(number)}, "uploadId": {uploadID}}
+	resp, err := c.do(ctx, http.MethodPut, key, query, data, "")
+	if err != nil {
+		return "", err
+	}
+	resp.Body.Close()
+	etag := resp.Header.Get("ETag")
+	if etag == "" {
+		return "", fmt.Errorf("S3 파트 업로드 응답에 ETag 없음 (%s, 파트 %d)", key, number)
+	}
+	return etag, nil
+}
+
+// completeMultipart - 멀티파트 업로드 완료
+func (c *s3Client) completeMultipart(ctx context.Context, key, uploadID string, parts []s3Part) error {
+	body, err := xml.Marshal(struct {
+		XMLName xml.Name `xml:"CompleteMultipartUpload"`
+		Parts   []s3Part `xml:"Part"`
+	}{Parts: parts})
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.do(ctx, http.MethodPost, key, url.Values{"uploadId": {uploadID}}, body, "application/xml")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	// 200 응답 본문에 에러가 올 수 있음
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	var result struct {
+		XMLName xml.Name
+		Code    string `xml:"Code"`
+		Message string `xml:"Message"`
+	}
+	if xml.Unmarshal(data, &result) == nil && result.XMLName.Local == "Error" {
+		return fmt.Errorf("S3 멀티파트 업로드 완료 실패 (%s): %s %s", key, result.Code, result.Message)
+	}
+	return nil
+}
+
+// abortMultipart - 멀티파트 업로드 취소 (올린 파트 정리, 요청 context 가 취소됐을 수 있으므로 따로 제한)
+func (c *s3Client) abortMultipart(key, uploadID string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	resp, err := c.do(ctx, http.MethodDelete, key, url.Values{"uploadId": {uploadID}}, nil, "")
+	if err != nil {
+		return
+	}
+	resp.Body.Close()
+}
+
+// s3Error - S3 에러 응답 변환 (NoSuchKey 등 404 는 os.ErrNotExist)
+func s3Error(resp *http.Response, key string) error {
+	var body struct {
+		Code    string `xml:"Code"`
+		Message string `xml:"Message"`
+	}
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if err := xml.Unmarshal(data, &body); err != nil || body.Code == "" {
+		body.Code = http.StatusText(resp.StatusCode)
+	}
+	if resp.StatusCode == http.StatusNotFound && body.Code != "NoSuchBucket" {
+		return fmt.Errorf("%w: S3 객체 %s", os.ErrNotExist, key)
+	}
+	return fmt.Errorf("S3 API 오류 (HTTP %d): %s %s", resp.StatusCode, body.Code, body.Message)
+}
+
+// s3Host - 서명할 Host 값 (기본 포트는 생략)
+func s3Host(u *url.URL) string {
+	host := u.Host
+	if (u.Scheme == "https" && strings.HasSuffix(host, ":443")) || (u.Scheme == "http" && strings.HasSuffix(host, ":80")) {
+		host = host[:strings.LastIndex(host, ":")]
+	}
+	return host
+}
+
+// s3CanonicalQuery - 키 순서로 정렬하고 RFC 3986 으로 인코딩한 쿼리
+func s3CanonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var pairs []string
+	for _, key := range keys {
+		values := append([]string(nil), query[key]...)
+		sort.Strings(values)
+		for _, value := range values {
+			pairs = append(pairs, s3Escape(key, true)+"="+s3Escape(value, true))
+		}
+	}
+	return strings.Join(pairs, "&")
+}
+
+// s3Escape - Signature V4 URI 인코딩 (A-Z a-z 0-9 - _ . ~ 외에는 %XX, encodeSlash 가 false 면 / 유지)
+func s3Escape(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c >= 'A' && c <= 'Z', c >= 'a' && c <= 'z', c >= '0' && c <= '9', c == '-', c == '_', c == '.', c == '~':
+			b.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+// hmacSHA256 - HMAC-SHA256
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
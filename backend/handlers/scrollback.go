@@ -0,0 +1,50 @@
+package handlers
+
+// scrollback - 최근 터미널 출력 링 버퍼
+// 출력 전체에서의 위치(오프셋)로 조회하므로 재연결한 클라이언트에 놓친 출력만 다시 보낼 수 있음
+type scrollback struct {
+	buf   []byte
+	total int64 // 지금까지 기록한 전체 바이트 (다음 출력의 오프셋)
+}
+
+// newScrollback - size 바이트 링 버퍼 생성
+func newScrollback(size int) *scrollback {
+	return &scrollback{buf: make([]byte, size)}
+}
+
+// Write - 출력 기록 (오래된 출력은 덮어씀)
+func (s *scrollback) Write(p []byte) {
+	size := len(s.buf)
+	if len(p) > size {
+		s.total += int64(len(p) - size)
+		p = p[len(p)-size:]
+	}
+	offset := int(s.total % int64(size))
+	n := copy(s.buf[offset:], p)
+	copy(s.buf, p[n:])
+	s.total += int64(len(p))
+}
+
+// Since - offset 이후의 출력 (이미 덮어쓴 부분이 있으면 truncated)
+func (s *scrollback) Since(offset int64) (data []byte, truncated bool) {
+	oldest := s.total - int64(len(s.buf))
+	if oldest < 0 {
+		oldest = 0
+	}
+	if offset < oldest {
+		offset, truncated = oldest, true
+	}
+	if offset > s.total {
+		offset = s.total
+	}
+
+	data = make([]byte, s.total-offset)
+	n := copy(data, s.buf[int(offset%int64(len(s.buf))):])
+	copy(data[n:], s.buf)
+	return data, truncated
+}
+
+// Total - 지금까지 기록한 전체 바이트
+func (s *scrollback) Total() int64 {
+	return s.total
+}
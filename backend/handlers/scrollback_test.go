@@ -0,0 +1,61 @@
+package handlers
+
+import "testing"
+
+func TestScrollbackSince(t *testing.T) {
+	s := newScrollback(8)
+
+	s.Write([]byte("abcdef"))
+	tests := []struct {
+		offset    int64
+		want      string
+		truncated bool
+	}{
+		{0, "abcdef", false},
+		{2, "cdef", false},
+		{6, "", false},
+		{10, "", false}, // 아직 없는 위치
+	}
+	for _, tt := range tests {
+		data, truncated := s.Since(tt.offset)
+		if string(data) != tt.want || truncated != tt.truncated {
+			t.Errorf("Since(%d) = %q, %v (want %q, %v)", tt.offset, data, truncated, tt.want, tt.truncated)
+		}
+	}
+
+	// 링 버퍼를 한 바퀴 넘김: 오래된 출력은 덮어쓰고 오프셋은 계속 증가
+	s.Write([]byte("ghij"))
+	if s.Total() != 10 {
+		t.Errorf("Total = %d, want 10", s.Total())
+	}
+	tests = []struct {
+		offset    int64
+		want      string
+		truncated bool
+	}{
+		{0, "cdefghij", true},
+		{2, "cdefghij", false},
+		{6, "ghij", false},
+		{9, "j", false},
+	}
+	for _, tt := range tests {
+		data, truncated := s.Since(tt.offset)
+		if string(data) != tt.want || truncated != tt.truncated {
+			t.Errorf("Since(%d) = %q, %v (want %q, %v)", tt.offset, data, truncated, tt.want, tt.truncated)
+		}
+	}
+}
+
+func TestScrollbackLargeWrite(t *testing.T) {
+	s := newScrollback(4)
+	s.Write([]byte("ab"))
+	s.Write([]byte("0123456789")) // 버퍼보다 큰 출력은 끝부분만 남음
+
+	if s.Total() != 12 {
+		t.Errorf("Total = %d, want 12", s.Total())
+	}
+	data, truncated := s.Since(2)
+	if string(data) != "6789" || !truncated {
+		t.Errorf("Since(2) = %q, %v", data, truncated)
+	}
+}
@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+)
+
+// ContainerSource - 접속 대상(컨테이너/노드) 목록 제공자
+// Teleport, Docker 등 인벤토리 출처마다 하나씩 구현
+type ContainerSource interface {
+	// Name - 소스 이름 (ContainerInfo.Source 값과 동일)
+	Name() string
+	// ListContainers - 현재 컨테이너 목록 조회
+	// 여러 소스를 묶은 경우 일부만 실패하면 나머지 목록과 *PartialListError 를 함께 반환
+	ListContainers(ctx context.Context) ([]ContainerInfo, error)
+}
+
+// PartialListError - 일부 소스/클러스터만 조회에 실패 (나머지 목록은 함께 반환)
+// 목록에서 빠진 컨테이너가 실제로 삭제된 것인지, 조회에 실패한 것인지 구분하는 데 사용
+type PartialListError struct {
+	Failed []FailedScope
+}
+
+// FailedScope - 조회에 실패한 범위 (비어 있는 필드는 모든 값)
+type FailedScope struct {
+	Cluster string // ContainerInfo.Cluster
+	Source  string // ContainerInfo.Source
+}
+
+func (e *PartialListError) Error() string {
+	scopes := make([]string, 0, len(e.Failed))
+	for _, scope := range e.Failed {
+		switch {
+		case scope.Cluster == "":
+			scopes = append(scopes, scope.Source)
+		case scope.Source == "":
+			scopes = append(scopes, scope.Cluster)
+		default:
+			scopes = append(scopes, scope.Cluster+"/"+scope.Source)
+		}
+	}
+	return "일부 소스 조회 실패: " + strings.Join(scopes, ", ")
+}
+
+// Covers - 컨테이너가 조회에 실패한 범위에 속하는지 확인
+func (e *PartialListError) Covers(c ContainerInfo) bool {
+	for _, scope := range e.Failed {
+		if (scope.Cluster == "" || scope.Cluster == c.Cluster) && (scope.Source == "" || scope.Source == c.Source) {
+			return true
+		}
+	}
+	return false
+}
+
+// asPartialList - 일부만 실패한 조회인지 확인
+func asPartialList(err error) (*PartialListError, bool) {
+	var partial *PartialListError
+	ok := errors.As(err, &partial)
+	return partial, ok
+}
+
+// MultiSource - 여러 소스의 목록을 하나로 합침
+// 일부 소스가 실패해도 나머지 결과는 반환 (실패한 소스는 *PartialListError 로 알림)
+type MultiSource struct {
+	sources []ContainerSource
+}
+
+// NewMultiSource - 여러 소스를 묶은 소스 생성
+func NewMultiSource(sources ...ContainerSource) *MultiSource {
+	return &MultiSource{sources: sources}
+}
+
+// Name - 묶인 소스 이름 목록
+func (m *MultiSource) Name() string {
+	names := make([]string, 0, len(m.sources))
+	for _, source := range m.sources {
+		names = append(names, source.Name())
+	}
+	return strings.Join(names, "+")
+}
+
+// ListContainers - 모든 소스의 목록을 순서대로 합쳐 반환
+func (m *MultiSource) ListContainers(ctx context.Context) ([]ContainerInfo, error) {
+	var (
+		containers []ContainerInfo
+		errs       []string
+		partial    PartialListError
+	)
+
+	for _, source := range m.sources {
+		list, err := source.ListContainers(ctx)
+		if failed, ok := asPartialList(err); ok {
+			// 소스 안에서 일부만 실패 (예: Auth 서버 장애 중인 teleport 의 fallback 목록)
+			containers = append(containers, list...)
+			partial.Failed = append(partial.Failed, failed.Failed...)
+			continue
+		}
+		if err != nil {
+			log.Printf("[%s] 컨테이너 목록 조회 실패: %v", source.Name(), err)
+			errs = append(errs, fmt.Sprintf("%s: %v", source.Name(), err))
+			partial.Failed = append(partial.Failed, FailedScope{Source: source.Name()})
+			continue
+		}
+		containers = append(containers, list...)
+	}
+
+	// 모든 소스가 실패한 경우에만 에러
+	if len(errs) > 0 && len(errs) == len(m.sources) {
+		return nil, fmt.Errorf("모든 소스 조회 실패: %s", strings.Join(errs, "; "))
+	}
+	if len(partial.Failed) > 0 {
+		return containers, &partial
+	}
+	return containers, nil
+}
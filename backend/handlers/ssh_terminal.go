@@ -0,0 +1,238 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"os"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+
+	"github.com/Heo-YJ/teleport-opensource/config"
+)
+
+// SSHBackend - 원격 노드의 SSH 셸 세션 (직접 접속 또는 Teleport Proxy 경유)
+type SSHBackend struct {
+	method string // ssh / teleport
+	addr   string // 로그/정보 표시용 접속 대상
+	term   string // PTY 요청 시 TERM 값
+	dial   func(ctx context.Context) (*ssh.Client, error)
+	agent  io.Closer // ssh-agent 연결 (인증에만 필요하므로 접속 후 닫음, 없으면 nil)
+
+	client  *ssh.Client
+	session *ssh.Session
+	stdin   io.WriteCloser
+	output  *io.PipeReader // stdout + stderr
+	exited  chan struct{}  // session.Wait 완료
+	waitErr error
+}
+
+// newSSHClientConfig - 터미널 설정으로 SSH 클라이언트 설정 생성
+// 개인 키 파일 → ssh-agent → 비밀번호 순으로 인증 시도
+// ssh-agent 를 사용하면 agent 연결도 반환 (SSHBackend 가 접속 후 닫음)
+func newSSHClientConfig(cfg *config.TerminalConfig, user string) (*ssh.ClientConfig, io.Closer, error) {
+	var auth []ssh.AuthMethod
+
+	if cfg.SSHKeyFile != "" {
+		signer, err := loadSSHKey(cfg.SSHKeyFile)
+		switch {
+		case err == nil:
+			auth = append(auth, ssh.PublicKeys(signer))
+		case cfg.SSHKeyFileSet:
+			return nil, nil, err
+		case !errors.Is(err, os.ErrNotExist):
+			// 기본 키 (~/.ssh/id_rsa) 는 암호가 걸려 있거나 형식이 달라도 건너뛰고 다른 인증 수단 사용
+			log.Printf("⚠️ 기본 SSH 개인 키를 건너뜀: %v", err)
+		}
+	}
+
+	hostKeyCallback := ssh.InsecureIgnoreHostKey()
+	if !cfg.SSHInsecure {
+		callback, err := knownhosts.New(cfg.SSHKnownHosts)
+		if err != nil {
+			return nil, nil, fmt.Errorf("known_hosts 로드 실패 (SSH_INSECURE_IGNORE_HOST_KEY=true 로 검증 생략 가능): %v", err)
+		}
+		hostKeyCallback = callback
+	}
+
+	var agentConn net.Conn
+	if sock := os.Getenv("SSH_AUTH_SOCK"); sock != "" {
+		if conn, err := net.Dial("unix", sock); err == nil {
+			agentConn = conn
+			auth = append(auth, ssh.PublicKeysCallback(agent.NewClient(agentConn).Signers))
+		}
+	}
+
+	if cfg.SSHPassword != "" {
+		auth = append(auth, ssh.Password(cfg.SSHPassword))
+	}
+
+	if len(auth) == 0 {
+		return nil, nil, fmt.Errorf("사용할 SSH 인증 수단이 없습니다 (SSH_KEY_FILE, SSH_AUTH_SOCK, SSH_PASSWORD)")
+	}
+
+	config := &ssh.ClientConfig{
+		User:            user,
+		Auth:            auth,
+		HostKeyCallback: hostKeyCallback,
+		Timeout:         cfg.SSHDialTimeout,
+	}
+	if agentConn == nil {
+		return config, nil, nil
+	}
+	return config, agentConn, nil
+}
+
+// loadSSHKey - 개인 키 파일을 읽어 signer 생성 (암호가 걸린 키는 *ssh.PassphraseMissingError)
+func loadSSHKey(path string) (ssh.Signer, error) {
+	key, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("SSH 개인 키 읽기 실패: %w", err)
+	}
+	signer, err := ssh.ParsePrivateKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("SSH 개인 키 파싱 실패 (%s): %w", path, err)
+	}
+	return signer, nil
+}
+
+// NewSSHBackend - addr 에 직접 SSH 접속하는 백엔드 (agent 는 newSSHClientConfig 가 반환한 ssh-agent 연결, nil 가능)
+func NewSSHBackend(addr string, config *ssh.ClientConfig, agent io.Closer, term string) *SSHBackend {
+	backend := newSSHBackend(addr, term, func(ctx context.Context) (*ssh.Client, error) {
+		var dialer net.Dialer
+		netConn, err := dialer.DialContext(ctx, "tcp", addr)
+		if err != nil {
+			return nil, fmt.Errorf("SSH 접속 실패 (%s): %v", addr, err)
+		}
+		conn, chans, reqs, err := ssh.NewClientConn(netConn, addr, config)
+		if err != nil {
+			netConn.Close()
+			return nil, fmt.Errorf("SSH 접속 실패 (%s): %v", addr, err)
+		}
+		return ssh.NewClient(conn, chans, reqs), nil
+	})
+	backend.agent = agent
+	return backend
+}
+
+// newSSHBackend - 접속 함수로 SSH 백엔드 생성 (Teleport Proxy 경유 등)
+func newSSHBackend(addr, term string, dial func(ctx context.Context) (*ssh.Client, error)) *SSHBackend {
+	return &SSHBackend{method: MethodSSH, addr: addr, term: term, dial: dial, exited: make(chan struct{})}
+}
+
+// Start - 접속 후 PTY 를 요청하고 셸 시작
+func (b *SSHBackend) Start(ctx context.Context, cols, rows int) error {
+	client, err := b.dial(ctx)
+	b.closeAgent() // 인증이 끝났으므로 세션 동안 붙잡고 있지 않음
+	if err != nil {
+		return err
+	}
+	b.client = client
+
+	if b.session, err = client.NewSession(); err != nil {
+		return fmt.Errorf("SSH 세션 생성 실패: %v", err)
+	}
+
+	modes := ssh.TerminalModes{
+		ssh.ECHO:          1,
+		ssh.TTY_OP_ISPEED: 14400,
+		ssh.TTY_OP_OSPEED: 14400,
+	}
+	if err := b.session.RequestPty(b.term, rows, cols, modes); err != nil {
+		return fmt.Errorf("PTY 요청 실패: %v", err)
+	}
+
+	if b.stdin, err = b.session.StdinPipe(); err != nil {
+		return fmt.Errorf("SSH 입력 연결 실패: %v", err)
+	}
+	// PTY 에서는 stderr 도 같은 화면으로 출력
+	output, outputWriter := io.Pipe()
+	b.output = output
+	b.session.Stdout = outputWriter
+	b.session.Stderr = outputWriter
+
+	if err := b.session.Shell(); err != nil {
+		return fmt.Errorf("SSH 셸 시작 실패: %v", err)
+	}
+	log.Printf("SSH 셸 시작: %s@%s", client.User(), b.addr)
+
+	// 셸이 끝나고 출력 복사가 끝나면 Read 가 EOF 를 반환하도록 파이프를 닫음
+	go func() {
+		b.waitErr = b.session.Wait()
+		outputWriter.Close()
+		close(b.exited)
+	}()
+	return nil
+}
+
+// Read - 셸 출력 읽기
+func (b *SSHBackend) Read(p []byte) (int, error) {
+	return b.output.Read(p)
+}
+
+// Write - 셸 입력
+func (b *SSHBackend) Write(p []byte) (int, error) {
+	return b.stdin.Write(p)
+}
+
+// Resize - window-change 요청
+func (b *SSHBackend) Resize(cols, rows int) error {
+	return b.session.WindowChange(rows, cols)
+}
+
+// Signal - signal 요청 (서버가 지원하지 않으면 무시될 수 있음)
+func (b *SSHBackend) Signal(sig string) error {
+	return b.session.Signal(ssh.Signal(sig))
+}
+
+// Wait - 셸 종료 대기 후 종료 코드 반환
+func (b *SSHBackend) Wait() (int, error) {
+	<-b.exited
+	return sshExitCode(b.waitErr), b.waitErr
+}
+
+// sshExitCode - session.Wait 결과를 종료 코드로 변환 (알 수 없으면 -1)
+func sshExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	var exitErr *ssh.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitStatus()
+	}
+	return -1
+}
+
+// closeAgent - ssh-agent 연결 닫기 (여러 번 호출 가능)
+func (b *SSHBackend) closeAgent() {
+	if b.agent != nil {
+		b.agent.Close()
+		b.agent = nil
+	}
+}
+
+// Close - SSH 세션과 연결 정리
+func (b *SSHBackend) Close() error {
+	b.closeAgent()
+	if b.session != nil {
+		b.session.Close()
+	}
+	if b.client != nil {
+		return b.client.Close()
+	}
+	return nil
+}
+
+// Info - 터미널 정보
+func (b *SSHBackend) Info() map[string]interface{} {
+	info := map[string]interface{}{"backend": b.method, "remote": b.addr}
+	if b.client != nil {
+		info["user"] = b.client.User()
+	}
+	return info
+}
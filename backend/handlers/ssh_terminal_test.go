@@ -0,0 +1,466 @@
+package handlers
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+
+	"github.com/Heo-YJ/teleport-opensource/config"
+)
+
+// fakeSSHServer - 같은 프로세스에서 띄운 SSH 서버
+// 등록한 공개 키 또는 비밀번호로 인증하고, session 채널의 pty-req/shell/window-change/signal 요청을 기록
+// 셸은 입력을 그대로 돌려주고, "exit <코드>" 입력을 받으면 exit-status 를 보낸 뒤 채널을 닫음
+type fakeSSHServer struct {
+	addr       string
+	hostKey    ssh.Signer
+	authorized ssh.PublicKey
+	password   string
+
+	mu       sync.Mutex
+	users    []string
+	ptys     []string // "<TERM> <열>x<행>"
+	resizes  []string // "<열>x<행>"
+	signals  []string
+	sessions int
+}
+
+// newTestSSHKey - ed25519 키 생성
+func newTestSSHKey(t *testing.T) (ed25519.PrivateKey, ssh.Signer) {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := ssh.NewSignerFromKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key, signer
+}
+
+func newFakeSSHServer(t *testing.T, authorized ssh.PublicKey) *fakeSSHServer {
+	_, hostKey := newTestSSHKey(t)
+	s := &fakeSSHServer{hostKey: hostKey, authorized: authorized, password: "secret"}
+
+	serverConfig := &ssh.ServerConfig{
+		PublicKeyCallback: func(conn ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if s.authorized != nil && string(key.Marshal()) == string(s.authorized.Marshal()) {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("알 수 없는 키")
+		},
+		PasswordCallback: func(conn ssh.ConnMetadata, password []byte) (*ssh.Permissions, error) {
+			if string(password) == s.password {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("비밀번호 불일치")
+		},
+	}
+	serverConfig.AddHostKey(hostKey)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	s.addr = listener.Addr().String()
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn, serverConfig)
+		}
+	}()
+	return s
+}
+
+// knownHosts - 이 서버의 호스트 키를 담은 known_hosts 파일
+func (s *fakeSSHServer) knownHosts(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "known_hosts")
+	line := knownhosts.Line([]string{s.addr}, s.hostKey.PublicKey())
+	if err := os.WriteFile(path, []byte(line+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func (s *fakeSSHServer) serve(netConn net.Conn, serverConfig *ssh.ServerConfig) {
+	conn, chans, reqs, err := ssh.NewServerConn(netConn, serverConfig)
+	if err != nil {
+		netConn.Close()
+		return
+	}
+	defer conn.Close()
+	go ssh.DiscardRequests(reqs)
+
+	s.mu.Lock()
+	s.users = append(s.users, conn.User())
+	s.mu.Unlock()
+
+	for newChannel := range chans {
+		if newChannel.ChannelType() != "session" {
+			newChannel.Reject(ssh.UnknownChannelType, "session 만 지원")
+			continue
+		}
+		channel, requests, err := newChannel.Accept()
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		s.sessions++
+		s.mu.Unlock()
+		go s.session(channel, requests)
+	}
+}
+
+// session - 세션 요청 처리 (shell 을 받으면 echo 셸 시작)
+func (s *fakeSSHServer) session(channel ssh.Channel, requests <-chan *ssh.Request) {
+	for req := range requests {
+		ok := true
+		s.mu.Lock()
+		switch req.Type {
+		case "pty-req":
+			var pty struct {
+				Term                         string
+				Columns, Rows, Width, Height uint32
+				Modes                        string
+			}
+			ok = ssh.Unmarshal(req.Payload, &pty) == nil
+			s.ptys = append(s.ptys, fmt.Sprintf("%s %dx%d", pty.Term, pty.Columns, pty.Rows))
+		case "window-change":
+			var size struct{ Columns, Rows, Width, Height uint32 }
+			ok = ssh.Unmarshal(req.Payload, &size) == nil
+			s.resizes = append(s.resizes, fmt.Sprintf("%dx%d", size.Columns, size.Rows))
+		case "signal":
+			var signal struct{ Signal string }
+			ok = ssh.Unmarshal(req.Payload, &signal) == nil
+			s.signals = append(s.signals, signal.Signal)
+		case "shell":
+			go echoShell(channel)
+		default:
+			ok = false
+		}
+		s.mu.Unlock()
+		if req.WantReply {
+			req.Reply(ok, nil)
+		}
+	}
+}
+
+// echoShell - 입력을 그대로 출력 ("exit <코드>" 를 받으면 exit-status 를 보내고 종료)
+func echoShell(channel ssh.Channel) {
+	defer channel.Close()
+	buf := make([]byte, 1024)
+	for {
+		n, err := channel.Read(buf)
+		if err != nil {
+			return
+		}
+		if code, ok := strings.CutPrefix(strings.TrimSpace(string(buf[:n])), "exit "); ok {
+			status, _ := strconv.Atoi(code)
+			channel.SendRequest("exit-status", false, ssh.Marshal(struct{ Status uint32 }{uint32(status)}))
+			return
+		}
+		if _, err := channel.Write(buf[:n]); err != nil {
+			return
+		}
+	}
+}
+
+// state - 기록한 요청 (복사본)
+func (s *fakeSSHServer) state() (users, ptys, resizes, signals []string, sessions int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.users...), append([]string(nil), s.ptys...),
+		append([]string(nil), s.resizes...), append([]string(nil), s.signals...), s.sessions
+}
+
+// fakeSSHAgent - unix 소켓에서 키 하나를 가진 ssh-agent 제공 (연결이 닫히면 closed 로 알림)
+type fakeSSHAgent struct {
+	socket string
+	closed chan struct{}
+}
+
+func newFakeSSHAgent(t *testing.T, key ed25519.PrivateKey) *fakeSSHAgent {
+	// unix 소켓 경로 길이 제한 때문에 t.TempDir 대신 짧은 경로 사용
+	dir, err := os.MkdirTemp("", "agent")
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyring := agent.NewKeyring()
+	if err := keyring.Add(agent.AddedKey{PrivateKey: key}); err != nil {
+		t.Fatal(err)
+	}
+	a := &fakeSSHAgent{socket: filepath.Join(dir, "agent.sock"), closed: make(chan struct{}, 16)}
+	listener, err := net.Listen("unix", a.socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		listener.Close()
+		os.RemoveAll(dir)
+	})
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				agent.ServeAgent(keyring, conn)
+				conn.Close()
+				a.closed <- struct{}{}
+			}()
+		}
+	}()
+	return a
+}
+
+// waitClosed - 클라이언트가 agent 연결을 닫을 때까지 대기
+func (a *fakeSSHAgent) waitClosed(t *testing.T) {
+	t.Helper()
+	select {
+	case <-a.closed:
+	case <-time.After(2 * time.Second):
+		t.Fatal("ssh-agent 연결이 닫히지 않음")
+	}
+}
+
+// testSSHConfig - 개인 키 파일과 known_hosts 를 쓰는 터미널 설정
+func testSSHConfig(t *testing.T, key ed25519.PrivateKey, knownHosts string) *config.TerminalConfig {
+	t.Helper()
+	cfg := &config.TerminalConfig{
+		SSHKeyFile:     filepath.Join(t.TempDir(), "id_ed25519"),
+		SSHKnownHosts:  knownHosts,
+		SSHDialTimeout: 5 * time.Second,
+	}
+	if key != nil {
+		block, err := ssh.MarshalPrivateKey(key, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(cfg.SSHKeyFile, pem.EncodeToMemory(block), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return cfg
+}
+
+func TestSSHBackendSession(t *testing.T) {
+	t.Setenv("SSH_AUTH_SOCK", "")
+	key, signer := newTestSSHKey(t)
+	server := newFakeSSHServer(t, signer.PublicKey())
+
+	clientConfig, agentConn, err := newSSHClientConfig(testSSHConfig(t, key, server.knownHosts(t)), "deploy")
+	if err != nil || agentConn != nil {
+		t.Fatalf("newSSHClientConfig = %v, %v", agentConn, err)
+	}
+	b := NewSSHBackend(server.addr, clientConfig, nil, "xterm-256color")
+	if err := b.Start(context.Background(), 120, 40); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer b.Close()
+
+	if _, err := b.Write([]byte("uptime\n")); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	readUntil(t, b, "uptime\n")
+	if err := b.Resize(100, 30); err != nil {
+		t.Fatalf("Resize: %v", err)
+	}
+	if err := b.Signal("INT"); err != nil {
+		t.Fatalf("Signal: %v", err)
+	}
+
+	if _, err := b.Write([]byte("exit 3\n")); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if _, err := io.Copy(io.Discard, b); err != nil {
+		t.Fatalf("종료 후 읽기: %v", err)
+	}
+	if code, err := b.Wait(); code != 3 || err == nil {
+		t.Errorf("Wait = %d, %v", code, err)
+	}
+
+	users, ptys, resizes, signals, sessions := server.state()
+	if !reflect.DeepEqual(users, []string{"deploy"}) || sessions != 1 {
+		t.Errorf("접속 사용자 = %q, 세션 수 = %d", users, sessions)
+	}
+	if !reflect.DeepEqual(ptys, []string{"xterm-256color 120x40"}) {
+		t.Errorf("PTY 요청 = %q", ptys)
+	}
+	if !reflect.DeepEqual(resizes, []string{"100x30"}) {
+		t.Errorf("크기 조정 = %q", resizes)
+	}
+	if !reflect.DeepEqual(signals, []string{"INT"}) {
+		t.Errorf("시그널 = %q", signals)
+	}
+	if info := b.Info(); info["user"] != "deploy" || info["remote"] != server.addr || info["backend"] != MethodSSH {
+		t.Errorf("Info = %v", info)
+	}
+}
+
+func TestSSHBackendRejectsUnknownHostKey(t *testing.T) {
+	t.Setenv("SSH_AUTH_SOCK", "")
+	key, signer := newTestSSHKey(t)
+	server := newFakeSSHServer(t, signer.PublicKey())
+	other := newFakeSSHServer(t, signer.PublicKey())
+
+	// 다른 서버의 호스트 키만 등록된 known_hosts
+	knownHosts := filepath.Join(t.TempDir(), "known_hosts")
+	line := knownhosts.Line([]string{server.addr}, other.hostKey.PublicKey())
+	if err := os.WriteFile(knownHosts, []byte(line+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	clientConfig, _, err := newSSHClientConfig(testSSHConfig(t, key, knownHosts), "deploy")
+	if err != nil {
+		t.Fatalf("newSSHClientConfig: %v", err)
+	}
+	b := NewSSHBackend(server.addr, clientConfig, nil, "xterm")
+	if err := b.Start(context.Background(), 80, 24); err == nil {
+		b.Close()
+		t.Fatal("호스트 키가 다른 서버에 접속됨")
+	}
+	if _, _, _, _, sessions := server.state(); sessions != 0 {
+		t.Errorf("세션 수 = %d", sessions)
+	}
+}
+
+func TestSSHBackendAgentAuth(t *testing.T) {
+	key, signer := newTestSSHKey(t)
+	server := newFakeSSHServer(t, signer.PublicKey())
+	sshAgent := newFakeSSHAgent(t, key)
+	t.Setenv("SSH_AUTH_SOCK", sshAgent.socket)
+
+	// 개인 키 파일 없이 ssh-agent 의 키로 인증
+	cfg := testSSHConfig(t, nil, server.knownHosts(t))
+	clientConfig, agentConn, err := newSSHClientConfig(cfg, "deploy")
+	if err != nil || agentConn == nil {
+		t.Fatalf("newSSHClientConfig = %v, %v", agentConn, err)
+	}
+	b := NewSSHBackend(server.addr, clientConfig, agentConn, "xterm")
+	if err := b.Start(context.Background(), 80, 24); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer b.Close()
+
+	// 인증이 끝나면 세션이 끝나기 전에 agent 연결을 닫음
+	sshAgent.waitClosed(t)
+	if _, err := b.Write([]byte("id\n")); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	readUntil(t, b, "id\n")
+
+	// 접속에 실패해도 agent 연결을 닫음
+	clientConfig, agentConn, err = newSSHClientConfig(cfg, "deploy")
+	if err != nil {
+		t.Fatalf("newSSHClientConfig: %v", err)
+	}
+	listener, _ := net.Listen("tcp", "127.0.0.1:0")
+	closedAddr := listener.Addr().String()
+	listener.Close()
+	failed := NewSSHBackend(closedAddr, clientConfig, agentConn, "xterm")
+	if err := failed.Start(context.Background(), 80, 24); err == nil {
+		t.Fatal("닫힌 주소에 접속됨")
+	}
+	sshAgent.waitClosed(t)
+}
+
+func TestSSHClientConfigPasswordFallback(t *testing.T) {
+	t.Setenv("SSH_AUTH_SOCK", "")
+	server := newFakeSSHServer(t, nil)
+
+	cfg := testSSHConfig(t, nil, "")
+	cfg.SSHInsecure = true
+	if _, _, err := newSSHClientConfig(cfg, "deploy"); err == nil {
+		t.Fatal("인증 수단 없이 설정이 만들어짐")
+	}
+
+	cfg.SSHPassword = "secret"
+	clientConfig, _, err := newSSHClientConfig(cfg, "deploy")
+	if err != nil {
+		t.Fatalf("newSSHClientConfig: %v", err)
+	}
+	b := NewSSHBackend(server.addr, clientConfig, nil, "xterm")
+	if err := b.Start(context.Background(), 80, 24); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer b.Close()
+
+	if _, err := b.Write([]byte("exit 0\n")); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	io.Copy(io.Discard, b)
+	if code, err := b.Wait(); code != 0 || err != nil {
+		t.Errorf("Wait = %d, %v", code, err)
+	}
+}
+
+func TestSSHClientConfigSkipsDefaultKey(t *testing.T) {
+	t.Setenv("SSH_AUTH_SOCK", "")
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, err := ssh.MarshalPrivateKeyWithPassphrase(key, "", []byte("passphrase"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := map[string][]byte{
+		"암호가 걸린 키": pem.EncodeToMemory(block),
+		"잘못된 형식":   []byte("not a key"),
+	}
+	for name, data := range cases {
+		cfg := testSSHConfig(t, nil, "")
+		cfg.SSHInsecure = true
+		cfg.SSHPassword = "secret"
+		if err := os.WriteFile(cfg.SSHKeyFile, data, 0o600); err != nil {
+			t.Fatal(err)
+		}
+
+		// 기본 키는 건너뛰고 비밀번호 인증만 사용
+		clientConfig, _, err := newSSHClientConfig(cfg, "deploy")
+		if err != nil {
+			t.Errorf("%s: 기본 키 newSSHClientConfig: %v", name, err)
+		} else if len(clientConfig.Auth) != 1 {
+			t.Errorf("%s: 인증 수단 %d 개", name, len(clientConfig.Auth))
+		}
+
+		// 직접 지정한 키는 에러
+		cfg.SSHKeyFileSet = true
+		if _, _, err := newSSHClientConfig(cfg, "deploy"); err == nil {
+			t.Errorf("%s: 직접 지정한 키 에러 없음", name)
+		}
+	}
+
+	// 직접 지정한 키 파일이 없어도 에러
+	cfg := testSSHConfig(t, nil, "")
+	cfg.SSHInsecure, cfg.SSHPassword, cfg.SSHKeyFileSet = true, "secret", true
+	if _, _, err := newSSHClientConfig(cfg, "deploy"); err == nil {
+		t.Error("없는 키 파일 에러 없음")
+	}
+}
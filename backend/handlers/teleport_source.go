@@ -0,0 +1,183 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/gravitational/teleport/api/client"
+	"github.com/gravitational/teleport/api/client/proto"
+	apidefaults "github.com/gravitational/teleport/api/defaults"
+	"github.com/gravitational/teleport/api/types"
+
+	"github.com/Heo-YJ/teleport-opensource/config"
+)
+
+// TeleportSource - Teleport Auth 서버의 SSH 노드 목록 제공자
+type TeleportSource struct {
+	config   *config.TeleportConfig
+	fallback ContainerSource // Auth 서버에 연결할 수 없을 때 사용할 소스
+
+	dialMu sync.Mutex // 동시에 한 번만 연결 시도
+
+	mu          sync.RWMutex
+	client      *client.Client // 연결하지 못했으면 nil (다음 조회/접속 때 다시 연결)
+	inFallback  bool           // true면 fallback 소스로 동작 중
+	fallbackErr error          // fallback 으로 전환된 원인
+}
+
+// NewTeleportSource - Teleport 소스 생성
+// 클라이언트 생성에 실패하면 fallback 모드로 시작하고, 이후 목록 조회 때마다 다시 연결 시도
+func NewTeleportSource(cfg *config.TeleportConfig, fallback ContainerSource) *TeleportSource {
+	s := &TeleportSource{config: cfg, fallback: fallback}
+	s.teleportClient() // 실패하면 setFallback 에서 기록
+	return s
+}
+
+// teleportClient - Auth 서버 클라이언트 (아직 연결하지 못했으면 다시 연결)
+func (s *TeleportSource) teleportClient() (*client.Client, error) {
+	s.mu.RLock()
+	teleportClient := s.client
+	s.mu.RUnlock()
+	if teleportClient != nil {
+		return teleportClient, nil
+	}
+
+	s.dialMu.Lock()
+	defer s.dialMu.Unlock()
+	s.mu.RLock()
+	teleportClient = s.client
+	s.mu.RUnlock()
+	if teleportClient != nil {
+		return teleportClient, nil
+	}
+
+	teleportClient, err := newTeleportClient(s.config)
+	if err != nil {
+		s.setFallback(fmt.Errorf("Teleport 클라이언트 생성 실패: %v", err))
+		return nil, err
+	}
+	log.Printf("Teleport Auth 서버 연결 성공: %s", s.config.AuthServer)
+	s.mu.Lock()
+	s.client = teleportClient
+	s.mu.Unlock()
+	return teleportClient, nil
+}
+
+// newTeleportClient - Auth 서버에 연결된 Teleport 클라이언트 생성
+func newTeleportClient(cfg *config.TeleportConfig) (*client.Client, error) {
+	var creds []client.Credentials
+	if cfg.IdentityFile != "" {
+		creds = append(creds, client.LoadIdentityFile(cfg.IdentityFile))
+	}
+	// identity 파일이 없으면 tsh login 프로필 사용
+	creds = append(creds, client.LoadProfile("", ""))
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.DialTimeout)
+	defer cancel()
+
+	return client.New(ctx, client.Config{
+		Addrs:       []string{cfg.AuthServer},
+		Credentials: creds,
+		DialTimeout: cfg.DialTimeout,
+	})
+}
+
+// Name - 소스 이름
+func (s *TeleportSource) Name() string {
+	return "teleport"
+}
+
+// setFallback - fallback 모드 전환 (err가 nil이면 정상 모드로 복귀)
+func (s *TeleportSource) setFallback(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err != nil && !s.inFallback {
+		log.Printf("Teleport fallback 모드 전환: %v", err)
+	} else if err == nil && s.inFallback {
+		log.Println("Teleport 정상 모드 복귀")
+	}
+	s.inFallback = err != nil
+	s.fallbackErr = err
+}
+
+// Status - Teleport 연결 상태 조회 (connected / fallback)
+func (s *TeleportSource) Status() (string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.inFallback {
+		return "fallback", s.fallbackErr
+	}
+	return "connected", nil
+}
+
+// ListContainers - Teleport API를 통한 실제 SSH 노드 목록 조회
+// Auth 서버에 연결할 수 없으면 fallback 소스 목록과 *PartialListError 반환
+func (s *TeleportSource) ListContainers(ctx context.Context) ([]ContainerInfo, error) {
+	teleportClient, err := s.teleportClient()
+	if err != nil {
+		return s.listFallback(ctx)
+	}
+
+	nodes, err := client.GetAllResources[types.Server](ctx, teleportClient, &proto.ListResourcesRequest{
+		ResourceType: types.KindNode,
+		Namespace:    apidefaults.Namespace,
+	})
+	if err != nil {
+		s.setFallback(fmt.Errorf("노드 목록 조회 실패: %v", err))
+		return s.listFallback(ctx)
+	}
+	s.setFallback(nil)
+
+	containers := make([]ContainerInfo, 0, len(nodes))
+	for _, node := range nodes {
+		containers = append(containers, nodeToContainerInfo(node))
+	}
+	return containers, nil
+}
+
+// listFallback - Auth 서버에 연결할 수 없을 때의 목록 (fallback 소스 목록)
+// Teleport 노드는 조회에 실패한 것이므로 *PartialListError 로 알려 캐시가 이전 노드를 유지하도록 함
+func (s *TeleportSource) listFallback(ctx context.Context) ([]ContainerInfo, error) {
+	partial := &PartialListError{Failed: []FailedScope{{Source: s.Name()}}}
+	if s.fallback == nil {
+		return nil, partial
+	}
+	containers, err := s.fallback.ListContainers(ctx)
+	if err != nil {
+		log.Printf("fallback 소스 조회 실패: %v", err)
+		return nil, partial
+	}
+	return containers, partial
+}
+
+// nodeToContainerInfo - Teleport 노드를 ContainerInfo로 변환
+func nodeToContainerInfo(node types.Server) ContainerInfo {
+	status := "online"
+	if expiry := node.Expiry(); !expiry.IsZero() && expiry.Before(time.Now()) {
+		// heartbeat가 끊긴 노드
+		status = "offline"
+	}
+
+	// 정적 라벨 + 동적(command) 라벨
+	labels := make(map[string]string)
+	for key, value := range node.GetStaticLabels() {
+		labels[key] = value
+	}
+	for key, cmd := range node.GetCmdLabels() {
+		labels[key] = cmd.GetResult()
+	}
+
+	return ContainerInfo{
+		ID:       node.GetName(),
+		Name:     node.GetHostname(),
+		Status:   status,
+		Labels:   labels,
+		NodeAddr: node.GetAddr(), // 리버스 터널 노드는 비어 있음
+		Source:   "teleport",
+	}
+}
@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Heo-YJ/teleport-opensource/config"
+)
+
+func TestTeleportSourceOutage(t *testing.T) {
+	cfg := &config.TeleportConfig{AuthServer: "127.0.0.1:1", DialTimeout: 200 * time.Millisecond}
+	fallback := &staticSource{name: "file", containers: []ContainerInfo{{ID: "dev-1", Source: "file"}}}
+	source := NewTeleportSource(cfg, fallback)
+	if status, err := source.Status(); status != "fallback" || err == nil {
+		t.Errorf("Status = %q, %v", status, err)
+	}
+
+	// 연결할 수 없으면 fallback 목록과 함께 teleport 범위의 일부 실패로 알림 (조회할 때마다 다시 연결 시도)
+	for i := 0; i < 2; i++ {
+		containers, err := source.ListContainers(context.Background())
+		partial, ok := asPartialList(err)
+		if !ok || containerIDs(containers) != "dev-1" || !reflect.DeepEqual(partial.Failed, []FailedScope{{Source: "teleport"}}) {
+			t.Errorf("%d번째 ListContainers = %+v, %v", i+1, containers, err)
+		}
+	}
+
+	containers, err := NewTeleportSource(cfg, nil).ListContainers(context.Background())
+	if partial, ok := asPartialList(err); !ok || len(containers) != 0 || !partial.Covers(ContainerInfo{ID: "node-1", Source: "teleport"}) {
+		t.Errorf("fallback 없이 ListContainers = %+v, %v", containers, err)
+	}
+
+	// 캐시는 Auth 서버 장애 중에도 이전에 받은 Teleport 노드를 유지
+	cache := NewInventoryCache(source, time.Minute, time.Minute)
+	cache.containers = []ContainerInfo{{ID: "node-1", Source: "teleport"}}
+	if err := cache.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+	if containers, _ := cache.ListContainers(context.Background()); containerIDs(containers) != "dev-1,node-1" {
+		t.Errorf("장애 중 캐시 목록 = %q", containerIDs(containers))
+	}
+
+	if _, err := source.DialNode(context.Background(), "alice", "root", "node-1:0"); err == nil {
+		t.Error("연결되지 않은 상태에서 DialNode 에러 없음")
+	}
+}
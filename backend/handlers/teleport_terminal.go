@@ -0,0 +1,202 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"time"
+
+	"github.com/gravitational/teleport/api/client"
+	"github.com/gravitational/teleport/api/client/proto"
+	"github.com/gravitational/teleport/api/types"
+	"golang.org/x/crypto/ssh"
+)
+
+// DialNode - Teleport Proxy 를 거쳐 노드에 SSH 접속
+// teleportUser 이름으로 짧은 유효 기간의 사용자 인증서를 발급받아 Proxy 와 노드 인증에 사용하므로
+// 세션이 Teleport 감사 로그/세션 녹화에 남음 (identity 파일의 역할에 impersonate 권한 필요)
+// node 는 "노드이름:0" (리버스 터널 노드 포함) 또는 "host:port"
+func (s *TeleportSource) DialNode(ctx context.Context, teleportUser, login, node string) (*ssh.Client, error) {
+	teleportClient, err := s.teleportClient()
+	if err != nil {
+		return nil, fmt.Errorf("Teleport Auth 서버에 연결되어 있지 않습니다: %v", err)
+	}
+	if teleportUser == "" {
+		return nil, fmt.Errorf("Teleport 사용자 정보가 없습니다 (X-Forwarded-User 헤더 또는 TELEPORT_USER)")
+	}
+
+	ping, err := teleportClient.Ping(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("Teleport 클러스터 정보 조회 실패: %v", err)
+	}
+
+	signer, err := s.userCertSigner(ctx, teleportClient, teleportUser, ping.ClusterName)
+	if err != nil {
+		return nil, err
+	}
+	hostKeyCallback, err := s.hostKeyCallback(ctx, teleportClient, ping.ClusterName)
+	if err != nil {
+		return nil, err
+	}
+
+	clientConfig := &ssh.ClientConfig{
+		User:            login,
+		Auth:            []ssh.AuthMethod{ssh.PublicKeys(signer)},
+		HostKeyCallback: hostKeyCallback,
+		Timeout:         s.config.DialTimeout,
+	}
+
+	proxyClient, err := ssh.Dial("tcp", s.config.ProxyAddr, clientConfig)
+	if err != nil {
+		return nil, fmt.Errorf("Teleport Proxy 접속 실패 (%s): %v", s.config.ProxyAddr, err)
+	}
+
+	// Proxy 의 proxy 서브시스템으로 노드까지 터널을 열고, 그 위에서 노드와 SSH 핸드셰이크
+	tunnel, err := openProxySubsystem(proxyClient, fmt.Sprintf("proxy:%s@%s", node, ping.ClusterName))
+	if err != nil {
+		proxyClient.Close()
+		return nil, err
+	}
+
+	conn, chans, reqs, err := ssh.NewClientConn(tunnel, node, clientConfig)
+	if err != nil {
+		tunnel.Close()
+		return nil, fmt.Errorf("노드 SSH 접속 실패 (%s): %v", node, err)
+	}
+
+	log.Printf("Teleport Proxy 경유 접속: %s → %s@%s (클러스터: %s)", teleportUser, login, node, ping.ClusterName)
+	return ssh.NewClient(conn, chans, reqs), nil
+}
+
+// NewTeleportBackend - Teleport Proxy 를 거쳐 노드에 접속하는 SSH 터미널 백엔드
+func (s *TeleportSource) NewTeleportBackend(teleportUser, login, node, term string) *SSHBackend {
+	backend := newSSHBackend(node, term, func(ctx context.Context) (*ssh.Client, error) {
+		return s.DialNode(ctx, teleportUser, login, node)
+	})
+	backend.method = MethodTeleport
+	return backend
+}
+
+// userCertSigner - 새 키를 만들고 Auth 서버에서 사용자 SSH 인증서 발급
+func (s *TeleportSource) userCertSigner(ctx context.Context, teleportClient *client.Client, teleportUser, clusterName string) (ssh.Signer, error) {
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("SSH 키 생성 실패: %v", err)
+	}
+	signer, err := ssh.NewSignerFromKey(privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("SSH 키 생성 실패: %v", err)
+	}
+
+	certs, err := teleportClient.GenerateUserCerts(ctx, proto.UserCertsRequest{
+		SSHPublicKey:   ssh.MarshalAuthorizedKey(signer.PublicKey()),
+		Username:       teleportUser,
+		Expires:        time.Now().Add(s.config.CertTTL),
+		RouteToCluster: clusterName,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("사용자 인증서 발급 실패 (%s): %v", teleportUser, err)
+	}
+
+	key, _, _, _, err := ssh.ParseAuthorizedKey(certs.SSH)
+	if err != nil {
+		return nil, fmt.Errorf("사용자 인증서 파싱 실패: %v", err)
+	}
+	cert, ok := key.(*ssh.Certificate)
+	if !ok {
+		return nil, fmt.Errorf("발급된 키가 SSH 인증서가 아닙니다")
+	}
+	return ssh.NewCertSigner(cert, signer)
+}
+
+// hostKeyCallback - 클러스터 Host CA 가 서명한 호스트 인증서만 신뢰
+func (s *TeleportSource) hostKeyCallback(ctx context.Context, teleportClient *client.Client, clusterName string) (ssh.HostKeyCallback, error) {
+	ca, err := teleportClient.GetCertAuthority(ctx, types.CertAuthID{Type: types.HostCA, DomainName: clusterName}, false)
+	if err != nil {
+		return nil, fmt.Errorf("Host CA 조회 실패: %v", err)
+	}
+
+	var authorities []ssh.PublicKey
+	for _, pair := range ca.GetTrustedSSHKeyPairs() {
+		key, _, _, _, err := ssh.ParseAuthorizedKey(pair.PublicKey)
+		if err != nil {
+			return nil, fmt.Errorf("Host CA 키 파싱 실패: %v", err)
+		}
+		authorities = append(authorities, key)
+	}
+	if len(authorities) == 0 {
+		return nil, fmt.Errorf("클러스터 %s 에 Host CA 키가 없습니다", clusterName)
+	}
+
+	checker := &ssh.CertChecker{
+		IsHostAuthority: func(auth ssh.PublicKey, address string) bool {
+			for _, key := range authorities {
+				if bytes.Equal(key.Marshal(), auth.Marshal()) {
+					return true
+				}
+			}
+			return false
+		},
+	}
+	return checker.CheckHostKey, nil
+}
+
+// teleportNodeAddr - proxy 서브시스템에 넘길 노드 주소
+// Teleport 에서 온 노드는 노드 이름(UUID)으로 찾으므로 리버스 터널 노드도 접속 가능
+func teleportNodeAddr(c *ContainerInfo, localID string) string {
+	if c.Source == "teleport" {
+		return localID + ":0"
+	}
+	return c.NodeAddr
+}
+
+// proxySubsystemConn - proxy 서브시스템 세션을 net.Conn 으로 사용
+type proxySubsystemConn struct {
+	proxy   *ssh.Client
+	session *ssh.Session
+	stdin   io.WriteCloser
+	stdout  io.Reader
+}
+
+// openProxySubsystem - Proxy 에 서브시스템 요청 (예: proxy:node-uuid:0@cluster)
+func openProxySubsystem(proxy *ssh.Client, subsystem string) (*proxySubsystemConn, error) {
+	session, err := proxy.NewSession()
+	if err != nil {
+		return nil, fmt.Errorf("Teleport Proxy 세션 생성 실패: %v", err)
+	}
+	stdin, err := session.StdinPipe()
+	if err != nil {
+		session.Close()
+		return nil, fmt.Errorf("Teleport Proxy 세션 생성 실패: %v", err)
+	}
+	stdout, err := session.StdoutPipe()
+	if err != nil {
+		session.Close()
+		return nil, fmt.Errorf("Teleport Proxy 세션 생성 실패: %v", err)
+	}
+	if err := session.RequestSubsystem(subsystem); err != nil {
+		session.Close()
+		return nil, fmt.Errorf("Teleport Proxy 서브시스템 요청 실패 (%s): %v", subsystem, err)
+	}
+	return &proxySubsystemConn{proxy: proxy, session: session, stdin: stdin, stdout: stdout}, nil
+}
+
+func (c *proxySubsystemConn) Read(p []byte) (int, error)  { return c.stdout.Read(p) }
+func (c *proxySubsystemConn) Write(p []byte) (int, error) { return c.stdin.Write(p) }
+
+// Close - 노드 연결이 닫히면 Proxy 연결도 함께 닫음
+func (c *proxySubsystemConn) Close() error {
+	c.session.Close()
+	return c.proxy.Close()
+}
+
+func (c *proxySubsystemConn) LocalAddr() net.Addr                { return c.proxy.LocalAddr() }
+func (c *proxySubsystemConn) RemoteAddr() net.Addr               { return c.proxy.RemoteAddr() }
+func (c *proxySubsystemConn) SetDeadline(t time.Time) error      { return nil }
+func (c *proxySubsystemConn) SetReadDeadline(t time.Time) error  { return nil }
+func (c *proxySubsystemConn) SetWriteDeadline(t time.Time) error { return nil }
@@ -2,17 +2,35 @@
 package handlers
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
-	"os"
-	"os/exec"
-	"runtime"
-	"syscall"
-	"unsafe"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
 
-	"github.com/creack/pty"
 	"github.com/gorilla/websocket"
+
+	"github.com/Heo-YJ/teleport-opensource/config"
+)
+
+// 바이너리 프레이밍 WebSocket 서브프로토콜
+// 클라이언트가 이 서브프로토콜을 요청하면 터미널 입출력은 원시 바이트 그대로 바이너리 프레임으로,
+// 제어 메시지(resize, signal, ping, exit, system, error)는 기존처럼 JSON 텍스트 프레임으로 주고받음
+// 서브프로토콜 없이 연결하면 모든 메시지가 JSON (기존 방식)
+const terminalBinaryProtocol = "terminal.binary.v1"
+
+// 터미널 시작(접속, PTY 요청, exec 생성) 제한 시간
+const terminalStartTimeout = 20 * time.Second
+
+// 터미널 기본 크기 (프론트엔드가 연결 직후 resize 를 보냄)
+const (
+	defaultTerminalCols = 80
+	defaultTerminalRows = 24
 )
 
 // TerminalMessage - WebSocket 메시지 구조
@@ -27,328 +45,777 @@ type ResizeMessage struct {
 	Rows int `json:"rows"`
 }
 
-// LocalTerminal - 실제 터미널 세션 관리
-type LocalTerminal struct {
-	cmd       *exec.Cmd       // 실행 중인 명령어
-	pty       *os.File        // 가상 터미널
-	conn      *websocket.Conn // WebSocket 연결
-	done      chan bool       // 종료 신호
-	sessionID string          // 세션 ID
-}
-
-// NewLocalTerminal - 새 로컬 터미널 생성
-func NewLocalTerminal(conn *websocket.Conn, sessionID string) (*LocalTerminal, error) {
-	log.Printf("터미널 생성 시작: %s", sessionID) //디버깅 확인
-
-	// OS에 따른 셸 명령어 결정
-	var cmd *exec.Cmd
-	if runtime.GOOS == "windows" {
-		log.Printf("Windows 환경에서 cmd 실행") //디버깅 확인
-		cmd = exec.Command("cmd")
-	} else {
-		// Linux/macOS
-		shellPath := "/bin/bash"
-		if _, err := os.Stat(shellPath); err != nil {
-			shellPath = "/bin/sh"
-		}
-		log.Printf("Unix 환경에서 %s 실행", shellPath) //디버깅 확인
-		cmd = exec.Command(shellPath)
+// TerminalBackend - 터미널 접속 방식별 구현 (로컬 PTY, SSH, docker exec, kubernetes exec)
+// WebSocket 과의 연결은 Terminal 이 담당하므로 백엔드는 입출력 스트림만 제공
+type TerminalBackend interface {
+	Start(ctx context.Context, cols, rows int) error // 접속 후 TTY 셸 시작
+	Read(p []byte) (int, error)                      // 출력 (stdout/stderr 합침), 셸이 끝나면 io.EOF
+	Write(p []byte) (int, error)                     // 입력
+	Resize(cols, rows int) error
+	Signal(sig string) error      // INT, TERM, KILL 등 (지원하지 않으면 에러)
+	Wait() (int, error)           // 셸 종료 대기 후 종료 코드 (알 수 없으면 -1)
+	Close() error                 // 셸/연결 정리 (여러 번 호출 가능)
+	Info() map[string]interface{} // GetInfo 에 추가할 정보
+}
+
+// terminalConn - Terminal 과 연결된 클라이언트 (WebSocket 하나, 또는 다중화 WebSocket 의 채널 하나)
+// *websocket.Conn 이 그대로 만족함
+type terminalConn interface {
+	ReadMessage() (messageType int, p []byte, err error)
+	WriteMessage(messageType int, data []byte) error
+	WriteJSON(v interface{}) error
+	Subprotocol() string
+	Close() error
+}
+
+// errSignalUnsupported - 백엔드가 지원하지 않는 시그널
+var errSignalUnsupported = errors.New("지원하지 않는 시그널")
+
+// 세션 참가자 역할
+const (
+	RoleOwner     = "owner"     // 세션을 연 사용자 (재연결 토큰으로 다시 연결)
+	RoleModerator = "moderator" // 함께 입력할 수 있는 참가자
+	RoleObserver  = "observer"  // 출력만 보는 참가자 (입력, 크기 조정, 시그널 불가)
+)
+
+// Participant - 세션 참가자 정보
+type Participant struct {
+	User     string    `json:"user"`
+	Role     string    `json:"role"`
+	Protocol string    `json:"protocol"` // json / binary
+	JoinedAt time.Time `json:"joinedAt"`
+}
+
+// participant - 세션에 연결된 클라이언트 하나
+type participant struct {
+	Participant
+	conn     terminalConn
+	binary   bool        // terminalBinaryProtocol 협상 여부
+	client   AuditClient // 감사 로그에 남길 클라이언트 정보
+	commands commandLine // 입력 중인 명령어 줄 (입력 고루틴에서만 사용)
+}
+
+// newParticipant - 연결의 서브프로토콜로 메시지 방식을 정해 참가자 생성
+func newParticipant(conn terminalConn, user, role string, client AuditClient) *participant {
+	p := &participant{
+		Participant: Participant{User: user, Role: role, Protocol: "json", JoinedAt: time.Now()},
+		conn:        conn,
+		binary:      conn.Subprotocol() == terminalBinaryProtocol,
+		client:      client,
+	}
+	if p.binary {
+		p.Protocol = "binary"
 	}
+	return p
+}
 
-	// 환경 변수 설정
-	cmd.Env = append(os.Environ(),
-		"TERM=xterm-256color",
-		"PS1=🐳 container:$ ",
-		"LANG=en_US.UTF-8",
-	)
-	log.Printf("환경 변수 설정 완료") //디버깅 확인
+// canInput - 터미널 입력 권한 (관찰자만 없음)
+func (p *participant) canInput() bool {
+	return p.Role != RoleObserver
+}
 
-	// PTY 생성 및 명령어 시작
-	log.Printf("PTY 생성 시도 중..") //디버깅 확인
-	ptyFile, err := pty.Start(cmd)
-	if err != nil {
-		log.Printf("PTY 생성 실패: %v", err) //디버깅 확인
-		return nil, fmt.Errorf("PTY 시작 실패: %v", err)
+// writeOutput - 출력 프레임 쓰기 (바이너리 모드는 바이너리 프레임, JSON 모드는 output 메시지)
+func (p *participant) writeOutput(data []byte) error {
+	if p.binary {
+		return p.conn.WriteMessage(websocket.BinaryMessage, data)
 	}
-	log.Printf("PTY 생성 성공 (PID: %d)", cmd.Process.Pid) //디버깅 확인
+	return p.conn.WriteJSON(TerminalMessage{Type: "output", Data: string(data)})
+}
+
+// Terminal - TerminalBackend 와 WebSocket 연결
+// 메시지: input, resize, signal, ping, command (수신) / output, exit, pong, system, error (송신)
+// 바이너리 모드에서는 input/output 대신 바이너리 프레임 사용
+// 클라이언트 연결이 끊겨도 DetachGrace 동안 셸을 유지하고, Attach 로 다시 연결하면 놓친 출력부터 전송
+// 다른 사용자는 Join 으로 같은 세션에 참가해 같은 출력을 받음 (관찰자는 입력 불가)
+// recorder 가 있으면 백엔드와 주고받는 입출력과 크기 조정을 녹화
+// 시작/종료, 참가/퇴장, 입력한 명령어는 audit 으로 감사 로그에 기록
+type Terminal struct {
+	backend   TerminalBackend
+	config    *config.TerminalConfig
+	recorder  *Recorder     // 세션 녹화 (녹화하지 않으면 nil)
+	audit     *sessionAudit // 감사 로그
+	output    *outputQueue  // 전송 대기 출력 (묶어서 전송, 백프레셔)
+	done      chan bool     // 종료 신호
+	closeOnce sync.Once
+	sessionID string    // 세션 ID
+	owner     string    // 세션을 연 사용자
+	startedAt time.Time // 세션 시작 시각
+
+	// 아래는 writeMu 로 보호 (WebSocket 동시 쓰기 방지)
+	writeMu      sync.Mutex
+	participants []*participant // 연결된 클라이언트 (소유자가 끊겨도 다른 참가자는 유지)
+	scrollback   *scrollback    // 최근 출력
+	sentOffset   int64          // 소유자 연결에 마지막으로 보낸 출력 위치
+	detachTimer  *time.Timer    // 입력할 수 있는 참가자가 모두 끊긴 동안의 재연결 유예 시간
+}
+
+// NewTerminal - 백엔드를 시작하고 WebSocket 과 연결 (conn 은 세션 소유자, recorder 는 nil 가능)
+func NewTerminal(conn terminalConn, sessionID, user string, backend TerminalBackend, recorder *Recorder, audit *sessionAudit, cfg *config.TerminalConfig) (*Terminal, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), terminalStartTimeout)
+	defer cancel()
 
-	terminal := &LocalTerminal{
-		cmd:       cmd,
-		pty:       ptyFile,
-		conn:      conn,
-		done:      make(chan bool),
-		sessionID: sessionID,
+	if err := backend.Start(ctx, defaultTerminalCols, defaultTerminalRows); err != nil {
+		backend.Close()
+		return nil, err
 	}
 
-	log.Printf("🖥️ 새 터미널 세션 시작: %s (PID: %d)", sessionID, cmd.Process.Pid)
+	owner := newParticipant(conn, user, RoleOwner, audit.owner)
+	terminal := &Terminal{
+		backend:      backend,
+		config:       cfg,
+		recorder:     recorder,
+		audit:        audit,
+		output:       newOutputQueue(cfg.OutputQueueSize, cfg.OutputPolicy),
+		done:         make(chan bool),
+		sessionID:    sessionID,
+		owner:        user,
+		startedAt:    time.Now(),
+		participants: []*participant{owner},
+		scrollback:   newScrollback(cfg.ScrollbackSize),
+	}
 
-	// 백그라운드 고루틴 시작
-	log.Printf("백그라운드 go루틴 시작") //디버깅 확인
-	go terminal.handlePtyOutput()
-	go terminal.handleWebSocketInput()
-	go terminal.monitorProcess()
+	log.Printf("🖥️ 새 터미널 세션 시작: %s %v (사용자: %s, 프로토콜: %s)", sessionID, backend.Info(), user, owner.Protocol)
 
-	log.Printf("터미널 생성 및 초기화 완료: %s", sessionID) //디버깅 확인
+	go terminal.handleBackendOutput()
+	go terminal.handleOutputQueue()
+	go terminal.handleWebSocketInput(owner)
 	return terminal, nil
 }
 
-// handlePtyOutput - PTY 출력을 WebSocket으로 전송
-func (lt *LocalTerminal) handlePtyOutput() {
-	buffer := make([]byte, 1024)
+// handleBackendOutput - 백엔드 출력을 출력 큐에 넣고, 셸이 끝나면 남은 출력 전송 후 exit 메시지 전송
+func (t *Terminal) handleBackendOutput() {
+	buffer := make([]byte, 8192)
+	for {
+		n, err := t.backend.Read(buffer)
+		if n > 0 {
+			// 녹화는 읽은 시점 기준 (drop 정책으로 버린 출력도 녹화에는 남음)
+			t.recorder.Output(buffer[:n])
+			if !t.output.push(buffer[:n]) {
+				// 클라이언트가 먼저 종료함
+				return
+			}
+		}
+		if err != nil {
+			if err != io.EOF && t.IsAlive() {
+				log.Printf("터미널 출력 읽기 오류: %v", err)
+			}
+			break
+		}
+	}
+
+	t.output.closeInput()
+	select {
+	case <-t.output.drained:
+	case <-t.done:
+		// 클라이언트가 먼저 종료함
+		return
+	}
+
+	exitCode, err := t.backend.Wait()
+	log.Printf("🏁 셸 종료: %s (코드: %d, 에러: %v)", t.sessionID, exitCode, err)
+
+	t.SendMessage("exit", map[string]interface{}{
+		"message":   "터미널 세션이 종료되었습니다",
+		"code":      exitCode,
+		"sessionId": t.sessionID,
+	})
+	t.closeWith("exit", map[string]interface{}{"exitCode": exitCode})
+}
 
+// handleOutputQueue - 출력 큐를 짧은 간격으로 모아서 모든 참가자에게 전송
+// (cat 처럼 출력이 많을 때 작은 프레임 수천 개 대신 큰 프레임 몇 개로 보냄)
+func (t *Terminal) handleOutputQueue() {
 	for {
 		select {
-		case <-lt.done:
+		case <-t.output.notify:
+		case <-t.done:
 			return
-		default:
-			// PTY에서 데이터 읽기
-			n, err := lt.pty.Read(buffer)
-			if err != nil {
-				if err == io.EOF {
-					log.Printf("터미널 출력 종료: %s", lt.sessionID)
-				} else {
-					log.Printf("PTY 읽기 오류: %v", err)
-				}
-				lt.Close()
+		}
+
+		// 한 프레임을 채울 만큼 쌓이지 않았으면 잠시 더 모음
+		if t.output.size() < t.config.OutputBatchSize {
+			select {
+			case <-time.After(t.config.OutputFlushInterval):
+			case <-t.done:
 				return
 			}
+		}
 
-			// WebSocket으로 출력 데이터 전송
-			if err := lt.conn.WriteJSON(TerminalMessage{
-				Type: "output",
-				Data: string(buffer[:n]),
-			}); err != nil {
-				log.Printf("WebSocket 출력 전송 실패: %v", err)
-				lt.Close()
-				return
+		for {
+			data, dropped := t.output.take(t.config.OutputBatchSize, t.hasJSONParticipant())
+			if dropped > 0 {
+				log.Printf("⚠️ 출력 큐 초과로 %d 바이트 버림 (세션: %s)", dropped, t.sessionID)
+				t.SendMessage("system", fmt.Sprintf("출력이 너무 많아 %d 바이트를 건너뛰었습니다", dropped))
+			}
+			if len(data) == 0 {
+				break
 			}
+			// 다중화 채널은 클라이언트가 ack 한 만큼만 보냄 (writeMu 밖에서 대기, 모든 참가자가 같은 출력을 받으므로 가장 느린 채널 기준)
+			for _, conn := range t.connections() {
+				if conn, ok := conn.(flowControlledConn); ok {
+					conn.waitCredit(len(data), t.done)
+				}
+			}
+			for _, conn := range t.sendOutput(data) {
+				t.detach(conn)
+			}
+		}
+
+		if t.output.isDrained() {
+			close(t.output.drained)
+			return
 		}
 	}
 }
 
-// handleWebSocketInput - WebSocket 입력을 PTY로 전송
-func (lt *LocalTerminal) handleWebSocketInput() {
+// handleWebSocketInput - 참가자의 WebSocket 입력을 백엔드로 전송 (연결마다 하나)
+func (t *Terminal) handleWebSocketInput(p *participant) {
+	conn := p.conn
+	warned := false // 관찰자 입력 거부는 한 번만 알림
 	for {
-		select {
-		case <-lt.done:
-			return
-		default:
-			var message TerminalMessage
-			err := lt.conn.ReadJSON(&message)
-			if err != nil {
-				if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
-					log.Printf("WebSocket 입력 오류: %v", err)
-				} else {
-					log.Printf("클라이언트 연결 종료: %s", lt.sessionID)
+		messageType, data, err := conn.ReadMessage()
+		if err != nil {
+			if !t.IsAlive() {
+				return
+			}
+			// 소유자가 터미널을 닫은 경우만 세션을 바로 종료하고, 새로고침/네트워크 끊김은 재연결을 기다림
+			if websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseNoStatusReceived) {
+				if p.Role == RoleOwner {
+					log.Printf("클라이언트 연결 종료: %s", t.sessionID)
+					t.closeWith("client_closed", nil)
+					return
 				}
-				lt.Close()
+			} else {
+				log.Printf("WebSocket 입력 오류: %v", err)
+			}
+			t.detach(conn)
+			return
+		}
+
+		// 바이너리 프레임은 그대로 터미널 입력
+		if messageType == websocket.BinaryMessage {
+			if !p.binary {
+				log.Printf("바이너리 프로토콜을 협상하지 않은 세션의 바이너리 프레임 무시: %s", t.sessionID)
+				continue
+			}
+			if !p.canInput() {
+				warned = t.rejectInput(p, "input", warned)
+				continue
+			}
+			t.recorder.Input(data)
+			t.auditInput(p, data)
+			if _, err := t.backend.Write(data); err != nil {
+				log.Printf("터미널 입력 전송 실패: %v", err)
+				t.closeWith("input_error", map[string]interface{}{"error": err.Error()})
 				return
 			}
+			continue
+		}
 
-			// 메시지 타입에 따른 처리
-			switch message.Type {
-			case "input":
-				// 사용자 입력을 PTY로 전송
-				if input, ok := message.Data.(string); ok {
-					_, err := lt.pty.WriteString(input)
-					if err != nil {
-						log.Printf("PTY 입력 전송 실패: %v", err)
-						lt.Close()
-						return
-					}
-				}
+		var message TerminalMessage
+		if err := json.Unmarshal(data, &message); err != nil {
+			log.Printf("잘못된 메시지 형식: %v", err)
+			continue
+		}
 
-			case "resize":
-				// 터미널 크기 조정
-				if resizeData, ok := message.Data.(map[string]interface{}); ok {
-					if cols, hasC := resizeData["cols"].(float64); hasC {
-						if rows, hasR := resizeData["rows"].(float64); hasR {
-							lt.resize(int(cols), int(rows))
-						}
-					}
+		// 관찰자는 ping 만 가능
+		if !p.canInput() && message.Type != "ping" {
+			warned = t.rejectInput(p, message.Type, warned)
+			continue
+		}
+
+		// 메시지 타입에 따른 처리
+		switch message.Type {
+		case "input":
+			// 사용자 입력을 백엔드로 전송
+			if input, ok := message.Data.(string); ok {
+				t.auditInput(p, []byte(input))
+				if err := t.WriteToTerminal(input); err != nil {
+					log.Printf("터미널 입력 전송 실패: %v", err)
+					t.closeWith("input_error", map[string]interface{}{"error": err.Error()})
+					return
 				}
+			}
 
-			case "ping":
-				// Ping-Pong
-				pongMessage := TerminalMessage{
-					Type: "pong",
-					Data: "터미널 연결 정상",
+		case "resize":
+			// 터미널 크기 조정
+			if cols, rows, ok := parseResizeData(message.Data); ok {
+				log.Printf("🔧 터미널 크기 조정: %dx%d (세션: %s, 사용자: %s)", cols, rows, t.sessionID, p.User)
+				if err := t.backend.Resize(cols, rows); err != nil {
+					log.Printf("터미널 크기 조정 실패: %v", err)
+				} else {
+					t.recorder.Resize(cols, rows)
 				}
-				lt.conn.WriteJSON(pongMessage)
+			}
 
-			case "command":
-				// 특별 명령어 처리
-				if cmdStr, ok := message.Data.(string); ok {
-					lt.handleSpecialCommand(cmdStr)
+		case "signal":
+			// 시그널 전송 (예: {"type":"signal","data":"INT"})
+			if sig, ok := message.Data.(string); ok {
+				if err := t.backend.Signal(strings.ToUpper(strings.TrimPrefix(sig, "SIG"))); err != nil {
+					t.sendTo(conn, "error", map[string]interface{}{
+						"message": fmt.Sprintf("시그널 전송 실패 (%s): %v", sig, err),
+						"time":    time.Now().Format("15:04:05"),
+					})
 				}
+			}
 
-			default:
-				log.Printf("알 수 없는 메시지 타입: %s", message.Type)
+		case "ping":
+			// Ping-Pong
+			t.sendTo(conn, "pong", "터미널 연결 정상")
+
+		case "command":
+			// 명령어 한 줄 실행
+			if cmdStr, ok := message.Data.(string); ok {
+				t.auditInput(p, []byte(cmdStr+"\n"))
+				t.WriteToTerminal(cmdStr + "\n")
 			}
+
+		default:
+			log.Printf("알 수 없는 메시지 타입: %s", message.Type)
 		}
 	}
 }
 
-// handleSpecialCommand - 특별 명령어 처리
-func (lt *LocalTerminal) handleSpecialCommand(command string) {
-	switch command {
-	case "clear":
-		// 화면 지우기
-		lt.pty.WriteString("clear\n")
-	case "help":
-		// 도움말
-		helpText := `터미널 도움말`
-		lt.pty.WriteString(fmt.Sprintf("echo '%s'\n", helpText))
+// rejectInput - 관찰자의 입력 거부 (처음 한 번만 에러 메시지 전송, 알렸으면 true)
+func (t *Terminal) rejectInput(p *participant, msgType string, warned bool) bool {
+	if warned {
+		return true
+	}
+	log.Printf("관찰자 입력 거부: %s (사용자: %s, 메시지: %s)", t.sessionID, p.User, msgType)
+	t.sendTo(p.conn, "error", map[string]interface{}{
+		"message": "관찰자는 터미널에 입력할 수 없습니다",
+		"role":    p.Role,
+		"time":    time.Now().Format("15:04:05"),
+	})
+	return true
+}
+
+// auditInput - 참가자 입력에서 엔터로 끝난 명령어 줄을 감사 로그에 기록 (AUDIT_COMMANDS=true)
+func (t *Terminal) auditInput(p *participant, data []byte) {
+	if !t.audit.recordsCommands() {
+		return
+	}
+	for _, command := range p.commands.feed(data) {
+		t.audit.emit(AuditCommandExecuted, p.client, map[string]interface{}{
+			"command": command,
+			"role":    p.Role,
+		})
+	}
+}
+
+// Close - 터미널 세션 종료 (관리 API, 서버 종료)
+func (t *Terminal) Close() {
+	t.closeWith("terminated", nil)
+}
+
+// closeWith - 종료 이유와 함께 터미널 세션 종료 (처음 한 번만 session_end 기록)
+// reason: exit (셸 종료), client_closed (소유자가 닫음), detach_timeout, input_error, terminated
+func (t *Terminal) closeWith(reason string, details map[string]interface{}) {
+	t.closeOnce.Do(func() {
+		log.Printf("터미널 세션 종료 중: %s (%s)", t.sessionID, reason)
+		close(t.done)
+		t.output.close()
+
+		// 리소스 정리
+		if err := t.backend.Close(); err != nil {
+			log.Printf("터미널 백엔드 정리 실패: %v", err)
+		}
+		if err := t.recorder.Close(); err != nil {
+			log.Printf("세션 녹화 종료 실패: %v", err)
+		}
+
+		// 종료 메시지 전송 시도
+		t.SendMessage("system", "터미널 세션이 종료되었습니다")
+		t.writeMu.Lock()
+		if t.detachTimer != nil {
+			t.detachTimer.Stop()
+		}
+		for _, p := range t.participants {
+			p.conn.Close()
+		}
+		t.writeMu.Unlock()
+
+		if details == nil {
+			details = map[string]interface{}{}
+		}
+		details["reason"] = reason
+		details["duration"] = time.Since(t.startedAt).Seconds()
+		details["output"] = t.OutputStats()
+		t.audit.emit(AuditSessionEnd, t.audit.owner, details)
+
+		log.Printf("터미널 세션 정리 완료: %s", t.sessionID)
+	})
+}
+
+// Done - 세션이 끝나면 닫히는 채널
+func (t *Terminal) Done() <-chan bool {
+	return t.done
+}
+
+// IsAlive - 터미널이 살아있는지 확인
+func (t *Terminal) IsAlive() bool {
+	select {
+	case <-t.done:
+		return false
 	default:
-		// 일반 명령어는 그대로 전송
-		lt.pty.WriteString(command + "\n")
+		return true
 	}
 }
 
-// monitorProcess - 프로세스 상태 모니터링
-func (lt *LocalTerminal) monitorProcess() {
-	// 프로세스 종료 대기
-	err := lt.cmd.Wait()
+// Attach - 세션 소유자의 새 WebSocket 연결 (새로고침, 네트워크 끊김 후 재연결)
+// 아직 끊긴 줄 모르는 기존 연결은 닫고, 놓친 출력(replayAll 이면 남아 있는 스크롤백 전체)을 먼저 보낸 뒤 실시간 출력 재개
+func (t *Terminal) Attach(conn terminalConn, replayAll bool, client AuditClient) error {
+	t.writeMu.Lock()
+	defer t.writeMu.Unlock()
 
-	exitCode := 0
-	if lt.cmd.ProcessState != nil {
-		exitCode = lt.cmd.ProcessState.ExitCode()
+	if !t.IsAlive() {
+		return fmt.Errorf("이미 종료된 세션입니다: %s", t.sessionID)
+	}
+	t.stopDetachTimerLocked()
+	for _, p := range t.participants {
+		if p.Role == RoleOwner {
+			log.Printf("기존 연결을 새 연결로 교체: %s", t.sessionID)
+			t.removeLocked(p.conn)
+			p.conn.Close()
+			break
+		}
 	}
 
-	log.Printf("🏁 프로세스 종료: %s (PID: %d, 코드: %d, 에러: %v)",
-		lt.sessionID, lt.cmd.Process.Pid, exitCode, err)
+	owner := newParticipant(conn, t.owner, RoleOwner, client)
+	t.participants = append(t.participants, owner)
 
-	// 종료 메시지 전송
-	exitMessage := TerminalMessage{
-		Type: "exit",
-		Data: map[string]interface{}{
-			"message":   "터미널 세션이 종료되었습니다",
-			"code":      exitCode,
-			"sessionId": lt.sessionID,
-		},
+	from := t.sentOffset
+	if replayAll {
+		from = 0
 	}
+	t.replayLocked(owner, from, "세션에 다시 연결되었습니다")
+	t.announceLocked("join", owner)
+	t.auditParticipant(AuditSessionJoin, owner)
 
-	lt.conn.WriteJSON(exitMessage)
-	lt.Close()
+	go t.handleWebSocketInput(owner)
+	return nil
 }
 
-// resize - 터미널 크기 조정
-func (lt *LocalTerminal) resize(cols, rows int) {
-	log.Printf("🔧 터미널 크기 조정: %dx%d (세션: %s)", cols, rows, lt.sessionID)
+// Join - 다른 사용자가 실행 중인 세션에 참가 (함께 디버깅)
+// 남아 있는 스크롤백 전체를 먼저 보낸 뒤 다른 참가자와 같은 실시간 출력을 받음
+func (t *Terminal) Join(conn terminalConn, user, role string, client AuditClient) error {
+	if role != RoleModerator && role != RoleObserver {
+		return fmt.Errorf("알 수 없는 역할입니다: %s", role)
+	}
 
-	if runtime.GOOS != "windows" {
-		// Unix 계열에서만 동작
-		type winsize struct {
-			rows, cols, xpixel, ypixel uint16
-		}
+	t.writeMu.Lock()
+	defer t.writeMu.Unlock()
 
-		ws := winsize{
-			rows:   uint16(rows),
-			cols:   uint16(cols),
-			xpixel: 0,
-			ypixel: 0,
-		}
+	if !t.IsAlive() {
+		return fmt.Errorf("이미 종료된 세션입니다: %s", t.sessionID)
+	}
+	if limit := t.config.MaxParticipants; len(t.participants) >= limit {
+		return fmt.Errorf("세션 참가자는 최대 %d명입니다", limit)
+	}
+
+	p := newParticipant(conn, user, role, client)
+	if p.canInput() {
+		t.stopDetachTimerLocked()
+	}
+	t.participants = append(t.participants, p)
+	log.Printf("👥 세션 참가: %s (사용자: %s, 역할: %s, 참가자: %d명)", t.sessionID, user, role, len(t.participants))
+
+	t.replayLocked(p, 0, "세션에 참가했습니다")
+	t.announceLocked("join", p)
+	t.auditParticipant(AuditSessionJoin, p)
 
-		_, _, errno := syscall.Syscall(
-			syscall.SYS_IOCTL,
-			lt.pty.Fd(),
-			syscall.TIOCSWINSZ,
-			uintptr(unsafe.Pointer(&ws)),
-		)
+	go t.handleWebSocketInput(p)
+	return nil
+}
 
-		if errno != 0 {
-			log.Printf("터미널 크기 조정 실패: %v", errno)
+// replayLocked - 새 연결에 안내 메시지와 from 이후의 스크롤백 전송
+func (t *Terminal) replayLocked(p *participant, from int64, message string) {
+	missed, truncated := t.scrollback.Since(from)
+	log.Printf("🔁 세션 연결: %s (사용자: %s, 역할: %s, 재전송: %d 바이트, 잘림: %v)", t.sessionID, p.User, p.Role, len(missed), truncated)
+
+	// 쓰기 오류는 입력 고루틴이 연결 끊김으로 처리
+	p.conn.WriteJSON(TerminalMessage{Type: "system", Data: map[string]interface{}{
+		"message":      message,
+		"sessionId":    t.sessionID,
+		"role":         p.Role,
+		"participants": t.participantsLocked(),
+		"replayBytes":  len(missed),
+		"truncated":    truncated,
+		"time":         time.Now().Format("15:04:05"),
+	}})
+	for len(missed) > 0 {
+		chunk := missed[:min(len(missed), t.config.OutputBatchSize)]
+		if complete, _ := splitUTF8(chunk); !p.binary && len(complete) > 0 {
+			chunk = complete
 		}
-	} else {
-		// Windows에서는 크기 조정이 복잡함
-		log.Printf("Windows에서는 터미널 크기 조정이 지원되지 않습니다")
+		if err := p.writeOutput(chunk); err != nil {
+			break
+		}
+		missed = missed[len(chunk):]
+	}
+	if p.Role == RoleOwner {
+		t.sentOffset = t.scrollback.Total()
 	}
 }
 
-// Close - 터미널 세션 종료
-func (lt *LocalTerminal) Close() {
-	select {
-	case <-lt.done:
-		// 이미 닫힘
-		return
-	default:
-		log.Printf("터미널 세션 종료 중: %s", lt.sessionID)
-		close(lt.done)
-
-		// 리소스 정리
-		if lt.pty != nil {
-			lt.pty.Close()
+// announceLocked - 참가/퇴장(event: join / leave)을 다른 참가자에게 알림
+func (t *Terminal) announceLocked(event string, p *participant) {
+	message := fmt.Sprintf("%s 님이 참가했습니다 (%s)", p.User, p.Role)
+	if event == "leave" {
+		message = fmt.Sprintf("%s 님이 나갔습니다 (%s)", p.User, p.Role)
+	}
+	notice := TerminalMessage{Type: "system", Data: map[string]interface{}{
+		"message":      message,
+		"event":        event,
+		"user":         p.User,
+		"role":         p.Role,
+		"participants": t.participantsLocked(),
+		"time":         time.Now().Format("15:04:05"),
+	}}
+	for _, other := range t.participants {
+		if other != p {
+			other.conn.WriteJSON(notice)
 		}
+	}
+}
 
-		if lt.cmd != nil && lt.cmd.Process != nil {
-			// 프로세스 강제 종료
-			if runtime.GOOS == "windows" {
-				lt.cmd.Process.Kill()
-			} else {
-				// Unix 계열에서는 SIGTERM 먼저 시도
-				lt.cmd.Process.Signal(syscall.SIGTERM)
-				// 잠시 대기 후 SIGKILL
-				go func() {
-					// time.Sleep(2 * time.Second)
-					if lt.cmd.Process != nil {
-						lt.cmd.Process.Kill()
-					}
-				}()
+// detach - 참가자 연결이 끊김 (남은 참가자에게 알림)
+// 입력할 수 있는 참가자가 모두 끊기면 DetachGrace 동안 셸을 유지하고 재연결을 기다림
+func (t *Terminal) detach(conn terminalConn) {
+	grace := t.config.DetachGrace
+
+	t.writeMu.Lock()
+	p := t.removeLocked(conn)
+	if p == nil || !t.IsAlive() {
+		// 이미 새 연결로 교체되었거나 종료됨
+		t.writeMu.Unlock()
+		return
+	}
+	conn.Close()
+	log.Printf("참가자 연결 끊김: %s (사용자: %s, 역할: %s)", t.sessionID, p.User, p.Role)
+	t.announceLocked("leave", p)
+	t.auditParticipant(AuditSessionLeave, p)
+
+	waiting := !t.hasInputLocked()
+	if waiting && grace > 0 && t.detachTimer == nil {
+		t.detachTimer = time.AfterFunc(grace, func() {
+			if !t.IsAttached() {
+				log.Printf("⌛ 재연결 유예 시간 초과: %s", t.sessionID)
+				t.closeWith("detach_timeout", nil)
 			}
+		})
+	}
+	t.writeMu.Unlock()
+
+	if !waiting {
+		return
+	}
+	if grace <= 0 {
+		t.closeWith("client_disconnected", nil)
+		return
+	}
+	log.Printf("🔌 클라이언트 연결 끊김, %v 동안 재연결 대기: %s", grace, t.sessionID)
+}
+
+// auditParticipant - 참가/퇴장 감사 로그 (세션 시작 시 소유자는 session_start 로 기록)
+func (t *Terminal) auditParticipant(eventType string, p *participant) {
+	t.audit.emit(eventType, p.client, map[string]interface{}{
+		"user":     p.User,
+		"role":     p.Role,
+		"protocol": p.Protocol,
+	})
+}
+
+// removeLocked - 참가자 목록에서 연결 제거 (없으면 nil)
+func (t *Terminal) removeLocked(conn terminalConn) *participant {
+	for i, p := range t.participants {
+		if p.conn == conn {
+			t.participants = append(t.participants[:i:i], t.participants[i+1:]...)
+			return p
 		}
+	}
+	return nil
+}
 
-		if lt.conn != nil {
-			// 종료 메시지 전송 시도
-			finalMessage := TerminalMessage{
-				Type: "system",
-				Data: "터미널 세션이 종료되었습니다",
-			}
-			lt.conn.WriteJSON(finalMessage)
-			lt.conn.Close()
+// stopDetachTimerLocked - 재연결 유예 타이머 중지
+func (t *Terminal) stopDetachTimerLocked() {
+	if t.detachTimer != nil {
+		t.detachTimer.Stop()
+		t.detachTimer = nil
+	}
+}
+
+// hasInputLocked - 입력할 수 있는 참가자가 연결되어 있는지 확인
+func (t *Terminal) hasInputLocked() bool {
+	for _, p := range t.participants {
+		if p.canInput() {
+			return true
 		}
+	}
+	return false
+}
 
-		log.Printf("터미널 세션 정리 완료: %s", lt.sessionID)
+// participantsLocked - 참가자 정보 목록
+func (t *Terminal) participantsLocked() []Participant {
+	participants := make([]Participant, 0, len(t.participants))
+	for _, p := range t.participants {
+		participants = append(participants, p.Participant)
 	}
+	return participants
 }
 
-// IsAlive - 터미널이 살아있는지 확인
-func (lt *LocalTerminal) IsAlive() bool {
-	select {
-	case <-lt.done:
-		return false
-	default:
-		return lt.cmd != nil && lt.cmd.Process != nil
+// connections - 현재 연결 목록
+func (t *Terminal) connections() []terminalConn {
+	t.writeMu.Lock()
+	defer t.writeMu.Unlock()
+
+	conns := make([]terminalConn, 0, len(t.participants))
+	for _, p := range t.participants {
+		conns = append(conns, p.conn)
 	}
+	return conns
 }
 
-// SendMessage - 터미널에 메시지 전송 (외부에서 호출 가능)
-func (lt *LocalTerminal) SendMessage(msgType string, data interface{}) error {
-	message := TerminalMessage{
-		Type: msgType,
-		Data: data,
+// hasJSONParticipant - JSON 모드 참가자가 있는지 확인 (출력을 UTF-8 문자 단위로 잘라야 함)
+func (t *Terminal) hasJSONParticipant() bool {
+	t.writeMu.Lock()
+	defer t.writeMu.Unlock()
+
+	for _, p := range t.participants {
+		if !p.binary {
+			return true
+		}
 	}
-	return lt.conn.WriteJSON(message)
+	return false
 }
 
-// WriteToTerminal - 터미널에 직접 텍스트 작성
-func (lt *LocalTerminal) WriteToTerminal(text string) error {
-	if lt.pty == nil {
-		return fmt.Errorf("터미널이 초기화되지 않았습니다")
+// IsAttached - 입력할 수 있는 참가자(소유자 또는 moderator)가 연결되어 있는지 확인
+func (t *Terminal) IsAttached() bool {
+	t.writeMu.Lock()
+	defer t.writeMu.Unlock()
+	return t.hasInputLocked()
+}
+
+// Participants - 현재 연결된 참가자 목록
+func (t *Terminal) Participants() []Participant {
+	t.writeMu.Lock()
+	defer t.writeMu.Unlock()
+	return t.participantsLocked()
+}
+
+// SendMessage - 모든 참가자에게 메시지 전송 (외부에서 호출 가능, 아무도 연결되지 않은 동안은 버림)
+// 쓰기 오류는 각 연결의 입력 고루틴이 연결 끊김으로 처리하므로 첫 번째 오류만 반환
+func (t *Terminal) SendMessage(msgType string, data interface{}) error {
+	t.writeMu.Lock()
+	defer t.writeMu.Unlock()
+
+	var firstErr error
+	for _, p := range t.participants {
+		if err := p.conn.WriteJSON(TerminalMessage{Type: msgType, Data: data}); err != nil && firstErr == nil {
+			firstErr = err
+		}
 	}
-	_, err := lt.pty.WriteString(text)
+	return firstErr
+}
+
+// sendTo - 참가자 한 명에게만 메시지 전송 (pong, 에러 응답)
+func (t *Terminal) sendTo(conn terminalConn, msgType string, data interface{}) error {
+	t.writeMu.Lock()
+	defer t.writeMu.Unlock()
+	return conn.WriteJSON(TerminalMessage{Type: msgType, Data: data})
+}
+
+// sendOutput - 출력을 스크롤백에 기록하고 모든 참가자에게 전송
+// 전송에 실패한 연결 목록 반환
+func (t *Terminal) sendOutput(data []byte) (failed []terminalConn) {
+	t.writeMu.Lock()
+	defer t.writeMu.Unlock()
+
+	t.scrollback.Write(data)
+	for _, p := range t.participants {
+		if err := p.writeOutput(data); err != nil {
+			log.Printf("WebSocket 출력 전송 실패 (사용자: %s): %v", p.User, err)
+			failed = append(failed, p.conn)
+			continue
+		}
+		if p.Role == RoleOwner {
+			t.sentOffset = t.scrollback.Total()
+		}
+	}
+	return failed
+}
+
+// WriteToTerminal - 터미널에 직접 텍스트 작성
+func (t *Terminal) WriteToTerminal(text string) error {
+	t.recorder.Input([]byte(text))
+	_, err := io.WriteString(t.backend, text)
 	return err
 }
 
+// OutputStats - 출력 전송 통계
+func (t *Terminal) OutputStats() OutputStats {
+	return t.output.Stats()
+}
+
 // GetInfo - 터미널 정보 반환
-func (lt *LocalTerminal) GetInfo() map[string]interface{} {
+func (t *Terminal) GetInfo() map[string]interface{} {
 	info := map[string]interface{}{
-		"sessionId": lt.sessionID,
-		"alive":     lt.IsAlive(),
+		"sessionId":    t.sessionID,
+		"user":         t.owner,
+		"alive":        t.IsAlive(),
+		"attached":     t.IsAttached(),
+		"participants": t.Participants(),
+		"output":       t.OutputStats(),
+	}
+	if t.recorder != nil {
+		info["recording"] = t.recorder.Info()
 	}
+	for key, value := range t.backend.Info() {
+		info[key] = value
+	}
+	return info
+}
 
-	if lt.cmd != nil && lt.cmd.Process != nil {
-		info["pid"] = lt.cmd.Process.Pid
+// parseResizeData - resize 메시지의 data({cols, rows}) 파싱
+func parseResizeData(data interface{}) (cols, rows int, ok bool) {
+	resizeData, ok := data.(map[string]interface{})
+	if !ok {
+		return 0, 0, false
 	}
+	c, hasC := resizeData["cols"].(float64)
+	r, hasR := resizeData["rows"].(float64)
+	if !hasC || !hasR || c < 1 || r < 1 {
+		return 0, 0, false
+	}
+	return int(c), int(r), true
+}
 
-	return info
+// splitUTF8 - 끝에 잘린 UTF-8 문자가 있으면 완성된 부분과 나머지로 분리
+func splitUTF8(p []byte) (complete, rest []byte) {
+	for i := len(p) - 1; i >= 0 && i > len(p)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(p[i]) {
+			if !utf8.FullRune(p[i:]) {
+				return p[:i], p[i:]
+			}
+			break
+		}
+	}
+	return p, nil
+}
+
+// ttySignal - 시그널 API 가 없는 백엔드용: TTY 제어 문자로 전경 프로세스에 시그널 전달
+func ttySignal(w io.Writer, sig string) error {
+	var control string
+	switch sig {
+	case "INT":
+		control = "\x03" // Ctrl+C
+	case "QUIT":
+		control = "\x1c" // Ctrl+\
+	case "TSTP":
+		control = "\x1a" // Ctrl+Z
+	default:
+		return fmt.Errorf("%w: %s", errSignalUnsupported, sig)
+	}
+	_, err := io.WriteString(w, control)
+	return err
 }
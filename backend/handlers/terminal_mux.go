@@ -0,0 +1,463 @@
+package handlers
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// 다중화 WebSocket (/api/ws/terminals)
+//
+// 클라이언트 → 서버 (텍스트 프레임, channel 은 클라이언트가 정하는 1 이상의 번호)
+//
+//	{"type":"open","channel":1,"data":{"containerId":"...","window":65536}}
+//	{"type":"open","channel":2,"data":{"containerId":"...","session":"...","token":"...","replay":"all"}} (재연결)
+//	{"type":"open","channel":3,"data":{"containerId":"...","session":"...","role":"observer","token":"..."}} (다른 사용자의 세션에 참가)
+//	{"type":"input"|"resize"|"signal"|"ping"|"command","channel":1,"data":...} (TerminalMessage 와 같음)
+//	{"type":"ack","channel":1,"data":32768} (처리한 출력 바이트, window 를 지정한 채널만)
+//	{"type":"close","channel":1}
+//
+// 서버 → 클라이언트: TerminalMessage 에 channel 이 붙은 메시지와 opened / closed
+// terminalBinaryProtocol 을 협상하면 출력/입력은 바이너리 프레임 [채널 번호 4바이트 big-endian][데이터]
+
+// MuxMessage - 다중화 WebSocket 메시지 (채널 번호가 붙은 TerminalMessage)
+type MuxMessage struct {
+	Type    string      `json:"type"`
+	Channel uint32      `json:"channel"`
+	Data    interface{} `json:"data,omitempty"`
+}
+
+// muxOpenRequest - open 메시지의 data
+type muxOpenRequest struct {
+	ContainerID string `json:"containerId"`
+	Window      int64  `json:"window"`  // ack 없이 보낼 수 있는 최대 출력 바이트 (0 이면 흐름 제어 안 함)
+	Session     string `json:"session"` // 재연결할 세션 ID
+	Token       string `json:"token"`   // 재연결 토큰 (role 이 있으면 참가 토큰)
+	Role        string `json:"role"`    // observer / moderator 이면 재연결 대신 참가
+	Replay      string `json:"replay"`  // all 이면 스크롤백 전체 재전송
+}
+
+// flowControlledConn - 출력 흐름 제어가 있는 연결 (다중화 채널)
+type flowControlledConn interface {
+	waitCredit(n int, done <-chan bool)
+}
+
+// terminalMux - WebSocket 하나에 여러 터미널 채널
+type terminalMux struct {
+	handler *TeleportHandler
+	request *http.Request // 채널을 열 때 사용자/컨텍스트 확인용
+	conn    *websocket.Conn
+	binary  bool
+	writeMu sync.Mutex // WebSocket 동시 쓰기 방지 (채널마다 고루틴)
+
+	mu       sync.Mutex
+	channels map[uint32]*muxChannel
+}
+
+// muxFrame - 채널로 전달할 입력 프레임
+type muxFrame struct {
+	messageType int
+	data        []byte
+}
+
+// muxChannel - 다중화 WebSocket 의 채널 하나 (terminalConn 구현)
+type muxChannel struct {
+	mux   *terminalMux
+	id    uint32
+	input chan muxFrame
+
+	mu       sync.Mutex
+	window   int64         // 0 이면 흐름 제어 안 함
+	unacked  int64         // 보냈지만 ack 받지 못한 출력 바이트
+	creditCh chan struct{} // ack 또는 종료 알림
+	closed   bool
+	closeErr error
+	done     chan struct{}
+}
+
+// HandleTerminalMux - 여러 터미널을 WebSocket 하나로 다중화
+func (h *TeleportHandler) HandleTerminalMux(w http.ResponseWriter, r *http.Request) {
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Printf("WebSocket 업그레이드 실패: %v", err)
+		return
+	}
+	log.Printf("다중화 WebSocket 연결 (프로토콜: %q)", conn.Subprotocol())
+
+	m := &terminalMux{
+		handler:  h,
+		request:  r,
+		conn:     conn,
+		binary:   conn.Subprotocol() == terminalBinaryProtocol,
+		channels: make(map[uint32]*muxChannel),
+	}
+	defer m.shutdown()
+
+	for {
+		messageType, data, err := conn.ReadMessage()
+		if err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway, websocket.CloseNoStatusReceived) {
+				log.Printf("다중화 WebSocket 입력 오류: %v", err)
+			}
+			return
+		}
+
+		// 바이너리 프레임: [채널 번호][입력]
+		if messageType == websocket.BinaryMessage {
+			if !m.binary || len(data) < 4 {
+				continue
+			}
+			if ch := m.channel(binary.BigEndian.Uint32(data)); ch != nil {
+				ch.deliver(muxFrame{websocket.BinaryMessage, data[4:]})
+			}
+			continue
+		}
+
+		var message struct {
+			Type    string          `json:"type"`
+			Channel uint32          `json:"channel"`
+			Data    json.RawMessage `json:"data"`
+		}
+		if err := json.Unmarshal(data, &message); err != nil {
+			log.Printf("잘못된 메시지 형식: %v", err)
+			continue
+		}
+
+		switch message.Type {
+		case "open":
+			m.open(message.Channel, message.Data)
+
+		case "ack":
+			var n int64
+			if ch := m.channel(message.Channel); ch != nil && json.Unmarshal(message.Data, &n) == nil {
+				ch.ack(n)
+			}
+
+		case "close":
+			// 사용자가 닫은 채널은 재연결을 기다리지 않고 종료
+			if ch := m.channel(message.Channel); ch != nil {
+				ch.shutdown(&websocket.CloseError{Code: websocket.CloseNormalClosure})
+			}
+
+		case "ping":
+			if message.Channel == 0 {
+				m.writeJSON(MuxMessage{Type: "pong", Data: "다중화 연결 정상"})
+				continue
+			}
+			fallthrough
+
+		default:
+			// 나머지는 채널의 터미널로 그대로 전달
+			ch := m.channel(message.Channel)
+			if ch == nil {
+				m.sendError(message.Channel, fmt.Sprintf("열려 있지 않은 채널입니다: %d", message.Channel))
+				continue
+			}
+			forwarded, _ := json.Marshal(TerminalMessage{Type: message.Type, Data: message.Data})
+			ch.deliver(muxFrame{websocket.TextMessage, forwarded})
+		}
+	}
+}
+
+// open - 채널 열기 (새 터미널 또는 끊긴 세션 재연결)
+func (m *terminalMux) open(id uint32, data json.RawMessage) {
+	var req muxOpenRequest
+	if err := json.Unmarshal(data, &req); err != nil || req.ContainerID == "" {
+		m.sendError(id, "open 메시지에 containerId 가 필요합니다")
+		return
+	}
+	if id == 0 {
+		m.sendError(id, "채널 번호는 1 이상이어야 합니다")
+		return
+	}
+
+	ch, err := m.register(id, req.Window)
+	if err != nil {
+		m.sendError(id, err.Error())
+		return
+	}
+	terminals := m.handler.terminalHandler
+
+	if req.Session != "" {
+		terminal, status, err := terminals.findReattachable(req.ContainerID, req.Session, req.Role, req.Token)
+		if err != nil {
+			log.Printf("세션 연결 거부: %v", err)
+			terminals.auditError(terminals.requestClient(m.request), req.ContainerID, req.Session, map[string]interface{}{
+				"message": fmt.Sprintf("세션 연결 거부: %v", err),
+				"status":  status,
+				"role":    req.Role,
+			})
+			ch.fail(err.Error())
+			return
+		}
+		m.writeJSON(MuxMessage{Type: "opened", Channel: id, Data: map[string]interface{}{"containerId": req.ContainerID, "sessionId": req.Session}})
+		if req.Role != "" {
+			terminals.join(ch, terminal, terminals.requestClient(m.request), req.Role)
+			return
+		}
+		terminals.reattach(ch, terminal, req.Replay == "all", terminals.requestClient(m.request))
+		return
+	}
+
+	// 접속 대상 확인과 터미널 시작은 시간이 걸리므로 채널마다 고루틴
+	go func() {
+		target, status, message := m.handler.terminalTarget(m.request, req.ContainerID)
+		if target == nil {
+			terminals.auditError(terminals.requestClient(m.request), req.ContainerID, "", map[string]interface{}{
+				"message": message,
+				"status":  status,
+			})
+			ch.fail(message)
+			return
+		}
+		log.Printf("다중화 채널 %d 터미널 요청: 컨테이너 %s (%s, 클러스터: %s)", id, target.Container.Name, req.ContainerID, target.Cluster.Name)
+
+		m.writeJSON(MuxMessage{Type: "opened", Channel: id, Data: map[string]interface{}{"containerId": req.ContainerID}})
+		session := terminals.AddSession(req.ContainerID, target.Cluster.Name, target.User, terminals.requestClient(m.request))
+		terminals.runSession(ch, target, session)
+	}()
+}
+
+// register - 채널 등록
+func (m *terminalMux) register(id uint32, window int64) (*muxChannel, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, exists := m.channels[id]; exists {
+		return nil, fmt.Errorf("이미 사용 중인 채널입니다: %d", id)
+	}
+	if limit := m.handler.terminalHandler.config.MuxMaxChannels; len(m.channels) >= limit {
+		return nil, fmt.Errorf("채널은 최대 %d개까지 열 수 있습니다", limit)
+	}
+	if window < 0 {
+		window = 0
+	}
+
+	ch := &muxChannel{
+		mux:      m,
+		id:       id,
+		input:    make(chan muxFrame, 64),
+		window:   window,
+		creditCh: make(chan struct{}, 1),
+		done:     make(chan struct{}),
+	}
+	m.channels[id] = ch
+	return ch, nil
+}
+
+// channel - 열린 채널 조회
+func (m *terminalMux) channel(id uint32) *muxChannel {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.channels[id]
+}
+
+// remove - 채널 제거 (이미 제거되었으면 false)
+func (m *terminalMux) remove(ch *muxChannel) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.channels[ch.id] != ch {
+		return false
+	}
+	delete(m.channels, ch.id)
+	return true
+}
+
+// shutdown - WebSocket 이 끊김: 모든 채널의 터미널은 재연결 대기 상태가 됨
+func (m *terminalMux) shutdown() {
+	m.mu.Lock()
+	channels := make([]*muxChannel, 0, len(m.channels))
+	for _, ch := range m.channels {
+		channels = append(channels, ch)
+	}
+	m.mu.Unlock()
+
+	for _, ch := range channels {
+		ch.shutdown(&websocket.CloseError{Code: websocket.CloseAbnormalClosure, Text: "다중화 연결 끊김"})
+	}
+	m.conn.Close()
+	log.Printf("다중화 WebSocket 종료 (채널 %d개)", len(channels))
+}
+
+// writeJSON - 텍스트 프레임 전송
+func (m *terminalMux) writeJSON(message MuxMessage) error {
+	m.writeMu.Lock()
+	defer m.writeMu.Unlock()
+	return m.conn.WriteJSON(message)
+}
+
+// writeBinary - 바이너리 프레임 전송 (채널 번호를 앞에 붙임)
+func (m *terminalMux) writeBinary(id uint32, data []byte) error {
+	frame := make([]byte, 4+len(data))
+	binary.BigEndian.PutUint32(frame, id)
+	copy(frame[4:], data)
+
+	m.writeMu.Lock()
+	defer m.writeMu.Unlock()
+	return m.conn.WriteMessage(websocket.BinaryMessage, frame)
+}
+
+// sendError - 채널에 에러 메시지 전송
+func (m *terminalMux) sendError(id uint32, message string) {
+	m.writeJSON(MuxMessage{
+		Type:    "error",
+		Channel: id,
+		Data: map[string]interface{}{
+			"message": message,
+			"time":    time.Now().Format("15:04:05"),
+		},
+	})
+}
+
+// deliver - 클라이언트 입력을 채널로 전달
+// 읽기 루프는 모든 채널이 함께 쓰므로 기다리지 않음: 입력 버퍼가 찬 채널(터미널이 입력을 받지 않음)은 끊음
+func (ch *muxChannel) deliver(frame muxFrame) {
+	select {
+	case ch.input <- frame:
+	case <-ch.done:
+	default:
+		ch.overflow()
+	}
+}
+
+// overflow - 입력 버퍼가 찬 채널 끊기
+// 비정상 종료로 처리하므로 세션은 재연결을 기다림 (클라이언트는 session/token 으로 다시 열 수 있음)
+func (ch *muxChannel) overflow() {
+	if !ch.shutdown(fmt.Errorf("다중화 채널 %d 입력 버퍼 초과", ch.id)) {
+		return
+	}
+	log.Printf("⚠️ 다중화 채널 %d 입력 버퍼 초과, 채널을 끊음", ch.id)
+	ch.mux.sendError(ch.id, "터미널이 입력을 처리하지 못해 채널을 끊었습니다")
+	if ch.mux.remove(ch) {
+		ch.mux.writeJSON(MuxMessage{Type: "closed", Channel: ch.id})
+	}
+}
+
+// ReadMessage - 채널 입력 읽기 (채널이 닫히면 닫힌 이유를 에러로 반환)
+func (ch *muxChannel) ReadMessage() (int, []byte, error) {
+	select {
+	case frame := <-ch.input:
+		return frame.messageType, frame.data, nil
+	case <-ch.done:
+		return 0, nil, ch.closeErr
+	}
+}
+
+// WriteMessage - 바이너리 출력 전송
+func (ch *muxChannel) WriteMessage(messageType int, data []byte) error {
+	if err := ch.consume(len(data)); err != nil {
+		return err
+	}
+	if messageType != websocket.BinaryMessage {
+		return ch.mux.writeJSON(MuxMessage{Type: "output", Channel: ch.id, Data: string(data)})
+	}
+	return ch.mux.writeBinary(ch.id, data)
+}
+
+// WriteJSON - TerminalMessage 에 채널 번호를 붙여 전송
+func (ch *muxChannel) WriteJSON(v interface{}) error {
+	message, ok := v.(TerminalMessage)
+	if !ok {
+		return fmt.Errorf("지원하지 않는 메시지 형식: %T", v)
+	}
+	size := 0
+	if output, ok := message.Data.(string); ok && message.Type == "output" {
+		size = len(output)
+	}
+	if err := ch.consume(size); err != nil {
+		return err
+	}
+	return ch.mux.writeJSON(MuxMessage{Type: message.Type, Channel: ch.id, Data: message.Data})
+}
+
+// Subprotocol - 다중화 WebSocket 의 서브프로토콜
+func (ch *muxChannel) Subprotocol() string {
+	return ch.mux.conn.Subprotocol()
+}
+
+// Close - 터미널이 채널 사용을 마침 (종료, 다른 연결로 재연결 등): 클라이언트에 closed 전송
+func (ch *muxChannel) Close() error {
+	ch.shutdown(&websocket.CloseError{Code: websocket.CloseNormalClosure})
+	if ch.mux.remove(ch) {
+		return ch.mux.writeJSON(MuxMessage{Type: "closed", Channel: ch.id})
+	}
+	return nil
+}
+
+// fail - 채널을 열지 못함
+func (ch *muxChannel) fail(message string) {
+	ch.mux.sendError(ch.id, message)
+	ch.Close()
+}
+
+// shutdown - 채널 입력/출력 중단 (처음 호출한 경우 true)
+func (ch *muxChannel) shutdown(err error) bool {
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+	if ch.closed {
+		return false
+	}
+	ch.closed = true
+	ch.closeErr = err
+	close(ch.done)
+	ch.notifyCredit()
+	return true
+}
+
+// consume - 보낸 출력을 ack 대기 바이트에 더함 (닫힌 채널이면 에러)
+func (ch *muxChannel) consume(n int) error {
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+	if ch.closed {
+		return ch.closeErr
+	}
+	if ch.window > 0 {
+		ch.unacked += int64(n)
+	}
+	return nil
+}
+
+// waitCredit - window 에 n 바이트를 보낼 자리가 생길 때까지 대기
+// ack 대기 중인 출력이 없으면 window 보다 큰 프레임도 하나는 보냄
+// (대기하는 동안 터미널 출력 큐가 차면 출력 정책에 따라 읽기를 멈추거나 버림)
+func (ch *muxChannel) waitCredit(n int, done <-chan bool) {
+	for {
+		ch.mu.Lock()
+		ready := ch.window == 0 || ch.closed || ch.unacked == 0 || ch.unacked+int64(n) <= ch.window
+		ch.mu.Unlock()
+		if ready {
+			return
+		}
+
+		select {
+		case <-ch.creditCh:
+		case <-done:
+			return
+		}
+	}
+}
+
+// ack - 클라이언트가 처리한 출력 바이트
+func (ch *muxChannel) ack(n int64) {
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+	ch.unacked -= n
+	if ch.unacked < 0 {
+		ch.unacked = 0
+	}
+	ch.notifyCredit()
+}
+
+// notifyCredit - waitCredit 깨우기
+func (ch *muxChannel) notifyCredit() {
+	select {
+	case ch.creditCh <- struct{}{}:
+	default:
+	}
+}
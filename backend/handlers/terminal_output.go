@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"log"
+	"sync"
+	"time"
+)
+
+// 출력 큐가 가득 찼을 때의 처리 방식
+const (
+	OutputPolicyBlock = "block" // 백엔드 읽기를 멈춤 (PTY 버퍼가 차면 셸 출력도 멈춤)
+	OutputPolicyDrop  = "drop"  // 새 출력을 버리고 클라이언트에 알림
+)
+
+// OutputStats - 터미널 출력 전송 통계
+type OutputStats struct {
+	ReadBytes      int64 `json:"readBytes"`      // 백엔드에서 읽은 바이트
+	SentBytes      int64 `json:"sentBytes"`      // WebSocket 으로 보낸 바이트
+	Frames         int64 `json:"frames"`         // 보낸 출력 프레임 수
+	DroppedBytes   int64 `json:"droppedBytes"`   // drop 정책으로 버린 바이트
+	BlockedCount   int64 `json:"blockedCount"`   // block 정책으로 읽기를 멈춘 횟수
+	BlockedMillis  int64 `json:"blockedMs"`      // 읽기를 멈춘 총 시간 (전송 지연)
+	MaxQueuedBytes int   `json:"maxQueuedBytes"` // 전송 대기 출력의 최대 크기
+}
+
+// add - 통계 합산
+func (s *OutputStats) add(other OutputStats) {
+	s.ReadBytes += other.ReadBytes
+	s.SentBytes += other.SentBytes
+	s.Frames += other.Frames
+	s.DroppedBytes += other.DroppedBytes
+	s.BlockedCount += other.BlockedCount
+	s.BlockedMillis += other.BlockedMillis
+	if other.MaxQueuedBytes > s.MaxQueuedBytes {
+		s.MaxQueuedBytes = other.MaxQueuedBytes
+	}
+}
+
+// outputQueue - 백엔드 출력과 WebSocket 전송 사이의 제한된 버퍼
+// 읽기 고루틴이 push 하고, 전송 고루틴이 짧은 간격으로 모아서 take
+type outputQueue struct {
+	mu      sync.Mutex
+	space   *sync.Cond // 큐에 자리가 생김 (block 정책)
+	pending []byte
+	limit   int
+	policy  string
+
+	inputClosed bool          // 백엔드 출력이 끝남 (남은 출력만 전송)
+	closed      bool          // 터미널 종료 (더 이상 전송하지 않음)
+	notify      chan struct{} // 새 출력 도착 알림
+	drained     chan struct{} // 입력이 끝나고 남은 출력을 모두 보냄
+
+	unreported int64 // 아직 클라이언트에 알리지 않은 버린 바이트
+	stats      OutputStats
+}
+
+// newOutputQueue - 출력 큐 생성 (알 수 없는 정책은 block)
+func newOutputQueue(limit int, policy string) *outputQueue {
+	if policy != OutputPolicyBlock && policy != OutputPolicyDrop {
+		log.Printf("⚠️ 알 수 없는 출력 정책 %q, %s 사용", policy, OutputPolicyBlock)
+		policy = OutputPolicyBlock
+	}
+	q := &outputQueue{
+		limit:   limit,
+		policy:  policy,
+		notify:  make(chan struct{}, 1),
+		drained: make(chan struct{}),
+	}
+	q.space = sync.NewCond(&q.mu)
+	return q
+}
+
+// push - 출력 추가 (터미널이 종료되었으면 false)
+// block 정책에서는 큐에 자리가 생길 때까지 대기
+func (q *outputQueue) push(data []byte) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.stats.ReadBytes += int64(len(data))
+	if len(q.pending) > 0 && len(q.pending)+len(data) > q.limit {
+		if q.policy == OutputPolicyDrop {
+			q.stats.DroppedBytes += int64(len(data))
+			q.unreported += int64(len(data))
+			q.signal()
+			return !q.closed
+		}
+
+		start := time.Now()
+		q.stats.BlockedCount++
+		for !q.closed && len(q.pending) > 0 && len(q.pending)+len(data) > q.limit {
+			q.space.Wait()
+		}
+		q.stats.BlockedMillis += time.Since(start).Milliseconds()
+	}
+	if q.closed {
+		return false
+	}
+
+	q.pending = append(q.pending, data...)
+	if len(q.pending) > q.stats.MaxQueuedBytes {
+		q.stats.MaxQueuedBytes = len(q.pending)
+	}
+	q.signal()
+	return true
+}
+
+// take - 최대 max 바이트 꺼내기
+// utf8Safe 이면 끝에 잘린 UTF-8 문자는 다음 출력과 합쳐서 보내도록 남겨 둠 (JSON 모드)
+func (q *outputQueue) take(max int, utf8Safe bool) (data []byte, dropped int64) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	dropped, q.unreported = q.unreported, 0
+	chunk := q.pending
+	if len(chunk) > max {
+		chunk = chunk[:max]
+	}
+	if utf8Safe && !q.inputClosed {
+		chunk, _ = splitUTF8(chunk)
+	}
+	if len(chunk) == 0 {
+		return nil, dropped
+	}
+
+	data = append([]byte(nil), chunk...)
+	q.pending = q.pending[len(chunk):]
+	if len(q.pending) == 0 {
+		q.pending = nil
+	}
+	q.stats.SentBytes += int64(len(data))
+	q.stats.Frames++
+	q.space.Broadcast()
+	return data, dropped
+}
+
+// size - 전송 대기 중인 바이트
+func (q *outputQueue) size() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.pending)
+}
+
+// isDrained - 입력이 끝나고 남은 출력이 없으면 true
+func (q *outputQueue) isDrained() bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.inputClosed && len(q.pending) == 0 && q.unreported == 0
+}
+
+// closeInput - 백엔드 출력 끝 (전송 고루틴이 남은 출력을 보낸 뒤 drained 를 닫음)
+func (q *outputQueue) closeInput() {
+	q.mu.Lock()
+	q.inputClosed = true
+	q.signal()
+	q.mu.Unlock()
+}
+
+// close - 터미널 종료 (대기 중인 push 깨움)
+func (q *outputQueue) close() {
+	q.mu.Lock()
+	q.closed = true
+	q.space.Broadcast()
+	q.mu.Unlock()
+}
+
+// Stats - 현재까지의 출력 통계
+func (q *outputQueue) Stats() OutputStats {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.stats
+}
+
+// signal - 전송 고루틴 깨우기 (이미 알림이 있으면 생략)
+func (q *outputQueue) signal() {
+	select {
+	case q.notify <- struct{}{}:
+	default:
+	}
+}
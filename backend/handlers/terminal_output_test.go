@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+)
+
+func TestOutputQueueDropPolicy(t *testing.T) {
+	q := newOutputQueue(10, OutputPolicyDrop)
+
+	q.push([]byte("12345678"))
+	q.push([]byte("abcde")) // 넘치므로 버림
+	q.push([]byte("xy"))
+
+	data, dropped := q.take(100, false)
+	if string(data) != "12345678xy" || dropped != 5 {
+		t.Errorf("take = %q, %d (want %q, 5)", data, dropped, "12345678xy")
+	}
+	if _, dropped := q.take(100, false); dropped != 0 {
+		t.Errorf("버린 바이트는 한 번만 알려야 함: %d", dropped)
+	}
+
+	stats := q.Stats()
+	if stats.ReadBytes != 15 || stats.SentBytes != 10 || stats.DroppedBytes != 5 || stats.Frames != 1 {
+		t.Errorf("통계 = %+v", stats)
+	}
+}
+
+func TestOutputQueueBlockPolicy(t *testing.T) {
+	q := newOutputQueue(10, OutputPolicyBlock)
+	q.push([]byte("12345678"))
+
+	pushed := make(chan bool)
+	go func() { pushed <- q.push([]byte("abcde")) }()
+
+	select {
+	case <-pushed:
+		t.Fatal("큐가 찼는데 push 가 기다리지 않음")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// 자리가 생기면 이어서 추가
+	if data, _ := q.take(4, false); string(data) != "1234" {
+		t.Errorf("take = %q", data)
+	}
+	if ok := <-pushed; !ok {
+		t.Fatal("push 실패")
+	}
+	if data, _ := q.take(100, false); string(data) != "5678abcde" {
+		t.Errorf("take = %q", data)
+	}
+	if stats := q.Stats(); stats.BlockedCount != 1 || stats.DroppedBytes != 0 {
+		t.Errorf("통계 = %+v", stats)
+	}
+
+	// 터미널이 종료되면 기다리던 push 는 false
+	q.push([]byte("0123456789"))
+	go func() { pushed <- q.push([]byte("z")) }()
+	time.Sleep(20 * time.Millisecond)
+	q.close()
+	select {
+	case ok := <-pushed:
+		if ok {
+			t.Error("종료된 큐에 push 성공")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("close 가 기다리던 push 를 깨우지 않음")
+	}
+}
+
+func TestOutputQueueUTF8Boundary(t *testing.T) {
+	q := newOutputQueue(100, OutputPolicyBlock)
+	han := []byte("가") // 3바이트
+
+	q.push(append([]byte("a"), han[:2]...))
+	// JSON 모드는 잘린 문자를 남겨 둠
+	if data, _ := q.take(100, true); string(data) != "a" {
+		t.Errorf("take = %q, want %q", data, "a")
+	}
+	q.push(han[2:])
+	if data, _ := q.take(100, true); string(data) != "가" {
+		t.Errorf("take = %q, want %q", data, "가")
+	}
+
+	// 출력이 끝났으면 잘린 문자도 그대로 보냄
+	q.push(han[:1])
+	q.closeInput()
+	if data, _ := q.take(100, true); len(data) != 1 {
+		t.Errorf("take = %q", data)
+	}
+	if !q.isDrained() {
+		t.Error("남은 출력이 없는데 drained 가 아님")
+	}
+}
@@ -0,0 +1,521 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/Heo-YJ/teleport-opensource/config"
+)
+
+// fakeBackend - 테스트용 TerminalBackend (출력은 emit 으로 넣고, 입력/크기 조정/시그널/호출 순서를 기록)
+type fakeBackend struct {
+	output   chan []byte
+	closed   chan struct{}
+	exitCode int
+
+	mu        sync.Mutex
+	input     strings.Builder
+	resizes   []string
+	signals   []string
+	calls     []string // start, wait, close
+	closeOnce sync.Once
+}
+
+func newFakeBackend() *fakeBackend {
+	return &fakeBackend{output: make(chan []byte, 64), closed: make(chan struct{})}
+}
+
+// emit - 셸 출력
+func (b *fakeBackend) emit(s string) {
+	b.output <- []byte(s)
+}
+
+// exit - 셸 종료 (남은 출력을 읽은 뒤 io.EOF)
+func (b *fakeBackend) exit(code int) {
+	b.mu.Lock()
+	b.exitCode = code
+	b.mu.Unlock()
+	close(b.output)
+}
+
+func (b *fakeBackend) record(call string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.calls = append(b.calls, call)
+}
+
+func (b *fakeBackend) Start(ctx context.Context, cols, rows int) error {
+	b.record("start")
+	return nil
+}
+
+func (b *fakeBackend) Read(p []byte) (int, error) {
+	select {
+	case data, ok := <-b.output:
+		if !ok {
+			return 0, io.EOF
+		}
+		return copy(p, data), nil
+	case <-b.closed:
+		return 0, fmt.Errorf("backend closed")
+	}
+}
+
+func (b *fakeBackend) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.input.Write(p)
+}
+
+func (b *fakeBackend) Resize(cols, rows int) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.resizes = append(b.resizes, fmt.Sprintf("%dx%d", cols, rows))
+	return nil
+}
+
+func (b *fakeBackend) Signal(sig string) error {
+	if sig != "INT" && sig != "TERM" {
+		return fmt.Errorf("%w: %s", errSignalUnsupported, sig)
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.signals = append(b.signals, sig)
+	return nil
+}
+
+func (b *fakeBackend) Wait() (int, error) {
+	b.record("wait")
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.exitCode, nil
+}
+
+func (b *fakeBackend) Close() error {
+	b.closeOnce.Do(func() {
+		b.record("close")
+		close(b.closed)
+	})
+	return nil
+}
+
+func (b *fakeBackend) Info() map[string]interface{} {
+	return map[string]interface{}{"backend": "fake"}
+}
+
+func (b *fakeBackend) inputString() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.input.String()
+}
+
+func (b *fakeBackend) snapshot() (resizes, signals, calls []string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]string(nil), b.resizes...), append([]string(nil), b.signals...), append([]string(nil), b.calls...)
+}
+
+// fakeConn - 테스트용 terminalConn (클라이언트 쪽 메시지는 send 로 넣고, 서버가 보낸 메시지를 기록)
+type fakeConn struct {
+	protocol string
+	in       chan fakeFrame
+	closed   chan struct{}
+
+	mu        sync.Mutex
+	messages  []TerminalMessage // 텍스트 메시지 (JSON 으로 한 번 왕복한 형태)
+	binary    []byte            // 바이너리 출력을 이어 붙인 것
+	log       []string          // 메시지 종류와 close 순서
+	closeOnce sync.Once
+}
+
+type fakeFrame struct {
+	messageType int
+	data        []byte
+}
+
+func newFakeConn(protocol string) *fakeConn {
+	return &fakeConn{protocol: protocol, in: make(chan fakeFrame, 64), closed: make(chan struct{})}
+}
+
+// send - 클라이언트 메시지
+func (c *fakeConn) send(msgType string, data interface{}) {
+	frame, _ := json.Marshal(TerminalMessage{Type: msgType, Data: data})
+	c.in <- fakeFrame{websocket.TextMessage, frame}
+}
+
+// disconnect - 클라이언트 연결 끊김 (새로고침, 네트워크 끊김)
+func (c *fakeConn) disconnect() {
+	c.Close()
+}
+
+// hangUp - 사용자가 터미널을 닫음 (정상 종료)
+func (c *fakeConn) hangUp() {
+	c.in <- fakeFrame{-1, nil}
+}
+
+func (c *fakeConn) ReadMessage() (int, []byte, error) {
+	select {
+	case frame := <-c.in:
+		if frame.messageType == -1 {
+			return 0, nil, &websocket.CloseError{Code: websocket.CloseNormalClosure}
+		}
+		return frame.messageType, frame.data, nil
+	case <-c.closed:
+		return 0, nil, &websocket.CloseError{Code: websocket.CloseAbnormalClosure}
+	}
+}
+
+func (c *fakeConn) WriteMessage(messageType int, data []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.isClosed() {
+		return websocket.ErrCloseSent
+	}
+	c.binary = append(c.binary, data...)
+	c.log = append(c.log, "binary")
+	return nil
+}
+
+func (c *fakeConn) WriteJSON(v interface{}) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.isClosed() {
+		return websocket.ErrCloseSent
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	var message TerminalMessage
+	json.Unmarshal(data, &message)
+	c.messages = append(c.messages, message)
+	c.log = append(c.log, message.Type)
+	return nil
+}
+
+func (c *fakeConn) Subprotocol() string {
+	return c.protocol
+}
+
+func (c *fakeConn) Close() error {
+	c.closeOnce.Do(func() {
+		c.mu.Lock()
+		c.log = append(c.log, "close")
+		c.mu.Unlock()
+		close(c.closed)
+	})
+	return nil
+}
+
+func (c *fakeConn) isClosed() bool {
+	select {
+	case <-c.closed:
+		return true
+	default:
+		return false
+	}
+}
+
+// output - 받은 터미널 출력 (JSON output 메시지 + 바이너리 프레임)
+func (c *fakeConn) output() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var out strings.Builder
+	for _, m := range c.messages {
+		if m.Type == "output" {
+			out.WriteString(m.Data.(string))
+		}
+	}
+	out.Write(c.binary)
+	return out.String()
+}
+
+// find - 마지막으로 받은 msgType 메시지
+func (c *fakeConn) find(msgType string) (TerminalMessage, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i := len(c.messages) - 1; i >= 0; i-- {
+		if c.messages[i].Type == msgType {
+			return c.messages[i], true
+		}
+	}
+	return TerminalMessage{}, false
+}
+
+// count - 받은 msgType 메시지 수
+func (c *fakeConn) count(msgType string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	n := 0
+	for _, m := range c.messages {
+		if m.Type == msgType {
+			n++
+		}
+	}
+	return n
+}
+
+func (c *fakeConn) events() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]string(nil), c.log...)
+}
+
+// testTerminalConfig - 테스트용 터미널 설정 (출력을 바로 보냄)
+func testTerminalConfig() *config.TerminalConfig {
+	return &config.TerminalConfig{
+		OutputFlushInterval: time.Millisecond,
+		OutputBatchSize:     32 * 1024,
+		OutputQueueSize:     1024 * 1024,
+		OutputPolicy:        OutputPolicyBlock,
+		DetachGrace:         time.Minute,
+		ScrollbackSize:      1024,
+		MaxParticipants:     8,
+	}
+}
+
+// startTestTerminal - fakeBackend 와 소유자 연결로 터미널 시작
+func startTestTerminal(t *testing.T, protocol string) (*Terminal, *fakeBackend, *fakeConn) {
+	t.Helper()
+	backend := newFakeBackend()
+	conn := newFakeConn(protocol)
+	terminal, err := NewTerminal(conn, "session-test", "alice", backend, nil, &sessionAudit{}, testTerminalConfig())
+	if err != nil {
+		t.Fatalf("NewTerminal: %v", err)
+	}
+	t.Cleanup(terminal.Close)
+	return terminal, backend, conn
+}
+
+// eventually - cond 가 참이 될 때까지 대기 (2초)
+func eventually(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("시간 초과: %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestTerminalOutputAndExit(t *testing.T) {
+	for _, protocol := range []string{"", terminalBinaryProtocol} {
+		t.Run("protocol="+protocol, func(t *testing.T) {
+			terminal, backend, conn := startTestTerminal(t, protocol)
+
+			backend.emit("hello ")
+			backend.emit("world")
+			eventually(t, "출력 전송", func() bool { return conn.output() == "hello world" })
+
+			backend.emit("bye\r\n")
+			backend.exit(3)
+			eventually(t, "세션 종료", func() bool { return !terminal.IsAlive() && conn.isClosed() })
+
+			if got := conn.output(); got != "hello worldbye\r\n" {
+				t.Errorf("출력 = %q", got)
+			}
+			exit, ok := conn.find("exit")
+			if !ok {
+				t.Fatal("exit 메시지가 없음")
+			}
+			if code := exit.Data.(map[string]interface{})["code"]; code != float64(3) {
+				t.Errorf("종료 코드 = %v, want 3", code)
+			}
+
+			// 남은 출력 → exit → 연결 종료, 백엔드는 Wait 뒤에 Close
+			events := conn.events()
+			if events[len(events)-1] != "close" || indexOf(events, "exit") > indexOf(events, "close") {
+				t.Errorf("연결 이벤트 순서 = %v", events)
+			}
+			if _, _, calls := backend.snapshot(); strings.Join(calls, ",") != "start,wait,close" {
+				t.Errorf("백엔드 호출 순서 = %v", calls)
+			}
+		})
+	}
+}
+
+func TestTerminalRoutesInputResizeAndSignal(t *testing.T) {
+	_, backend, conn := startTestTerminal(t, "")
+
+	conn.send("input", "ls -al\r")
+	conn.send("command", "whoami")
+	conn.send("resize", map[string]int{"cols": 120, "rows": 40})
+	conn.send("resize", map[string]int{"cols": 0, "rows": 40}) // 무시
+	conn.send("signal", "SIGINT")
+	conn.send("signal", "TSTP") // 지원하지 않음
+	conn.send("ping", nil)
+
+	eventually(t, "pong", func() bool { return conn.count("pong") == 1 })
+
+	if got := backend.inputString(); got != "ls -al\rwhoami\n" {
+		t.Errorf("입력 = %q", got)
+	}
+	resizes, signals, _ := backend.snapshot()
+	if strings.Join(resizes, ",") != "120x40" {
+		t.Errorf("크기 조정 = %v", resizes)
+	}
+	if strings.Join(signals, ",") != "INT" {
+		t.Errorf("시그널 = %v", signals)
+	}
+	message, ok := conn.find("error")
+	if !ok || !strings.Contains(message.Data.(map[string]interface{})["message"].(string), "TSTP") {
+		t.Errorf("지원하지 않는 시그널 에러 = %v", message)
+	}
+}
+
+func TestTerminalOwnerCloseClosesBackend(t *testing.T) {
+	terminal, backend, conn := startTestTerminal(t, "")
+
+	conn.hangUp()
+	eventually(t, "세션 종료", func() bool { return !terminal.IsAlive() && conn.isClosed() })
+
+	// 셸이 끝나지 않았으므로 Wait 없이 Close
+	if _, _, calls := backend.snapshot(); strings.Join(calls, ",") != "start,close" {
+		t.Errorf("백엔드 호출 순서 = %v", calls)
+	}
+}
+
+func TestTerminalReattachReplaysMissedOutput(t *testing.T) {
+	terminal, backend, conn := startTestTerminal(t, "")
+
+	backend.emit("one ")
+	eventually(t, "출력 전송", func() bool { return conn.output() == "one " })
+
+	// 연결이 끊긴 동안의 출력은 스크롤백에만 남음
+	conn.disconnect()
+	eventually(t, "연결 끊김", func() bool { return !terminal.IsAttached() })
+	backend.emit("two ")
+	eventually(t, "스크롤백 기록", func() bool { return terminal.OutputStats().SentBytes == 8 })
+	if !terminal.IsAlive() {
+		t.Fatal("재연결 유예 시간 동안 세션이 유지되어야 함")
+	}
+
+	missed := newFakeConn("")
+	if err := terminal.Attach(missed, false, AuditClient{}); err != nil {
+		t.Fatalf("Attach: %v", err)
+	}
+	if got := missed.output(); got != "two " {
+		t.Errorf("놓친 출력 = %q, want %q", got, "two ")
+	}
+
+	// replay=all 은 스크롤백 전체, 이전 소유자 연결은 닫힘
+	all := newFakeConn(terminalBinaryProtocol)
+	if err := terminal.Attach(all, true, AuditClient{}); err != nil {
+		t.Fatalf("Attach: %v", err)
+	}
+	if got := all.output(); got != "one two " {
+		t.Errorf("전체 재전송 = %q", got)
+	}
+	if !missed.isClosed() {
+		t.Error("교체된 소유자 연결이 닫히지 않음")
+	}
+
+	// 이후 출력은 새 연결로
+	backend.emit("three")
+	eventually(t, "실시간 출력", func() bool { return all.output() == "one two three" })
+}
+
+func TestTerminalObserverCannotInput(t *testing.T) {
+	terminal, backend, owner := startTestTerminal(t, "")
+
+	observer := newFakeConn("")
+	if err := terminal.Join(observer, "bob", RoleObserver, AuditClient{}); err != nil {
+		t.Fatalf("Join: %v", err)
+	}
+	moderator := newFakeConn(terminalBinaryProtocol)
+	if err := terminal.Join(moderator, "carol", RoleModerator, AuditClient{}); err != nil {
+		t.Fatalf("Join: %v", err)
+	}
+
+	observer.send("input", "rm -rf /\r")
+	observer.send("resize", map[string]int{"cols": 10, "rows": 10})
+	observer.send("signal", "INT")
+	observer.in <- fakeFrame{websocket.BinaryMessage, []byte("x")}
+	observer.send("ping", nil)
+	eventually(t, "관찰자 pong", func() bool { return observer.count("pong") == 1 })
+
+	moderator.in <- fakeFrame{websocket.BinaryMessage, []byte("echo hi\r")}
+	eventually(t, "moderator 입력", func() bool { return backend.inputString() == "echo hi\r" })
+
+	resizes, signals, _ := backend.snapshot()
+	if len(resizes) != 0 || len(signals) != 0 {
+		t.Errorf("관찰자의 크기 조정/시그널이 전달됨: %v %v", resizes, signals)
+	}
+	// 거부 알림은 한 번만
+	if n := observer.count("error"); n != 1 {
+		t.Errorf("관찰자 에러 메시지 %d개, want 1", n)
+	}
+	message, _ := observer.find("error")
+	if role := message.Data.(map[string]interface{})["role"]; role != RoleObserver {
+		t.Errorf("에러 메시지 role = %v", role)
+	}
+
+	// 모든 참가자가 같은 출력을 받음
+	backend.emit("shared")
+	for name, conn := range map[string]*fakeConn{"owner": owner, "observer": observer, "moderator": moderator} {
+		eventually(t, name+" 출력", func() bool { return conn.output() == "shared" })
+	}
+}
+
+func TestTerminalJoinLimit(t *testing.T) {
+	terminal, _, _ := startTestTerminal(t, "")
+	terminal.config.MaxParticipants = 2
+
+	if err := terminal.Join(newFakeConn(""), "bob", RoleObserver, AuditClient{}); err != nil {
+		t.Fatalf("Join: %v", err)
+	}
+	if err := terminal.Join(newFakeConn(""), "carol", RoleObserver, AuditClient{}); err == nil {
+		t.Error("참가자 수 제한을 넘었는데 참가됨")
+	}
+	if err := terminal.Join(newFakeConn(""), "dave", "admin", AuditClient{}); err == nil {
+		t.Error("알 수 없는 역할로 참가됨")
+	}
+}
+
+func TestTerminalBinaryInput(t *testing.T) {
+	// 바이너리 프레임은 서브프로토콜을 협상한 경우에만 입력으로 전달
+	_, backend, conn := startTestTerminal(t, "")
+	conn.in <- fakeFrame{websocket.BinaryMessage, []byte("rm -rf /\r")}
+	conn.send("ping", nil)
+	eventually(t, "pong", func() bool { return conn.count("pong") == 1 })
+	if got := backend.inputString(); got != "" {
+		t.Errorf("JSON 모드 바이너리 입력 = %q", got)
+	}
+
+	_, backend, conn = startTestTerminal(t, terminalBinaryProtocol)
+	conn.in <- fakeFrame{websocket.BinaryMessage, []byte("echo hi\r")}
+	conn.send("input", "ls\r") // JSON input 도 계속 처리
+	conn.send("ping", nil)
+	eventually(t, "pong", func() bool { return conn.count("pong") == 1 })
+	if got := backend.inputString(); got != "echo hi\rls\r" {
+		t.Errorf("바이너리 모드 입력 = %q", got)
+	}
+}
+
+func TestTerminalJSONOutputKeepsUTF8(t *testing.T) {
+	_, backend, conn := startTestTerminal(t, "")
+
+	// 읽기 경계에서 잘린 "한" 은 다음 출력과 합쳐서 전송
+	backend.emit("\xed\x95")
+	backend.emit("\x9c!")
+	eventually(t, "출력 전송", func() bool { return conn.output() == "한!" })
+}
+
+// indexOf - s 에서 v 의 위치 (없으면 -1)
+func indexOf(s []string, v string) int {
+	for i, item := range s {
+		if item == v {
+			return i
+		}
+	}
+	return -1
+}
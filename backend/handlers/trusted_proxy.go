@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"net"
+	"net/http"
+)
+
+// trustedProxies - 인증 프록시 헤더(X-Forwarded-User 등)를 믿을 주소 목록 (TRUSTED_PROXIES)
+type trustedProxies []*net.IPNet
+
+// trusts - 요청이 신뢰하는 프록시에서 직접 왔는지 확인 (연결 주소 기준)
+func (p trustedProxies) trusts(r *http.Request) bool {
+	if len(p) == 0 {
+		return false
+	}
+	ip := net.ParseIP(remoteHost(r))
+	if ip == nil {
+		return false
+	}
+	for _, network := range p {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// remoteHost - 연결 주소의 호스트 부분
+func remoteHost(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -1,10 +1,17 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/rs/cors"
@@ -12,13 +19,6 @@ import (
 	"github.com/Heo-YJ/teleport-opensource/handlers"
 )
 
-type Container struct {
-	ID     string            `json:"id"`
-	Name   string            `json:"name"`
-	Status string            `json:"status"`
-	Labels map[string]string `json:"labels"`
-}
-
 func main() {
 	// 라우터 생성
 	r := mux.NewRouter()
@@ -28,12 +28,18 @@ func main() {
 
 	//API 라우트 설정
 	api := r.PathPrefix("/api").Subrouter()
-	api.HandleFunc("/health", healthCheck).Methods("GET")
-	api.HandleFunc("/containers", getContainers).Methods("GET")
+	api.HandleFunc("/health", teleportHandler.HandleHealthCheck).Methods("GET")
+	api.HandleFunc("/containers", teleportHandler.HandleGetContainers).Methods("GET")
+	api.HandleFunc("/containers/events", teleportHandler.HandleContainerEvents).Methods("GET") // {containerId} 보다 먼저 등록
 	api.HandleFunc("/containers/{containerId}", teleportHandler.HandleGetContainer).Methods("GET")
 	api.HandleFunc("/containers/{containerId}/connect", connectContainer).Methods("POST")
 	api.HandleFunc("/terminal/sessions", teleportHandler.HandleGetTerminalSessions).Methods("GET")
 	api.HandleFunc("/ws/terminal/{containerId}", teleportHandler.HandleTerminalWebSocket).Methods("GET")
+	api.HandleFunc("/ws/terminals", teleportHandler.HandleTerminalMux).Methods("GET") // 여러 터미널 다중화
+	api.HandleFunc("/recordings", teleportHandler.HandleGetRecordings).Methods("GET")
+	api.HandleFunc("/recordings/{sessionId}", teleportHandler.HandleGetRecording).Methods("GET")
+	api.HandleFunc("/recordings/{sessionId}/cast", teleportHandler.HandleDownloadRecording).Methods("GET")
+	api.HandleFunc("/ws/recordings/{sessionId}", teleportHandler.HandlePlayRecording).Methods("GET") // 녹화 재생
 
 	//CORS 설정 (프론트엔드와 연동용)
 	c := cors.New(cors.Options{
@@ -48,58 +54,36 @@ func main() {
 	fmt.Println("API Server: http://localhost:8080")
 	fmt.Println("Health Check: http://localhost:8080/api/health")
 	fmt.Println("Containers:http://localhost:8080/api/containers")
-
-	log.Println("서버가 :8080 포트에서 시작됩니다...")
-	log.Fatal(http.ListenAndServe(":8080", handler))
-}
-
-func healthCheck(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-	response := map[string]string{
-		"status":   "ok",
-		"message":  "Container SSH System is running",
-		"teleport": "connected",
+	fmt.Println("Container Events (SSE): http://localhost:8080/api/containers/events")
+
+	// 종료할 때 요청 컨텍스트를 취소해서 SSE 등 오래 열린 응답도 끝냄
+	baseCtx, cancelBase := context.WithCancel(context.Background())
+	server := &http.Server{
+		Addr:        ":8080",
+		Handler:     handler,
+		BaseContext: func(net.Listener) context.Context { return baseCtx },
 	}
-	json.NewEncoder(w).Encode(response)
-}
-
-func getContainers(w http.ResponseWriter, r *http.Request) {
-	//임시 Mock 데이터 (나중에 Teleport API로 교체)
-	containers := []Container{
-		{
-			ID:     "web-001",
-			Name:   "nginx-server",
-			Status: "running",
-			Labels: map[string]string{
-				"team": "frontend",
-				"env":  "production",
-			},
-		},
-		{
-			ID:     "db-001",
-			Name:   "postgres-db",
-			Status: "running",
-			Labels: map[string]string{
-				"team": "backend",
-				"env":  "production",
-			},
-		},
-		{
-			ID:     "api-001",
-			Name:   "backend-api",
-			Status: "stopped",
-			Labels: map[string]string{
-				"team": "backend",
-				"env":  "development",
-			},
-		},
+	server.RegisterOnShutdown(cancelBase)
+	go func() {
+		log.Println("서버가 :8080 포트에서 시작됩니다...")
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatal(err)
+		}
+	}()
+
+	// SIGINT / SIGTERM 을 받으면 새 요청을 받지 않고, 터미널과 감사 로그를 정리한 뒤 종료
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	<-ctx.Done()
+	log.Println("서버 종료 중...")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		log.Printf("HTTP 서버 종료 실패: %v", err)
 	}
-
-	w.Header().Set("Content-Type", "application/json")
-	_ = json.NewEncoder(w).Encode(map[string]interface{}{
-		"containers": containers,
-		"total":      len(containers),
-	})
+	teleportHandler.Shutdown()
+	log.Println("서버 종료 완료")
 }
 
 func connectContainer(w http.ResponseWriter, r *http.Request) {
@@ -12,6 +12,27 @@ export interface Container {
     ports?: string[];
   }
   
+  // 새로 추가: 컨테이너 상세 정보 (GET /api/containers/:id)
+  export interface ContainerDetail extends Container {
+    source?: string;
+    cluster?: string;
+    command?: string[];
+    state?: string;
+    startedAt?: string;
+    restartCount: number;
+    resources?: {
+      cpuLimit?: string;
+      cpuRequest?: string;
+      memoryLimit?: string;
+      memoryRequest?: string;
+      pidsLimit?: number;
+    };
+    env?: { name: string; value: string; redacted?: boolean; from?: string }[];
+    mounts?: { type?: string; source?: string; destination: string; readOnly: boolean }[];
+    transitions: { from?: string; to: string; time: string }[];
+    detailError?: string;
+  }
+
   export type ContainerStatus = 'running' | 'online' |'stopped' | 'pending' | 'error' | 'unknown';
   
   export interface ContainerConnection {